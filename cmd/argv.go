@@ -22,7 +22,11 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+
+	"github.com/soulteary/ssh-config/v2/pkg/diag"
 )
 
 var (
@@ -37,8 +41,51 @@ type Args struct {
 	Src      string
 	Dest     string
 	ShowHelp bool
+
+	KeepInclude    bool
+	IncludeDir     string
+	KeepOrder      bool
+	Duplicates     string
+	Expand         bool
+	KeepCase       bool
+	LegacyKeywords bool
+	Tag            string
+	MatchExec      bool
+	Strict         bool
+	OpenSSHVersion string
+	CryptoPolicy   string
+	Policy         string
+	Fix            bool
+	Write          bool
+	Format         string
+	Check          bool
+
+	// Stdin is set when the config is read from stdin instead of Src.
+	Stdin bool
+
+	// Command is the subcommand given before or among the flags, empty for a
+	// conversion; Operands are the positional arguments following it.
+	Command  string
+	Operands []string
 }
 
+// CommandResolve prints the settings ssh uses for a destination, like ssh -G.
+const CommandResolve = "resolve"
+
+// CommandValidate checks every value against the type of its keyword.
+const CommandValidate = "validate"
+
+// CommandLint reports risky settings, see the rules in the README.
+const CommandLint = "lint"
+
+// CommandModernize rewrites legacy patterns into current OpenSSH idioms.
+const CommandModernize = "modernize"
+
+// CommandFmt formats SSH config text, like gofmt.
+const CommandFmt = "fmt"
+
+var Commands = []string{CommandResolve, CommandValidate, CommandLint, CommandModernize, CommandFmt}
+
 const (
 	DEFAULT_TO_YAML = false
 	DEFAULT_TO_SSH  = false
@@ -46,6 +93,24 @@ const (
 	DEFAULT_SRC     = ""
 	DEFAULT_DEST    = ""
 	DEFAULT_HELP    = false
+
+	DEFAULT_KEEP_INCLUDE    = false
+	DEFAULT_INCLUDE_DIR     = ""
+	DEFAULT_KEEP_ORDER      = false
+	DEFAULT_DUPLICATES      = ""
+	DEFAULT_EXPAND          = false
+	DEFAULT_KEEP_CASE       = false
+	DEFAULT_LEGACY_KEYWORDS = false
+	DEFAULT_TAG             = ""
+	DEFAULT_MATCH_EXEC      = false
+	DEFAULT_STRICT          = false
+	DEFAULT_OPENSSH_VERSION = ""
+	DEFAULT_CRYPTO_POLICY   = ""
+	DEFAULT_POLICY          = ""
+	DEFAULT_FIX             = false
+	DEFAULT_WRITE           = false
+	DEFAULT_FORMAT          = ""
+	DEFAULT_CHECK           = false
 )
 
 func initFlags() {
@@ -55,12 +120,38 @@ func initFlags() {
 	flag.StringVar(&args.Src, "src", DEFAULT_SRC, "Source file or directories path, valid when using non-pipeline mode")
 	flag.StringVar(&args.Dest, "dest", DEFAULT_DEST, "Destination file path, valid when using non-pipeline mode")
 	flag.BoolVar(&args.ShowHelp, "help", DEFAULT_HELP, "Show help")
+	flag.BoolVar(&args.KeepInclude, "keep-include", DEFAULT_KEEP_INCLUDE, "Keep Include directives instead of inlining the files they reference")
+	flag.StringVar(&args.IncludeDir, "include-dir", DEFAULT_INCLUDE_DIR, "Directory relative Include paths are looked up in, ~/.ssh like ssh when empty")
+	flag.BoolVar(&args.KeepOrder, "keep-order", DEFAULT_KEEP_ORDER, "Keep Host and Match blocks in the order they are written")
+	flag.StringVar(&args.Duplicates, "duplicates", DEFAULT_DUPLICATES, "How to handle duplicate Host blocks: warn (default), error, merge or keep")
+	flag.BoolVar(&args.Expand, "expand", DEFAULT_EXPAND, "Expand %h, %p, ${ENV} and other tokens in the values of concrete hosts")
+	flag.BoolVar(&args.KeepCase, "keep-case", DEFAULT_KEEP_CASE, "Keep keywords as they are written instead of using their ssh_config(5) spelling")
+	flag.BoolVar(&args.LegacyKeywords, "legacy-keywords", DEFAULT_LEGACY_KEYWORDS, "Write renamed keywords with their old name, e.g. PubkeyAcceptedKeyTypes, for OpenSSH older than 8.5")
+	flag.StringVar(&args.Tag, "tag", DEFAULT_TAG, "Tag that Match tagged sees when resolving, like ssh -P")
+	flag.BoolVar(&args.MatchExec, "match-exec", DEFAULT_MATCH_EXEC, "Run the commands of Match exec criteria when resolving, they are skipped otherwise")
+	flag.BoolVar(&args.Strict, "strict", DEFAULT_STRICT, "Refuse YAML and JSON input with unknown fields or keywords")
+	flag.StringVar(&args.OpenSSHVersion, "openssh-version", DEFAULT_OPENSSH_VERSION, "OpenSSH version whose default algorithms lint checks against, the latest known when empty")
+	flag.StringVar(&args.CryptoPolicy, "crypto-policy", DEFAULT_CRYPTO_POLICY, "YAML file with the algorithms lint allows and denies, the modern baseline when empty")
+	flag.StringVar(&args.Policy, "policy", DEFAULT_POLICY, "YAML file with rules that lint checks the hosts against")
+	flag.BoolVar(&args.Fix, "fix", DEFAULT_FIX, "Show the fixes of lint findings as a diff and write them once confirmed")
+	flag.BoolVar(&args.Write, "write", DEFAULT_WRITE, "Write the fixes of lint -fix or modernize without asking")
+	flag.StringVar(&args.Format, "format", DEFAULT_FORMAT, "Print the problems validate and lint find as text, json or sarif, with the file they are in")
+	flag.BoolVar(&args.Check, "check", DEFAULT_CHECK, "Show the changes fmt would make as a diff and fail when there are any")
 }
 
 func ParseArgs() Args {
 	once.Do(func() {
 		initFlags()
 		flag.Parse()
+		// flags may also follow the command and its operands
+		for flag.NArg() > 0 {
+			args.Operands = append(args.Operands, flag.Arg(0))
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+		if len(args.Operands) > 0 && slices.Contains(Commands, args.Operands[0]) {
+			args.Command = args.Operands[0]
+			args.Operands = args.Operands[1:]
+		}
 	})
 	return args
 }
@@ -74,6 +165,24 @@ func ResetFlags() {
 		Src:      DEFAULT_SRC,
 		Dest:     DEFAULT_DEST,
 		ShowHelp: DEFAULT_HELP,
+
+		KeepInclude:    DEFAULT_KEEP_INCLUDE,
+		IncludeDir:     DEFAULT_INCLUDE_DIR,
+		KeepOrder:      DEFAULT_KEEP_ORDER,
+		Duplicates:     DEFAULT_DUPLICATES,
+		Expand:         DEFAULT_EXPAND,
+		KeepCase:       DEFAULT_KEEP_CASE,
+		LegacyKeywords: DEFAULT_LEGACY_KEYWORDS,
+		Tag:            DEFAULT_TAG,
+		MatchExec:      DEFAULT_MATCH_EXEC,
+		Strict:         DEFAULT_STRICT,
+		OpenSSHVersion: DEFAULT_OPENSSH_VERSION,
+		CryptoPolicy:   DEFAULT_CRYPTO_POLICY,
+		Policy:         DEFAULT_POLICY,
+		Fix:            DEFAULT_FIX,
+		Write:          DEFAULT_WRITE,
+		Format:         DEFAULT_FORMAT,
+		Check:          DEFAULT_CHECK,
 	} // Reset the args
 	once = sync.Once{} // Reset the once
 }
@@ -102,13 +211,49 @@ func CheckConvertArgvValid(args Args) (result bool, desc string) {
 		trueCount++
 	}
 
-	if trueCount != 1 {
+	// commands have their own default output
+	if trueCount > 1 || trueCount == 0 && args.Command == "" {
 		return false, "Please specify either -to-yaml or -to-ssh or -to-json"
 	}
 
 	return true, ""
 }
 
+func CheckCommandArgvValid(args Args) (result bool, desc string) {
+	switch args.Command {
+	case "":
+		if len(args.Operands) > 0 {
+			return false, fmt.Sprintf("Unknown command '%s', available commands: %v", args.Operands[0], Commands)
+		}
+	case CommandResolve:
+		if len(args.Operands) != 1 {
+			return false, "Please specify a single destination: resolve [user@]host[:port]"
+		}
+	case CommandValidate, CommandLint, CommandModernize, CommandFmt:
+		if len(args.Operands) > 0 {
+			return false, fmt.Sprintf("Unexpected argument '%s', %s reads -src or stdin", args.Operands[0], args.Command)
+		}
+	}
+	if args.Fix && args.Command != CommandLint {
+		return false, "-fix only applies to the lint command"
+	}
+	if args.Write && !args.Fix && args.Command != CommandModernize {
+		return false, "-write only applies to lint -fix and modernize"
+	}
+	if args.Check && args.Command != CommandFmt {
+		return false, "-check only applies to the fmt command"
+	}
+	if args.Format != "" {
+		if args.Command != CommandValidate && args.Command != CommandLint || args.Fix {
+			return false, "-format only applies to the validate and lint commands"
+		}
+		if !slices.Contains(diag.Formats, strings.ToLower(args.Format)) {
+			return false, fmt.Sprintf("Unsupported format '%s', use one of %v", args.Format, diag.Formats)
+		}
+	}
+	return true, ""
+}
+
 func CheckIOArgvValid(args Args) (result bool, desc string) {
 	if args.Src == "" {
 		return false, "Please specify source and destination file path"
@@ -74,6 +74,18 @@ func TestCheckConvertArgvValid(t *testing.T) {
 			wantResult: false,
 			wantDesc:   "Please specify either -to-yaml or -to-ssh or -to-json",
 		},
+		{
+			name:       "Command without format",
+			args:       Cmd.Args{Command: Cmd.CommandResolve},
+			wantResult: true,
+			wantDesc:   "",
+		},
+		{
+			name:       "Command with two formats",
+			args:       Cmd.Args{Command: Cmd.CommandResolve, ToJSON: true, ToYAML: true},
+			wantResult: false,
+			wantDesc:   "Please specify either -to-yaml or -to-ssh or -to-json",
+		},
 	}
 
 	for _, tt := range tests {
@@ -239,6 +251,43 @@ func TestShowHelp(t *testing.T) {
 	}
 }
 
+func TestCheckCommandArgvValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       Cmd.Args
+		wantResult bool
+		wantDesc   string
+	}{
+		{name: "Conversion", args: Cmd.Args{ToYAML: true}, wantResult: true},
+		{name: "Unknown command", args: Cmd.Args{Operands: []string{"bogus"}}, wantDesc: "Unknown command 'bogus', available commands: [resolve validate lint modernize fmt]"},
+		{name: "Resolve", args: Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"work"}}, wantResult: true},
+		{name: "Resolve without destination", args: Cmd.Args{Command: Cmd.CommandResolve}, wantDesc: "Please specify a single destination: resolve [user@]host[:port]"},
+		{name: "Resolve with two destinations", args: Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"a", "b"}}, wantDesc: "Please specify a single destination: resolve [user@]host[:port]"},
+		{name: "Validate", args: Cmd.Args{Command: Cmd.CommandValidate}, wantResult: true},
+		{name: "Validate with an operand", args: Cmd.Args{Command: Cmd.CommandValidate, Operands: []string{"config"}}, wantDesc: "Unexpected argument 'config', validate reads -src or stdin"},
+		{name: "Lint with an operand", args: Cmd.Args{Command: Cmd.CommandLint, Operands: []string{"config"}}, wantDesc: "Unexpected argument 'config', lint reads -src or stdin"},
+		{name: "Lint fix", args: Cmd.Args{Command: Cmd.CommandLint, Fix: true, Write: true}, wantResult: true},
+		{name: "Fix without lint", args: Cmd.Args{Command: Cmd.CommandValidate, Fix: true}, wantDesc: "-fix only applies to the lint command"},
+		{name: "Write without fix", args: Cmd.Args{Command: Cmd.CommandLint, Write: true}, wantDesc: "-write only applies to lint -fix and modernize"},
+		{name: "Modernize with write", args: Cmd.Args{Command: Cmd.CommandModernize, Write: true}, wantResult: true},
+		{name: "Modernize with argument", args: Cmd.Args{Command: Cmd.CommandModernize, Operands: []string{"web"}}, wantDesc: "Unexpected argument 'web', modernize reads -src or stdin"},
+		{name: "Fmt with check", args: Cmd.Args{Command: Cmd.CommandFmt, Check: true}, wantResult: true},
+		{name: "Check without fmt", args: Cmd.Args{Command: Cmd.CommandLint, Check: true}, wantDesc: "-check only applies to the fmt command"},
+		{name: "Lint as SARIF", args: Cmd.Args{Command: Cmd.CommandLint, Format: "SARIF"}, wantResult: true},
+		{name: "Format without command", args: Cmd.Args{Format: "json"}, wantDesc: "-format only applies to the validate and lint commands"},
+		{name: "Unknown format", args: Cmd.Args{Command: Cmd.CommandValidate, Format: "xml"}, wantDesc: "Unsupported format 'xml', use one of [text json sarif]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, gotDesc := Cmd.CheckCommandArgvValid(tt.args)
+			if gotResult != tt.wantResult || gotDesc != tt.wantDesc {
+				t.Errorf("CheckCommandArgvValid() = %v, %q, want %v, %q", gotResult, gotDesc, tt.wantResult, tt.wantDesc)
+			}
+		})
+	}
+}
+
 func TestParseArgs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -281,6 +330,27 @@ func TestParseArgs(t *testing.T) {
 				ShowHelp: false,
 			},
 		},
+		{
+			name: "Command with flags around its operand",
+			args: []string{"-src", "config", "resolve", "-to-json", "deploy@work", "-expand", "-tag", "admin", "-match-exec"},
+			expected: Cmd.Args{
+				ToJSON:    true,
+				Src:       "config",
+				Expand:    true,
+				Tag:       "admin",
+				MatchExec: true,
+				Command:   Cmd.CommandResolve,
+				Operands:  []string{"deploy@work"},
+			},
+		},
+		{
+			name: "Unknown command",
+			args: []string{"-to-ssh", "bogus", "x"},
+			expected: Cmd.Args{
+				ToSSH:    true,
+				Operands: []string{"bogus", "x"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -23,6 +23,19 @@ const Usage = `Usage:
   ssh-config -to-ssh
   ssh-config -to-json
   ssh-config -src <source file or directories path> -dest <destination file path>
+  ssh-config -keep-include
+  ssh-config -include-dir <directory>
+  ssh-config -keep-order
+  ssh-config -duplicates warn|error|merge|keep
+  ssh-config -expand
+  ssh-config -keep-case
+  ssh-config -to-ssh -legacy-keywords
+  ssh-config -strict
+  ssh-config resolve [user@]host[:port] [-to-yaml|-to-json] [-tag <tag>] [-match-exec]
+  ssh-config validate [-src <source file path>] [-format text|json|sarif]
+  ssh-config lint [-src <source file path>] [-openssh-version <version>] [-crypto-policy <policy file>] [-policy <rules file>] [-fix [-write] | -format text|json|sarif]
+  ssh-config modernize [-src <source file path>] [-openssh-version <version>] [-crypto-policy <policy file>] [-write]
+  ssh-config fmt [-src <source file path>] [-dest <destination file path>] [-check] [-keep-case]
   ssh-config -help
 `
 
@@ -16,37 +16,359 @@
 
 package define
 
+import "strings"
+
 type HostExtraConfig struct {
 	Prefix string
 }
 
+// MultiValueKeywords lists the directives ssh accumulates instead of using
+// the first obtained value, so every occurrence has to be kept in order.
+var MultiValueKeywords = []string{
+	"CertificateFile",
+	"DynamicForward",
+	"IdentityFile",
+	"Include",
+	"LocalForward",
+	"RemoteForward",
+	"SendEnv",
+}
+
+func IsMultiValueKeyword(key string) bool {
+	for _, keyword := range MultiValueKeywords {
+		if strings.EqualFold(keyword, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigValue is a directive value given either as a scalar or, for
+// repeated directives, as a list.
+type ConfigValue []string
+
+func (v *ConfigValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*v = ConfigValue{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*v = list
+	return nil
+}
+
+// splitConfigValues stores single values as scalars and repeated ones as lists.
+func splitConfigValues(raw map[string]ConfigValue) (config map[string]string, lists map[string][]string) {
+	if raw == nil {
+		return nil, nil
+	}
+	config = make(map[string]string)
+	for key, values := range raw {
+		switch len(values) {
+		case 0:
+			config[key] = ""
+		case 1:
+			config[key] = values[0]
+		default:
+			if lists == nil {
+				lists = make(map[string][]string)
+			}
+			lists[key] = append([]string(nil), values...)
+		}
+	}
+	return config, lists
+}
+
+// Match criteria, docs: https://man.openbsd.org/ssh_config#Match
+var MatchCriteria = []string{
+	"all",
+	"canonical",
+	"exec",
+	"final",
+	"host",
+	"localnetwork",
+	"localuser",
+	"originalhost",
+	"tagged",
+	"user",
+}
+
+// MatchCriteriaWithoutArgument take no argument, e.g. "Match canonical all".
+var MatchCriteriaWithoutArgument = []string{"all", "canonical", "final"}
+
+// MatchCondition is a single criterion of a Match line, e.g. "!host *.corp".
+type MatchCondition struct {
+	Criterion string `yaml:"Criterion" json:"Criterion"`
+	Negate    bool   `yaml:"Negate,omitempty" json:"Negate,omitempty"`
+	Argument  string `yaml:"Argument,omitempty" json:"Argument,omitempty"`
+}
+
+// HostPattern is a single pattern of a Host line, e.g. "!web3" or "*.dev".
+type HostPattern struct {
+	Pattern  string `yaml:"Pattern" json:"Pattern"`
+	Negate   bool   `yaml:"Negate,omitempty" json:"Negate,omitempty"`
+	Wildcard bool   `yaml:"Wildcard,omitempty" json:"Wildcard,omitempty"`
+}
+
+// ParseHostPatterns splits the patterns of a Host line.
+func ParseHostPatterns(name string) []HostPattern {
+	fields := strings.Fields(name)
+	patterns := make([]HostPattern, 0, len(fields))
+	for _, field := range fields {
+		pattern := HostPattern{}
+		if strings.HasPrefix(field, "!") {
+			pattern.Negate = true
+			field = field[1:]
+		}
+		pattern.Pattern = field
+		pattern.Wildcard = strings.ContainsAny(field, "*?")
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// FormatHostPatterns joins patterns back into a Host line.
+func FormatHostPatterns(patterns []HostPattern) string {
+	parts := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern.Negate {
+			parts = append(parts, "!"+pattern.Pattern)
+		} else {
+			parts = append(parts, pattern.Pattern)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// IsConcreteHost reports whether name is a single host, not a pattern rule.
+func IsConcreteHost(name string) bool {
+	patterns := ParseHostPatterns(name)
+	return len(patterns) == 1 && !patterns[0].Negate && !patterns[0].Wildcard
+}
+
+// MatchPattern reports whether name matches pattern, where '*' matches any
+// run of characters and '?' exactly one. Host names are compared ignoring case.
+func MatchPattern(pattern, name string) bool {
+	return matchWildcard(strings.ToLower(pattern), strings.ToLower(name))
+}
+
+// matchWildcard is MatchPattern comparing case.
+func matchWildcard(pattern, name string) bool {
+	// on a mismatch, retry from the last '*' with one more character consumed
+	star, retry := -1, 0
+	p, n := 0, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case p < len(pattern) && pattern[p] == '*':
+			star, retry = p, n
+			p++
+		case star >= 0:
+			retry++
+			p, n = star+1, retry
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// ParsePatternList splits a comma-separated pattern list of a Match
+// criterion, e.g. "*.corp,!legacy.corp".
+func ParsePatternList(list string) []HostPattern {
+	return ParseHostPatterns(strings.ReplaceAll(list, ",", " "))
+}
+
+// MatchHostPatterns reports whether host is selected by the patterns of a
+// Host line: one pattern matches and no negated pattern does.
+func MatchHostPatterns(patterns []HostPattern, host string) bool {
+	return matchPatterns(patterns, host, MatchPattern)
+}
+
+// MatchUserPatterns is MatchHostPatterns for user names, which ssh compares
+// with their case.
+func MatchUserPatterns(patterns []HostPattern, user string) bool {
+	return matchPatterns(patterns, user, matchWildcard)
+}
+
+func matchPatterns(patterns []HostPattern, name string, match func(pattern, name string) bool) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if !match(pattern.Pattern, name) {
+			continue
+		}
+		if pattern.Negate {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
 // ssh config
 type HostConfig struct {
-	Name   string `yaml:"Name,omitempty"`
-	Notes  string `yaml:"Notes,omitempty"`
-	Config map[string]string
-	Extra  HostExtraConfig `yaml:"Extra,omitempty"`
+	Name  string `yaml:"Name,omitempty"`
+	Notes string `yaml:"Notes,omitempty"`
+	// Patterns is the structured form of Name in the YAML and JSON formats,
+	// only written for pattern rules. When read it replaces Name, and it is
+	// cleared once the config is grouped.
+	Patterns []HostPattern `yaml:"Patterns,omitempty"`
+	Config   map[string]string
+	// Lists holds directives given more than once, in source order.
+	// A directive is either in Config or in Lists, never in both.
+	Lists map[string][]string `yaml:"-"`
+	// Match is set for Match blocks, which have no Name.
+	Match []MatchCondition `yaml:"Match,omitempty"`
+	// Include is set for an Include line outside of any block, which has no Name.
+	Include []string        `yaml:"Include,omitempty"`
+	Extra   HostExtraConfig `yaml:"Extra,omitempty"`
+}
+
+func (c *HostConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw struct {
+		Name     string                 `yaml:"Name,omitempty"`
+		Notes    string                 `yaml:"Notes,omitempty"`
+		Patterns []HostPattern          `yaml:"Patterns,omitempty"`
+		Config   map[string]ConfigValue `yaml:"config"`
+		Match    []MatchCondition       `yaml:"Match,omitempty"`
+		Extra    HostExtraConfig        `yaml:"Extra,omitempty"`
+	}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	c.Name = raw.Name
+	c.Notes = raw.Notes
+	c.Patterns = raw.Patterns
+	c.Config, c.Lists = splitConfigValues(raw.Config)
+	c.Match = raw.Match
+	c.Extra = raw.Extra
+	return nil
+}
+
+// HostPatterns returns the patterns of Name.
+func (c HostConfig) HostPatterns() []HostPattern {
+	return ParseHostPatterns(c.Name)
+}
+
+func (c HostConfig) IsMatch() bool {
+	return len(c.Match) > 0
+}
+
+func (c HostConfig) IsInclude() bool {
+	return len(c.Include) > 0
+}
+
+// Values returns every value of key, in order.
+func (c HostConfig) Values(key string) []string {
+	if values, ok := c.Lists[key]; ok {
+		return values
+	}
+	if value, ok := c.Config[key]; ok {
+		return []string{value}
+	}
+	return nil
+}
+
+func (c HostConfig) HasKey(key string) bool {
+	_, inConfig := c.Config[key]
+	_, inLists := c.Lists[key]
+	return inConfig || inLists
+}
+
+// SetValues replaces the values of key, keeping a single value as a scalar.
+func (c *HostConfig) SetValues(key string, values []string) {
+	delete(c.Config, key)
+	delete(c.Lists, key)
+	if len(values) == 1 {
+		if c.Config == nil {
+			c.Config = make(map[string]string)
+		}
+		c.Config[key] = values[0]
+		return
+	}
+	if len(values) > 1 {
+		if c.Lists == nil {
+			c.Lists = make(map[string][]string)
+		}
+		c.Lists[key] = append([]string(nil), values...)
+	}
 }
 
 // json
-type HostConfigDataForJSON map[string]string
+type HostConfigDataForJSON map[string]any
 
 type HostConfigForJSON struct {
-	Name  string                `json:"Name,omitempty"`
-	Notes string                `json:"Notes,omitempty"`
-	Data  HostConfigDataForJSON `json:"Data,omitempty"`
+	Name     string                `json:"Name,omitempty"`
+	Notes    string                `json:"Notes,omitempty"`
+	Patterns []HostPattern         `json:"Patterns,omitempty"`
+	Match    []MatchCondition      `json:"Match,omitempty"`
+	Include  []string              `json:"Include,omitempty"`
+	Data     HostConfigDataForJSON `json:"Data,omitempty"`
 }
 
 // yaml
 type GroupConfig struct {
-	Prefix string                `yaml:"Prefix,omitempty"`
-	Common map[string]string     `yaml:"Common,omitempty"`
-	Hosts  map[string]HostConfig `yaml:"Hosts,omitempty"`
+	Prefix      string                `yaml:"Prefix,omitempty"`
+	Common      map[string]string     `yaml:"Common,omitempty"`
+	CommonLists map[string][]string   `yaml:"-"`
+	Hosts       map[string]HostConfig `yaml:"Hosts,omitempty"`
+	// Match blocks written after the hosts of this group, in order.
+	Match []HostConfig `yaml:"Match,omitempty"`
+}
+
+func (g *GroupConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw struct {
+		Prefix string                 `yaml:"Prefix,omitempty"`
+		Common map[string]ConfigValue `yaml:"Common,omitempty"`
+		Hosts  map[string]HostConfig  `yaml:"Hosts,omitempty"`
+		Match  []HostConfig           `yaml:"Match,omitempty"`
+	}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	g.Prefix = raw.Prefix
+	g.Common, g.CommonLists = splitConfigValues(raw.Common)
+	g.Hosts = raw.Hosts
+	g.Match = raw.Match
+	return nil
 }
+
 type YAMLOutput struct {
-	Global  map[string]string      `yaml:"global,omitempty"`
-	Default map[string]string      `yaml:"default,omitempty"`
-	Groups  map[string]GroupConfig `yaml:",inline"`
+	Global       map[string]string      `yaml:"global,omitempty"`
+	GlobalLists  map[string][]string    `yaml:"-"`
+	Default      map[string]string      `yaml:"default,omitempty"`
+	DefaultLists map[string][]string    `yaml:"-"`
+	Include      []string               `yaml:"include,omitempty"`
+	Match        []HostConfig           `yaml:"match,omitempty"`
+	Groups       map[string]GroupConfig `yaml:",inline"`
+}
+
+func (o *YAMLOutput) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw struct {
+		Global  map[string]ConfigValue `yaml:"global,omitempty"`
+		Default map[string]ConfigValue `yaml:"default,omitempty"`
+		Include []string               `yaml:"include,omitempty"`
+		Match   []HostConfig           `yaml:"match,omitempty"`
+		Groups  map[string]GroupConfig `yaml:",inline"`
+	}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	o.Global, o.GlobalLists = splitConfigValues(raw.Global)
+	o.Default, o.DefaultLists = splitConfigValues(raw.Default)
+	o.Include = raw.Include
+	o.Match = raw.Match
+	o.Groups = raw.Groups
+	return nil
 }
 
 var ExcludePatterns = []string{
@@ -0,0 +1,202 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package define
+
+import "strings"
+
+// Keywords are the ssh_config(5) client keywords in their canonical
+// spelling, Host and Match excluded, docs: https://man.openbsd.org/ssh_config
+var Keywords = []string{
+	"AddKeysToAgent",
+	"AddressFamily",
+	"BatchMode",
+	"BindAddress",
+	"BindInterface",
+	"CanonicalDomains",
+	"CanonicalizeFallbackLocal",
+	"CanonicalizeHostname",
+	"CanonicalizeMaxDots",
+	"CanonicalizePermittedCNAMEs",
+	"CASignatureAlgorithms",
+	"CertificateFile",
+	"ChannelTimeout",
+	"CheckHostIP",
+	"Ciphers",
+	"ClearAllForwardings",
+	"Compression",
+	"ConnectionAttempts",
+	"ConnectTimeout",
+	"ControlMaster",
+	"ControlPath",
+	"ControlPersist",
+	"DynamicForward",
+	"EnableEscapeCommandline",
+	"EnableSSHKeysign",
+	"EscapeChar",
+	"ExitOnForwardFailure",
+	"FingerprintHash",
+	"ForkAfterAuthentication",
+	"ForwardAgent",
+	"ForwardX11",
+	"ForwardX11Timeout",
+	"ForwardX11Trusted",
+	"GatewayPorts",
+	"GlobalKnownHostsFile",
+	"GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials",
+	"HashKnownHosts",
+	"HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication",
+	"HostKeyAlgorithms",
+	"HostKeyAlias",
+	"HostName",
+	"IdentitiesOnly",
+	"IdentityAgent",
+	"IdentityFile",
+	"IgnoreUnknown",
+	"Include",
+	"IPQoS",
+	"KbdInteractiveAuthentication",
+	"KbdInteractiveDevices",
+	"KexAlgorithms",
+	"KnownHostsCommand",
+	"LocalCommand",
+	"LocalForward",
+	"LogLevel",
+	"LogVerbose",
+	"MACs",
+	"NoHostAuthenticationForLocalhost",
+	"NumberOfPasswordPrompts",
+	"ObscureKeystrokeTiming",
+	"PasswordAuthentication",
+	"PermitLocalCommand",
+	"PermitRemoteOpen",
+	"PKCS11Provider",
+	"Port",
+	"PreferredAuthentications",
+	"ProxyCommand",
+	"ProxyJump",
+	"ProxyUseFdpass",
+	"PubkeyAcceptedAlgorithms",
+	"PubkeyAuthentication",
+	"RekeyLimit",
+	"RemoteCommand",
+	"RemoteForward",
+	"RequestTTY",
+	"RequiredRSASize",
+	"RevokedHostKeys",
+	"SecurityKeyProvider",
+	"SendEnv",
+	"ServerAliveCountMax",
+	"ServerAliveInterval",
+	"SessionType",
+	"SetEnv",
+	"StdinNull",
+	"StreamLocalBindMask",
+	"StreamLocalBindUnlink",
+	"StrictHostKeyChecking",
+	"SyslogFacility",
+	"Tag",
+	"TCPKeepAlive",
+	"Tunnel",
+	"TunnelDevice",
+	"UpdateHostKeys",
+	"User",
+	"UserKnownHostsFile",
+	"VerifyHostKeyDNS",
+	"VisualHostKey",
+	"XAuthLocation",
+}
+
+// keywordSpellings maps lower-cased keywords to their canonical spelling.
+var keywordSpellings = func() map[string]string {
+	spellings := make(map[string]string, len(Keywords))
+	for _, keyword := range Keywords {
+		spellings[strings.ToLower(keyword)] = keyword
+	}
+	return spellings
+}()
+
+// CanonicalKeyword returns the canonical spelling of key, matched ignoring
+// case, and false for a keyword ssh_config(5) does not define.
+func CanonicalKeyword(key string) (string, bool) {
+	keyword, ok := keywordSpellings[strings.ToLower(key)]
+	return keyword, ok
+}
+
+// KeywordAliases maps the lower-cased names OpenSSH still accepts for a
+// renamed keyword to the current keyword.
+var KeywordAliases = map[string]string{
+	"challengeresponseauthentication": "KbdInteractiveAuthentication",
+	"dsaauthentication":               "PubkeyAuthentication",
+	"hostbasedkeytypes":               "HostbasedAcceptedAlgorithms",
+	"pubkeyacceptedkeytypes":          "PubkeyAcceptedAlgorithms",
+	"skeyauthentication":              "KbdInteractiveAuthentication",
+	"tisauthentication":               "KbdInteractiveAuthentication",
+}
+
+// LegacyKeywords maps a renamed keyword to the name OpenSSH releases before
+// the rename know it by: 8.5 for the algorithm lists, 8.7 for
+// KbdInteractiveAuthentication.
+var LegacyKeywords = map[string]string{
+	"HostbasedAcceptedAlgorithms":  "HostbasedKeyTypes",
+	"KbdInteractiveAuthentication": "ChallengeResponseAuthentication",
+	"PubkeyAcceptedAlgorithms":     "PubkeyAcceptedKeyTypes",
+}
+
+// RemovedKeywords maps lower-cased keywords OpenSSH no longer supports to
+// their usual spelling, ssh ignores them.
+var RemovedKeywords = map[string]string{
+	"afstokenpassing":         "AFSTokenPassing",
+	"cipher":                  "Cipher",
+	"compressionlevel":        "CompressionLevel",
+	"fallbacktorsh":           "FallBackToRsh",
+	"globalknownhostsfile2":   "GlobalKnownHostsFile2",
+	"kerberosauthentication":  "KerberosAuthentication",
+	"kerberostgtpassing":      "KerberosTGTPassing",
+	"protocol":                "Protocol",
+	"rhostsauthentication":    "RhostsAuthentication",
+	"rhostsrsaauthentication": "RhostsRSAAuthentication",
+	"rsaauthentication":       "RSAAuthentication",
+	"useprivilegedport":       "UsePrivilegedPort",
+	"userknownhostsfile2":     "UserKnownHostsFile2",
+	"usersh":                  "UseRsh",
+	"useroaming":              "UseRoaming",
+}
+
+// ModernKeyword returns the current keyword an alias stands for, e.g.
+// PubkeyAcceptedAlgorithms for PubkeyAcceptedKeyTypes.
+func ModernKeyword(key string) (string, bool) {
+	keyword, ok := KeywordAliases[strings.ToLower(key)]
+	return keyword, ok
+}
+
+// RemovedKeyword returns the usual spelling of a keyword OpenSSH no longer
+// supports, e.g. UseRoaming.
+func RemovedKeyword(key string) (string, bool) {
+	keyword, ok := RemovedKeywords[strings.ToLower(key)]
+	return keyword, ok
+}
+
+// KeywordID identifies the option a keyword sets: keywords are compared
+// ignoring case and an alias is the same option as its current keyword.
+func KeywordID(key string) string {
+	if keyword, ok := ModernKeyword(key); ok {
+		key = keyword
+	}
+	return strings.ToLower(key)
+}
@@ -31,7 +31,25 @@ func FindGlobalConfig(configs []Define.HostConfig) (result []Define.HostConfig)
 
 func FindNormalConfig(configs []Define.HostConfig) (result []Define.HostConfig) {
 	for _, config := range configs {
-		if config.Name != "*" {
+		if config.Name != "*" && !config.IsMatch() && !config.IsInclude() {
+			result = append(result, config)
+		}
+	}
+	return result
+}
+
+func FindMatchConfig(configs []Define.HostConfig) (result []Define.HostConfig) {
+	for _, config := range configs {
+		if config.IsMatch() {
+			result = append(result, config)
+		}
+	}
+	return result
+}
+
+func FindIncludeConfig(configs []Define.HostConfig) (result []Define.HostConfig) {
+	for _, config := range configs {
+		if config.IsInclude() {
 			result = append(result, config)
 		}
 	}
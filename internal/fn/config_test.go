@@ -108,3 +108,21 @@ func TestFindNormalConfigNoNormal(t *testing.T) {
 		t.Errorf("FindNormalConfig() = %v, want %v", result, 0)
 	}
 }
+
+func TestFindMatchConfig(t *testing.T) {
+	configs := []Define.HostConfig{
+		{Name: "*", Config: map[string]string{"key1": "value1"}},
+		{Match: []Define.MatchCondition{{Criterion: "all"}}, Config: map[string]string{"key2": "value2"}},
+		{Name: "host1", Config: map[string]string{"key3": "value3"}},
+	}
+
+	result := Fn.FindMatchConfig(configs)
+	if len(result) != 1 || !reflect.DeepEqual(result[0], configs[1]) {
+		t.Errorf("FindMatchConfig() = %v, want %v", result, configs[1:2])
+	}
+
+	normal := Fn.FindNormalConfig(configs)
+	if len(normal) != 1 || normal[0].Name != "host1" {
+		t.Errorf("FindNormalConfig() = %v, want only host1", normal)
+	}
+}
@@ -0,0 +1,167 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each hunk.
+const diffContext = 3
+
+// diffLine is a line of an edit script: ' ' kept, '-' removed, '+' added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the changes from a to b as a unified diff, "" when
+// they are the same.
+func UnifiedDiff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+	script := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	lineA, lineB := 1, 1
+	for start := 0; start < len(script); {
+		if script[start].op == ' ' {
+			start++
+			lineA++
+			lineB++
+			continue
+		}
+		// a hunk runs until more than twice the context is unchanged
+		first := max(start-diffContext, 0)
+		end, kept := start, 0
+		for i := start; i < len(script) && kept <= 2*diffContext; i++ {
+			if script[i].op == ' ' {
+				kept++
+			} else {
+				kept, end = 0, i+1
+			}
+		}
+		last := min(end+diffContext, len(script))
+
+		hunkA, hunkB := lineA-(start-first), lineB-(start-first)
+		var countA, countB int
+		for _, line := range script[first:last] {
+			if line.op != '+' {
+				countA++
+			}
+			if line.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunkA, countA), hunkRange(hunkB, countB))
+		for _, line := range script[first:last] {
+			out.WriteByte(line.op)
+			out.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		for _, line := range script[start:last] {
+			if line.op != '+' {
+				lineA++
+			}
+			if line.op != '-' {
+				lineB++
+			}
+		}
+		start = last
+	}
+	return out.String()
+}
+
+// hunkRange formats the start and length of a hunk, an empty range starts
+// at the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text after each newline, so a missing final newline is
+// a change of its own.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edit script turning a into b, from their longest
+// common subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// common[i][j] is the length of the longest common subsequence of
+	// middleA[i:] and middleB[j:]
+	common := make([][]int, len(middleA)+1)
+	for i := range common {
+		common[i] = make([]int, len(middleB)+1)
+	}
+	for i := len(middleA) - 1; i >= 0; i-- {
+		for j := len(middleB) - 1; j >= 0; j-- {
+			if middleA[i] == middleB[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	script := make([]diffLine, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		script = append(script, diffLine{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(middleA) || j < len(middleB) {
+		switch {
+		case i < len(middleA) && j < len(middleB) && middleA[i] == middleB[j]:
+			script = append(script, diffLine{' ', middleA[i]})
+			i++
+			j++
+		case j == len(middleB) || i < len(middleA) && common[i+1][j] >= common[i][j+1]:
+			script = append(script, diffLine{'-', middleA[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', middleB[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		script = append(script, diffLine{' ', line})
+	}
+	return script
+}
@@ -0,0 +1,65 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn_test
+
+import (
+	"strings"
+	"testing"
+
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(from, to int) string {
+		var text strings.Builder
+		for i := from; i <= to; i++ {
+			text.WriteString("line " + string(rune('a'+i-1)) + "\n")
+		}
+		return text.String()
+	}
+	tests := []struct {
+		name, a, b, want string
+	}{
+		{"same", "a\n", "a\n", ""},
+		{
+			"two hunks",
+			lines(1, 20),
+			strings.Replace(strings.Replace(lines(1, 20), "line b\n", "line B\n", 1), "line r\n", "", 1),
+			"--- a\n+++ b\n@@ -1,5 +1,5 @@\n line a\n-line b\n+line B\n line c\n line d\n line e\n" +
+				"@@ -15,6 +15,5 @@\n line o\n line p\n line q\n-line r\n line s\n line t\n",
+		},
+		{
+			"insert into empty",
+			"",
+			"Host a\n",
+			"--- a\n+++ b\n@@ -0,0 +1 @@\n+Host a\n",
+		},
+		{
+			"missing newline",
+			"Host a\n  User b",
+			"Host a\n  User b\n",
+			"--- a\n+++ b\n@@ -1,2 +1,2 @@\n Host a\n-  User b\n\\ No newline at end of file\n+  User b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fn.UnifiedDiff("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
@@ -36,19 +37,28 @@ var (
 	mkdirAll  = os.MkdirAll
 )
 
+// GetUserInputFromStdin reads stdin as it is, line endings included, so
+// fmt -check compares the piped config byte for byte.
 func GetUserInputFromStdin() string {
-	var lines []string
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	input := strings.Join(lines, "\n")
-	return input
+	input, _ := io.ReadAll(os.Stdin)
+	return string(input)
 }
 
 type OrderedMap struct {
-	Keys []string
-	Data map[string]string
+	Keys  []string
+	Data  map[string]string
+	Lists map[string][]string
+}
+
+// Values returns every value of key, repeated directives included.
+func (o OrderedMap) Values(key string) []string {
+	if values, ok := o.Lists[key]; ok {
+		return values
+	}
+	if value, ok := o.Data[key]; ok {
+		return []string{value}
+	}
+	return nil
 }
 
 func GetOrderMaps(m map[string]string) OrderedMap {
@@ -65,10 +75,31 @@ func GetOrderMaps(m map[string]string) OrderedMap {
 	}
 }
 
+// GetOrderConfig is GetOrderMaps for a host config, repeated directives included.
+func GetOrderConfig(config map[string]string, lists map[string][]string) OrderedMap {
+	keys := make([]string, 0, len(config)+len(lists))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	for k := range lists {
+		if _, ok := config[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return OrderedMap{
+		Keys:  keys,
+		Data:  config,
+		Lists: lists,
+	}
+}
+
 func GetYamlBytes(data any) []byte {
 	yamlData, err := yaml.Marshal(&data)
 	if err != nil {
-		fmt.Println("Error marshaling to YAML:", err)
+		fmt.Fprintln(os.Stderr, "Error marshaling to YAML:", err)
 		return nil
 	}
 	return yamlData
@@ -77,7 +108,7 @@ func GetYamlBytes(data any) []byte {
 func GetYamlData(input string) (yamlConfig Define.YAMLOutput) {
 	err := yaml.Unmarshal([]byte(input), &yamlConfig)
 	if err != nil {
-		fmt.Println("Error unmarshalling YAML:", err)
+		fmt.Fprintln(os.Stderr, "Error unmarshalling YAML:", err)
 		return yamlConfig
 	}
 	return yamlConfig
@@ -86,7 +117,7 @@ func GetYamlData(input string) (yamlConfig Define.YAMLOutput) {
 func GetJSONBytes(data any) []byte {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		fmt.Println("Error marshaling to JSON:", err)
+		fmt.Fprintln(os.Stderr, "Error marshaling to JSON:", err)
 		return nil
 	}
 	return jsonData
@@ -95,7 +126,7 @@ func GetJSONBytes(data any) []byte {
 func GetJSONData(input string) (jsonConfig []Define.HostConfigForJSON) {
 	err := json.Unmarshal([]byte(input), &jsonConfig)
 	if err != nil {
-		fmt.Println("Error unmarshalling JSON:", err)
+		fmt.Fprintln(os.Stderr, "Error unmarshalling JSON:", err)
 		return jsonConfig
 	}
 	return jsonConfig
@@ -121,6 +152,19 @@ func DetectStringType(input string) string {
 }
 
 func GetPathContent(src string) ([]byte, error) {
+	// a directory holding an ssh config is read from that file, its Include
+	// lines decide which other files belong to the configuration
+	if info, err := stat(src); err == nil && info.IsDir() {
+		entry := filepath.Join(src, DefaultConfigName)
+		if entryInfo, err := stat(entry); err == nil && !entryInfo.IsDir() {
+			content, err := readFile(entry)
+			if err != nil {
+				return nil, fmt.Errorf("no valid SSH config found in %s: %w", src, err)
+			}
+			return content, nil
+		}
+	}
+
 	configFiles, err := ReadSSHConfigs(src)
 	if err != nil {
 		return nil, err
@@ -146,6 +190,35 @@ func GetPathContent(src string) ([]byte, error) {
 	return content, nil
 }
 
+// SourceFile returns the file GetPathContent reads src from, an error when
+// it reads several.
+func SourceFile(src string) (string, error) {
+	info, err := stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return src, nil
+	}
+	entry := filepath.Join(src, DefaultConfigName)
+	if entryInfo, err := stat(entry); err == nil && !entryInfo.IsDir() {
+		return entry, nil
+	}
+	return "", fmt.Errorf("%s holds several configs, use -src with a single file", src)
+}
+
+// Confirm asks question on stdout and reports whether the answer read from
+// stdin is yes.
+func Confirm(question string) bool {
+	fmt.Print(question + " [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 func Save(dest string, content []byte) error {
 	destDir := filepath.Dir(dest)
 	if err := ensureDirectory(destDir); err != nil {
@@ -50,6 +50,11 @@ func TestGetUserInputFromStdin(t *testing.T) {
 			input:    "Line 1\nLine 2\nLine 3",
 			expected: "Line 1\nLine 2\nLine 3",
 		},
+		{
+			name:     "Final newline and CRLF",
+			input:    "Line 1\r\nLine 2\n",
+			expected: "Line 1\r\nLine 2\n",
+		},
 		{
 			name:     "Empty input",
 			input:    "",
@@ -137,6 +142,25 @@ func TestGetOrderMaps(t *testing.T) {
 		})
 	}
 }
+func TestGetOrderConfig(t *testing.T) {
+	config := map[string]string{"User": "root", "HostName": "example.com"}
+	lists := map[string][]string{"IdentityFile": {"~/.ssh/a", "~/.ssh/b"}}
+
+	result := Fn.GetOrderConfig(config, lists)
+	if !reflect.DeepEqual(result.Keys, []string{"HostName", "IdentityFile", "User"}) {
+		t.Errorf("GetOrderConfig() Keys = %v", result.Keys)
+	}
+	if got := result.Values("IdentityFile"); !reflect.DeepEqual(got, []string{"~/.ssh/a", "~/.ssh/b"}) {
+		t.Errorf("Values(IdentityFile) = %v", got)
+	}
+	if got := result.Values("User"); !reflect.DeepEqual(got, []string{"root"}) {
+		t.Errorf("Values(User) = %v", got)
+	}
+	if got := result.Values("Port"); got != nil {
+		t.Errorf("Values(Port) = %v, want nil", got)
+	}
+}
+
 func TestGetYamlBytes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -879,3 +903,19 @@ func TestGetPathContent2(t *testing.T) {
 		}
 	})
 }
+
+func TestSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Fn.SourceFile(dir); err == nil {
+		t.Error("SourceFile() expected an error for a directory without config")
+	}
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("Host a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range []string{dir, config} {
+		if got, err := Fn.SourceFile(src); err != nil || got != config {
+			t.Errorf("SourceFile(%q) = %q, %v, want %q", src, got, err, config)
+		}
+	}
+}
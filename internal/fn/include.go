@@ -0,0 +1,195 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+)
+
+// MaxIncludeDepth matches the nesting limit of OpenSSH (READCONF_MAX_DEPTH).
+const MaxIncludeDepth = 16
+
+// DefaultConfigName is the file ssh reads inside ~/.ssh.
+const DefaultConfigName = "config"
+
+var (
+	glob        = filepath.Glob
+	userHomeDir = os.UserHomeDir
+)
+
+type IncludeOptions struct {
+	// Source is the path of the file being resolved, used to detect cycles.
+	Source string
+	// BaseDir is where relative Include paths are looked up, ~/.ssh when empty.
+	BaseDir string
+	// HomeDir replaces "~", the current user's home directory when empty.
+	HomeDir string
+	// MaxDepth limits nested includes, MaxIncludeDepth when zero.
+	MaxDepth int
+}
+
+// IncludeSource returns the file ssh would start from when reading src.
+func IncludeSource(src string) string {
+	if src == "" {
+		return ""
+	}
+	if info, err := stat(src); err == nil && info.IsDir() {
+		return filepath.Join(src, DefaultConfigName)
+	}
+	return src
+}
+
+// ResolveIncludes replaces every Include line of input with the content of the
+// files it matches, recursively, the way ssh reads them. An Include inside a
+// Host or Match block whose files open blocks of their own is kept as it is:
+// ssh only reads those blocks for the hosts the enclosing one applies to,
+// which the inlined blocks could not tell.
+func ResolveIncludes(input string, options IncludeOptions) (string, error) {
+	if options.HomeDir == "" {
+		home, err := userHomeDir()
+		if err != nil && options.BaseDir == "" {
+			return "", fmt.Errorf("can not resolve include: %v", err)
+		}
+		options.HomeDir = home
+	}
+	if options.BaseDir == "" {
+		options.BaseDir = filepath.Join(options.HomeDir, ".ssh")
+	}
+	if options.MaxDepth == 0 {
+		options.MaxDepth = MaxIncludeDepth
+	}
+
+	var stack []string
+	if options.Source != "" {
+		if source, err := filepath.Abs(options.Source); err == nil {
+			stack = append(stack, source)
+		}
+	}
+	output, _, err := resolveIncludes(input, options, stack, 0)
+	return output, err
+}
+
+// resolveIncludes also reports whether the resolved text opens Host or Match blocks.
+func resolveIncludes(input string, options IncludeOptions, stack []string, depth int) (string, bool, error) {
+	lines := strings.Split(input, "\n")
+	output := make([]string, 0, len(lines))
+	// set once a Host or Match line opens the block later lines belong to
+	inBlock := false
+	opensBlocks := false
+
+	for _, line := range lines {
+		keyword, args := lineKeyword(line)
+		switch keyword {
+		case "host", "match":
+			inBlock = true
+			opensBlocks = true
+		case "include":
+			if depth >= options.MaxDepth {
+				return "", false, fmt.Errorf("include nested too deeply (more than %d levels)", options.MaxDepth)
+			}
+			var included []string
+			includedBlocks := false
+			for _, pattern := range args {
+				files, err := expandInclude(pattern, options)
+				if err != nil {
+					return "", false, err
+				}
+				for _, file := range files {
+					content, fileBlocks, err := includeFile(file, options, stack, depth)
+					if err != nil {
+						return "", false, err
+					}
+					if content != "" {
+						included = append(included, strings.TrimRight(content, "\n"))
+					}
+					includedBlocks = includedBlocks || fileBlocks
+				}
+			}
+			if inBlock && includedBlocks {
+				break
+			}
+			opensBlocks = opensBlocks || includedBlocks
+			output = append(output, included...)
+			continue
+		}
+		output = append(output, line)
+	}
+	return strings.Join(output, "\n"), opensBlocks, nil
+}
+
+func includeFile(file string, options IncludeOptions, stack []string, depth int) (string, bool, error) {
+	for index, parent := range stack {
+		if parent == file {
+			chain := append(append([]string{}, stack[index:]...), file)
+			return "", false, fmt.Errorf("include cycle detected: %s", strings.Join(chain, " -> "))
+		}
+	}
+	info, err := stat(file)
+	if err != nil {
+		return "", false, fmt.Errorf("can not read include %s: %v", file, err)
+	}
+	if info.IsDir() {
+		return "", false, nil
+	}
+	content, err := readFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("can not read include %s: %v", file, err)
+	}
+	return resolveIncludes(string(content), options, append(stack, file), depth+1)
+}
+
+// expandInclude turns an Include argument into the files it names, sorted like glob(3).
+func expandInclude(pattern string, options IncludeOptions) ([]string, error) {
+	if pattern == "~" {
+		pattern = options.HomeDir
+	} else if strings.HasPrefix(pattern, "~/") {
+		pattern = filepath.Join(options.HomeDir, pattern[2:])
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(options.BaseDir, pattern)
+	}
+	files, err := glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %s: %v", pattern, err)
+	}
+	for index, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			files[index] = abs
+		}
+	}
+	return files, nil
+}
+
+// lineKeyword returns the lower-cased block keyword of line and its arguments.
+func lineKeyword(line string) (string, []string) {
+	tokens, err := lexer.Lex(line)
+	if err != nil || len(tokens) == 0 || tokens[0].Kind != lexer.TokenKeyword {
+		return "", nil
+	}
+	var args []string
+	for _, token := range tokens[1:] {
+		if token.Kind == lexer.TokenValue || token.Kind == lexer.TokenQuoted {
+			args = append(args, token.Value)
+		}
+	}
+	return strings.ToLower(tokens[0].Value), args
+}
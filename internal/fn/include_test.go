@@ -0,0 +1,150 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+)
+
+func writeIncludeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestResolveIncludes(t *testing.T) {
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	writeIncludeFiles(t, sshDir, map[string]string{
+		"config.d/10-work":  "Host work\n    HostName work.example.com\n",
+		"config.d/20-home":  "Host home\n    HostName home.example.com\nInclude nested/*.conf\n",
+		"nested/a.conf":     "Host nested\n    User nested\n",
+		"extra/common.conf": "    User shared\n",
+	})
+
+	input := "Include config.d/*\nHost *\n    Include ~/.ssh/extra/common.conf\n    Port 22\nInclude missing/*\n"
+	output, err := Fn.ResolveIncludes(input, Fn.IncludeOptions{HomeDir: home})
+	if err != nil {
+		t.Fatalf("ResolveIncludes() error = %v", err)
+	}
+	expected := "Host work\n    HostName work.example.com\n" +
+		"Host home\n    HostName home.example.com\nInclude nested/*.conf\n" +
+		"Host *\n    User shared\n    Port 22\n"
+	if output != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", output, expected)
+	}
+}
+
+func TestResolveIncludes_KeepsIncludeOpeningBlocksInBlock(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"hosts.conf": "Host other\n    User other\n",
+		"user.conf":  "    User shared\n",
+	})
+
+	// ssh only reads Host other when connecting to a host Host main matches
+	input := "Host main\n    Include hosts.conf user.conf\n    User main\nMatch host db\n    Include user.conf\n"
+	output, err := Fn.ResolveIncludes(input, Fn.IncludeOptions{BaseDir: dir, HomeDir: dir})
+	if err != nil {
+		t.Fatalf("ResolveIncludes() error = %v", err)
+	}
+	expected := "Host main\n    Include hosts.conf user.conf\n    User main\nMatch host db\n    User shared\n"
+	if output != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", output, expected)
+	}
+}
+
+func TestResolveIncludes_Cycle(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"config": "Include a.conf\n",
+		"a.conf": "Include b.conf\n",
+		"b.conf": "Include config\n",
+	})
+
+	source := filepath.Join(dir, "config")
+	_, err := Fn.ResolveIncludes("Include a.conf\n", Fn.IncludeOptions{Source: source, BaseDir: dir, HomeDir: dir})
+	if err == nil || !strings.Contains(err.Error(), "include cycle detected") {
+		t.Fatalf("ResolveIncludes() error = %v, want include cycle", err)
+	}
+	if !strings.Contains(err.Error(), "a.conf -> ") {
+		t.Errorf("ResolveIncludes() error = %v, want the include chain", err)
+	}
+}
+
+func TestResolveIncludes_MaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"1.conf": "Include 2.conf\n",
+		"2.conf": "Include 3.conf\n",
+		"3.conf": "Host deep\n",
+	})
+
+	options := Fn.IncludeOptions{BaseDir: dir, HomeDir: dir, MaxDepth: 2}
+	_, err := Fn.ResolveIncludes("Include 1.conf\n", options)
+	if err == nil || !strings.Contains(err.Error(), "nested too deeply") {
+		t.Fatalf("ResolveIncludes() error = %v, want depth error", err)
+	}
+
+	options.MaxDepth = 3
+	output, err := Fn.ResolveIncludes("Include 1.conf\n", options)
+	if err != nil || output != "Host deep\n" {
+		t.Errorf("ResolveIncludes() = %q, %v", output, err)
+	}
+}
+
+func TestIncludeSource(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config")
+	writeIncludeFiles(t, dir, map[string]string{"config": "Host a\n"})
+
+	if got := Fn.IncludeSource(dir); got != file {
+		t.Errorf("IncludeSource(dir) = %q, want %q", got, file)
+	}
+	if got := Fn.IncludeSource(""); got != "" {
+		t.Errorf("IncludeSource(\"\") = %q, want empty", got)
+	}
+}
+
+func TestGetPathContent_ConfigEntryPoint(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"config":          "Include config.d/*\nHost main\n",
+		"config.d/extra":  "Host extra\n",
+		"unreferenced.cf": "Host unreferenced\n",
+	})
+
+	content, err := Fn.GetPathContent(dir)
+	if err != nil {
+		t.Fatalf("GetPathContent() error = %v", err)
+	}
+	if string(content) != "Include config.d/*\nHost main\n" {
+		t.Errorf("GetPathContent() = %q, want only the config entry point", content)
+	}
+}
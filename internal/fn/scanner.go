@@ -193,6 +193,8 @@ func ReadSingleConfig(path string) *ConfigFile {
 	return config
 }
 
+// GetHostConfig returns, per file, the block whose Host line is exactly host.
+// It does not match patterns, parser.Resolve gives the settings ssh applies.
 func (c *SSHConfig) GetHostConfig(host string) map[string]map[string]string {
 	results := make(map[string]map[string]string)
 
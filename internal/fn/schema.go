@@ -0,0 +1,581 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	"gopkg.in/yaml.v2"
+)
+
+// SchemaError is a field or keyword the YAML and JSON formats do not define.
+type SchemaError struct {
+	// Line is 0 when the position is unknown, e.g. for YAML flow mappings.
+	Line int
+	// Path leads to the field, e.g. "Group web.Hosts.web.config.ProxyJmp".
+	Path    string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Position returns the line of the error, its column is unknown.
+func (e SchemaError) Position() (int, int, string) {
+	return e.Line, 0, fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// SchemaErrors are every problem of a document, in document order.
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e SchemaErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// GetYamlDataStrict is GetYamlData refusing unknown fields and keywords.
+func GetYamlDataStrict(input string) (yamlConfig Define.YAMLOutput, err error) {
+	if err := CheckYAMLSchema(input); err != nil {
+		return yamlConfig, err
+	}
+	if err := yaml.UnmarshalStrict([]byte(input), &yamlConfig); err != nil {
+		return yamlConfig, err
+	}
+	return yamlConfig, nil
+}
+
+// GetJSONDataStrict is GetJSONData refusing unknown fields and keywords.
+func GetJSONDataStrict(input string) (jsonConfig []Define.HostConfigForJSON, err error) {
+	if err := CheckJSONSchema(input); err != nil {
+		return jsonConfig, err
+	}
+	if err := json.Unmarshal([]byte(input), &jsonConfig); err != nil {
+		return jsonConfig, err
+	}
+	return jsonConfig, nil
+}
+
+// node is a decoded document value and where it starts.
+type node struct {
+	line   int
+	fields []nodeField
+	items  []*node
+	// isMap and isList tell an empty mapping or list from a scalar.
+	isMap  bool
+	isList bool
+	value  any
+}
+
+type nodeField struct {
+	key   string
+	line  int
+	value *node
+}
+
+// schema describes a mapping of the YAML and JSON formats.
+type schema struct {
+	fields map[string]*schema
+	// keywords holds ssh keywords and their values.
+	keywords bool
+	// items is the schema of the elements of a list.
+	items *schema
+	// names is the schema of the values of a mapping keyed by free names,
+	// such as the hosts of a group.
+	names *schema
+	// criteria holds a Match criterion.
+	criteria bool
+	// foldCase matches fields ignoring case, like encoding/json does.
+	foldCase bool
+}
+
+var (
+	scalarSchema     = &schema{}
+	keywordSchema    = &schema{keywords: true}
+	stringListSchema = &schema{items: scalarSchema}
+	patternSchema    = &schema{fields: map[string]*schema{"Pattern": scalarSchema, "Negate": scalarSchema, "Wildcard": scalarSchema}}
+	conditionSchema  = &schema{fields: map[string]*schema{"Criterion": {criteria: true}, "Negate": scalarSchema, "Argument": scalarSchema}}
+
+	yamlHostSchema = &schema{fields: map[string]*schema{
+		"Name":     scalarSchema,
+		"Notes":    scalarSchema,
+		"Patterns": {items: patternSchema},
+		"config":   keywordSchema,
+		"Match":    {items: conditionSchema},
+		"Extra":    {fields: map[string]*schema{"Prefix": scalarSchema}},
+	}}
+	yamlGroupSchema = &schema{fields: map[string]*schema{
+		"Prefix": scalarSchema,
+		"Common": keywordSchema,
+		"Hosts":  {names: yamlHostSchema},
+		"Match":  {items: yamlHostSchema},
+	}}
+	yamlTopSchema = map[string]*schema{
+		"global":  keywordSchema,
+		"default": keywordSchema,
+		"include": stringListSchema,
+		"match":   {items: yamlHostSchema},
+	}
+
+	jsonHostSchema = &schema{foldCase: true, fields: map[string]*schema{
+		"Name":     scalarSchema,
+		"Notes":    scalarSchema,
+		"Patterns": {items: patternSchema},
+		"Match":    {items: conditionSchema},
+		"Include":  stringListSchema,
+		"Data":     keywordSchema,
+	}}
+)
+
+// schemaChecker collects the problems of a document.
+type schemaChecker struct {
+	errs SchemaErrors
+}
+
+func (c *schemaChecker) report(line int, path string, format string, args ...any) {
+	c.errs = append(c.errs, SchemaError{Line: line, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *schemaChecker) result() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(c.errs, func(a, b SchemaError) int { return a.Line - b.Line })
+	return c.errs
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// check validates value, found at path, against s.
+func (c *schemaChecker) check(value *node, s *schema, path string) {
+	switch {
+	case s.items != nil:
+		if !value.isList {
+			if value.value == nil && !value.isMap {
+				return
+			}
+			c.report(value.line, path, "expected a list")
+			return
+		}
+		for i, item := range value.items {
+			c.check(item, s.items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case s.keywords:
+		c.checkKeywords(value, path)
+	case s.criteria:
+		criterion := fmt.Sprint(value.value)
+		if value.isMap || value.isList || !slices.Contains(Define.MatchCriteria, strings.ToLower(criterion)) {
+			c.report(value.line, path, "unknown criterion %q%s", criterion, suggest(criterion, Define.MatchCriteria))
+		}
+	case s.fields != nil:
+		if !value.isMap {
+			if value.value == nil && !value.isList {
+				return
+			}
+			c.report(value.line, path, "expected a mapping")
+			return
+		}
+		names := slices.Sorted(maps.Keys(s.fields))
+		for _, field := range value.fields {
+			child, ok := s.fields[field.key]
+			if !ok && s.foldCase {
+				if i := slices.IndexFunc(names, func(name string) bool { return strings.EqualFold(name, field.key) }); i >= 0 {
+					child, ok = s.fields[names[i]], true
+				}
+			}
+			if !ok {
+				c.report(field.line, joinPath(path, field.key), "unknown field %q%s", field.key, suggest(field.key, names))
+				continue
+			}
+			c.check(field.value, child, joinPath(path, field.key))
+		}
+	case s.names != nil:
+		if !value.isMap {
+			if value.value == nil && !value.isList {
+				return
+			}
+			c.report(value.line, path, "expected a mapping")
+			return
+		}
+		for _, field := range value.fields {
+			c.check(field.value, s.names, joinPath(path, field.key))
+		}
+	default:
+		if value.isMap || value.isList {
+			c.report(value.line, path, "expected a single value")
+		}
+	}
+}
+
+// checkKeywords validates a mapping of ssh keywords to a value or a list of
+// values. Keywords matched by an IgnoreUnknown of the same mapping are
+// accepted, like ssh does.
+func (c *schemaChecker) checkKeywords(value *node, path string) {
+	if !value.isMap {
+		if value.value == nil && !value.isList {
+			return
+		}
+		c.report(value.line, path, "expected a mapping of keywords")
+		return
+	}
+	var ignored []Define.HostPattern
+	for _, field := range value.fields {
+		if strings.EqualFold(field.key, "IgnoreUnknown") && !field.value.isMap && !field.value.isList {
+			ignored = Define.ParsePatternList(fmt.Sprint(field.value.value))
+		}
+	}
+	for _, field := range value.fields {
+		fieldPath := joinPath(path, field.key)
+		if !isKnownKeyword(field.key) && !Define.MatchHostPatterns(ignored, field.key) {
+			c.report(field.line, fieldPath, "unknown keyword %q%s", field.key, suggest(field.key, Define.Keywords))
+			continue
+		}
+		if field.value.isMap || slices.ContainsFunc(field.value.items, func(item *node) bool { return item.isMap || item.isList }) {
+			c.report(field.value.line, fieldPath, "expected a value or a list of values")
+		}
+	}
+}
+
+func isKnownKeyword(key string) bool {
+	if _, ok := Define.CanonicalKeyword(key); ok {
+		return true
+	}
+	if _, ok := Define.ModernKeyword(key); ok {
+		return true
+	}
+	_, ok := Define.RemovedKeyword(key)
+	return ok
+}
+
+// CheckYAMLSchema reports the unknown fields and keywords of a YAML
+// document. Top-level keys other than global, default, include and match
+// are groups.
+func CheckYAMLSchema(input string) error {
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(input), &document); err != nil {
+		return err
+	}
+	lines := indexYAMLLines(input)
+	checker := &schemaChecker{}
+	reserved := slices.Sorted(maps.Keys(yamlTopSchema))
+	for _, item := range document {
+		key := fmt.Sprint(item.Key)
+		value := yamlNode(item.Value, []string{key}, lines)
+		line := lines[key]
+		if s, ok := yamlTopSchema[key]; ok {
+			checker.check(value, s, key)
+			continue
+		}
+		// a misspelled reserved key reads as a group, unless it holds one
+		if suggestion := suggest(key, reserved); suggestion != "" && !looksLikeGroup(value) {
+			checker.report(line, key, "unknown top-level key %q%s", key, suggestion)
+			continue
+		}
+		if !value.isMap {
+			checker.report(line, key, "unknown top-level key %q, a group is a mapping", key)
+			continue
+		}
+		checker.check(value, yamlGroupSchema, key)
+	}
+	return checker.result()
+}
+
+// looksLikeGroup reports whether value has a group field.
+func looksLikeGroup(value *node) bool {
+	return slices.ContainsFunc(value.fields, func(field nodeField) bool {
+		_, ok := yamlGroupSchema.fields[field.key]
+		return ok
+	})
+}
+
+// yamlNode converts a decoded YAML value at path into a node.
+func yamlNode(value any, path []string, lines map[string]int) *node {
+	n := &node{line: lines[strings.Join(path, yamlPathSeparator)]}
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		n.isMap = true
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			childPath := append(slices.Clone(path), key)
+			n.fields = append(n.fields, nodeField{
+				key:   key,
+				line:  lines[strings.Join(childPath, yamlPathSeparator)],
+				value: yamlNode(item.Value, childPath, lines),
+			})
+		}
+	case []any:
+		n.isList = true
+		for i, item := range v {
+			n.items = append(n.items, yamlNode(item, append(slices.Clone(path), "["+strconv.Itoa(i)+"]"), lines))
+		}
+	default:
+		n.value = v
+	}
+	return n
+}
+
+const yamlPathSeparator = "\x1f"
+
+// indexYAMLLines returns the line of every key and list item of a block
+// style YAML document, keyed by its path joined with yamlPathSeparator. List
+// items are "[index]". Flow style collections are not indexed.
+func indexYAMLLines(input string) map[string]int {
+	type entry struct {
+		indent int
+		path   []string
+		item   bool
+	}
+	lines := make(map[string]int)
+	counters := make(map[string]int)
+	var stack []entry
+	parent := func() []string {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1].path
+	}
+	for number, line := range strings.Split(input, "\n") {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+		content = strings.TrimRight(content, " \r")
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" || content == "..." {
+			continue
+		}
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent || stack[len(stack)-1].indent == indent && stack[len(stack)-1].item) {
+				stack = stack[:len(stack)-1]
+			}
+			list := strings.Join(parent(), yamlPathSeparator)
+			index := counters[list]
+			counters[list]++
+			path := append(slices.Clone(parent()), "["+strconv.Itoa(index)+"]")
+			lines[strings.Join(path, yamlPathSeparator)] = number + 1
+			stack = append(stack, entry{indent: indent, path: path, item: true})
+			rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+		key, ok := yamlKey(content)
+		if !ok {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := append(slices.Clone(parent()), key)
+		joined := strings.Join(path, yamlPathSeparator)
+		if _, seen := lines[joined]; !seen {
+			lines[joined] = number + 1
+		}
+		// a new mapping restarts the numbering of the lists below it
+		delete(counters, joined)
+		stack = append(stack, entry{indent: indent, path: path})
+	}
+	return lines
+}
+
+// YAMLLines holds the line of every key and list item of a YAML document.
+type YAMLLines map[string]int
+
+// IndexYAMLLines indexes the lines of a block style YAML document.
+func IndexYAMLLines(input string) YAMLLines {
+	return indexYAMLLines(input)
+}
+
+// Line returns the line of the key or list item at path, 0 when it is not
+// indexed. List items are "[index]".
+func (l YAMLLines) Line(path ...string) int {
+	return l[strings.Join(path, yamlPathSeparator)]
+}
+
+// Keys returns the keys right below path with their line.
+func (l YAMLLines) Keys(path ...string) map[string]int {
+	prefix := strings.Join(path, yamlPathSeparator) + yamlPathSeparator
+	keys := make(map[string]int)
+	for joined, line := range l {
+		if key, ok := strings.CutPrefix(joined, prefix); ok && !strings.Contains(key, yamlPathSeparator) {
+			keys[key] = line
+		}
+	}
+	return keys
+}
+
+// yamlKey returns the key of a "key: value" line.
+func yamlKey(content string) (string, bool) {
+	if content == "" {
+		return "", false
+	}
+	if quote := content[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(content[1:], quote)
+		if end < 0 || !strings.HasPrefix(content[end+2:], ":") {
+			return "", false
+		}
+		return content[1 : end+1], true
+	}
+	if content[0] == '{' || content[0] == '[' {
+		return "", false
+	}
+	for i := 0; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			return strings.TrimRight(content[:i], " "), true
+		}
+		if content[i] == '#' && i > 0 && content[i-1] == ' ' {
+			break
+		}
+	}
+	return "", false
+}
+
+// CheckJSONSchema reports the unknown fields and keywords of a JSON document.
+// Field names are matched ignoring case, like encoding/json does.
+func CheckJSONSchema(input string) error {
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	root, err := jsonNode(decoder, []byte(input))
+	if err != nil {
+		return err
+	}
+	checker := &schemaChecker{}
+	checker.check(root, &schema{items: jsonHostSchema}, "")
+	return checker.result()
+}
+
+// jsonNode reads the next value of decoder. Lines are those of the end of
+// the first token of the value, keys are on the line they end.
+func jsonNode(decoder *json.Decoder, input []byte) (*node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unexpected end of JSON input")
+		}
+		return nil, err
+	}
+	n := &node{line: lineAt(input, decoder.InputOffset())}
+	switch token {
+	case json.Delim('{'):
+		n.isMap = true
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			line := lineAt(input, decoder.InputOffset())
+			value, err := jsonNode(decoder, input)
+			if err != nil {
+				return nil, err
+			}
+			n.fields = append(n.fields, nodeField{key: fmt.Sprint(keyToken), line: line, value: value})
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		n.isList = true
+		for decoder.More() {
+			item, err := jsonNode(decoder, input)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		_, err = decoder.Token()
+	default:
+		n.value = token
+	}
+	return n, err
+}
+
+// lineAt returns the line of the byte before offset.
+func lineAt(input []byte, offset int64) int {
+	if offset > int64(len(input)) {
+		offset = int64(len(input))
+	}
+	if offset > 0 {
+		offset--
+	}
+	return bytes.Count(input[:offset], []byte("\n")) + 1
+}
+
+// suggest returns ", did you mean X?" for the candidate closest to word, or
+// "" when none is close. Case differences alone count as close.
+func suggest(word string, candidates []string) string {
+	best, bestDistance := "", -1
+	limit := max(1, len(word)/4)
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(word), strings.ToLower(candidate))
+		if distance > limit || bestDistance >= 0 && distance >= bestDistance {
+			continue
+		}
+		best, bestDistance = candidate, distance
+	}
+	if best == "" || best == word {
+		return ""
+	}
+	return ", did you mean " + best + "?"
+}
+
+// editDistance is the number of single character insertions, deletions,
+// substitutions and adjacent transpositions turning a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fn_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+)
+
+func schemaMessages(t *testing.T, err error) []string {
+	t.Helper()
+	var errs Fn.SchemaErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error = %v, want SchemaErrors", err)
+	}
+	return strings.Split(errs.Error(), "\n")
+}
+
+func TestCheckYAMLSchema(t *testing.T) {
+	input := `globl:
+  User: root
+Group web:
+  Comon:
+    User: deploy
+  Hosts:
+    web:
+      config:
+        ProxyJmp: bastion
+        IgnoreUnknown: UseKeychain
+        UseKeychain: "yes"
+        Port:
+          value: 22
+      Match:
+      - Criterion: hots
+  Match:
+  - Match:
+    - Criterion: host
+      Argumnt: x
+    config:
+      Usr: a
+include: conf.d/*
+extra: value
+`
+	want := []string{
+		`line 1: globl: unknown top-level key "globl", did you mean global?`,
+		`line 4: Group web.Comon: unknown field "Comon", did you mean Common?`,
+		`line 9: Group web.Hosts.web.config.ProxyJmp: unknown keyword "ProxyJmp", did you mean ProxyJump?`,
+		`line 12: Group web.Hosts.web.config.Port: expected a value or a list of values`,
+		`line 15: Group web.Hosts.web.Match[0].Criterion: unknown criterion "hots", did you mean host?`,
+		`line 19: Group web.Match[0].Match[0].Argumnt: unknown field "Argumnt", did you mean Argument?`,
+		`line 21: Group web.Match[0].config.Usr: unknown keyword "Usr", did you mean User?`,
+		`line 22: include: expected a list`,
+		`line 23: extra: unknown top-level key "extra", a group is a mapping`,
+	}
+	if got := schemaMessages(t, Fn.CheckYAMLSchema(input)); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckYAMLSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	input := `[
+  {
+    "name": "web",
+    "Data": {
+      "hostname": "web.example.com",
+      "ProxyJmp": "bastion",
+      "IdentityFile": ["a", {"b": 1}]
+    },
+    "Dat": {}
+  },
+  {"Match": [{"Criterion": "exec", "Argument": "true"}], "Data": {"Port": 22}}
+]`
+	want := []string{
+		`line 6: [0].Data.ProxyJmp: unknown keyword "ProxyJmp", did you mean ProxyJump?`,
+		`line 7: [0].Data.IdentityFile: expected a value or a list of values`,
+		`line 9: [0].Dat: unknown field "Dat", did you mean Data?`,
+	}
+	if got := schemaMessages(t, Fn.CheckJSONSchema(input)); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckJSONSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := Fn.CheckJSONSchema(`[{"Name": "web"`); err == nil || errors.As(err, new(Fn.SchemaErrors)) {
+		t.Errorf("CheckJSONSchema() error = %v, want a syntax error", err)
+	}
+}
+
+func TestGetDataStrict_TestData(t *testing.T) {
+	for _, name := range []string{"main-test.yaml", "parser-yaml-group.yaml", "parser-yaml-with-default.yaml", "parser-yaml-with-group-common.yaml", "parser-yaml-with-group-prefix.yaml"} {
+		content, err := os.ReadFile("../../testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		strict, err := Fn.GetYamlDataStrict(string(content))
+		if err != nil {
+			t.Errorf("GetYamlDataStrict(%s) error = %v", name, err)
+		}
+		if loose := Fn.GetYamlData(string(content)); !reflect.DeepEqual(strict, loose) {
+			t.Errorf("GetYamlDataStrict(%s) = %+v, want %+v", name, strict, loose)
+		}
+	}
+	for _, name := range []string{"main-test.json", "parser-json.json"} {
+		content, err := os.ReadFile("../../testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Fn.GetJSONDataStrict(string(content)); err != nil {
+			t.Errorf("GetJSONDataStrict(%s) error = %v", name, err)
+		}
+	}
+}
+
+func TestGetYamlDataStrict_DuplicateKey(t *testing.T) {
+	input := "global:\n  User: a\n  User: b\n"
+	if _, err := Fn.GetYamlDataStrict(input); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("GetYamlDataStrict() error = %v, want the duplicate key at line 3", err)
+	}
+}
+
+func TestIndexYAMLLines(t *testing.T) {
+	lines := Fn.IndexYAMLLines("global:\n  User: a\nGroup web:\n  Match:\n  - config:\n      Port: 22\n")
+	if got := lines.Line("Group web", "Match", "[0]", "config", "Port"); got != 6 {
+		t.Errorf("Line() = %d, want 6", got)
+	}
+	if got, want := lines.Keys("global"), map[string]int{"User": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
@@ -72,6 +72,7 @@ type HostConfig struct {
 	HostName                    string `yaml:"HostName,omitempty"`
 
 	IdentitiesOnly string `yaml:"IdentitiesOnly,omitempty"`
+	IdentityAgent  string `yaml:"IdentityAgent,omitempty"`
 	IdentityFile   string `yaml:"IdentityFile,omitempty"`
 	IgnoreUnknown  string `yaml:"IgnoreUnknown,omitempty"`
 	Include        string `yaml:"Include,omitempty"`
@@ -82,10 +83,10 @@ type HostConfig struct {
 	KexAlgorithms                string `yaml:"KexAlgorithms,omitempty"`
 	KnownHostsCommand            string `yaml:"KnownHostsCommand,omitempty"`
 
-	LocalCommand    string `yaml:"LocalCommand,omitempty"`
-	LocalForward    string `yaml:"LocalForward,omitempty"`
-	LogLevel        string `yaml:"LogLevel,omitempty"`
-	LogLevelVerbose string `yaml:"LogLevelVerbose,omitempty"`
+	LocalCommand string `yaml:"LocalCommand,omitempty"`
+	LocalForward string `yaml:"LocalForward,omitempty"`
+	LogLevel     string `yaml:"LogLevel,omitempty"`
+	LogVerbose   string `yaml:"LogVerbose,omitempty"`
 
 	MACs string `yaml:"MACs,omitempty"`
 
@@ -109,7 +110,7 @@ type HostConfig struct {
 	RemoteCommand            string `yaml:"RemoteCommand,omitempty"`
 	RemoteForward            string `yaml:"RemoteForward,omitempty"`
 	RequestTTY               string `yaml:"RequestTTY,omitempty"`
-	RequireRSASize           string `yaml:"RequireRSASize,omitempty"`
+	RequiredRSASize          string `yaml:"RequiredRSASize,omitempty"`
 	RevokedHostKeys          string `yaml:"RevokedHostKeys,omitempty"`
 
 	SecurityKeyProvider   string `yaml:"SecurityKeyProvider,omitempty"`
@@ -118,6 +119,7 @@ type HostConfig struct {
 	ServerAliveInterval   string `yaml:"ServerAliveInterval,omitempty"`
 	SessionType           string `yaml:"SessionType,omitempty"`
 	SetEnv                string `yaml:"SetEnv,omitempty"`
+	StdinNull             string `yaml:"StdinNull,omitempty"`
 	StreamLocalBindMask   string `yaml:"StreamLocalBindMask,omitempty"`
 	StreamLocalBindUnlink string `yaml:"StreamLocalBindUnlink,omitempty"`
 	StrictHostKeyChecking string `yaml:"StrictHostKeyChecking,omitempty"`
@@ -138,5 +140,10 @@ type HostConfig struct {
 
 	YamlUserNotes string `yaml:"YamlUserNotes,omitempty"`
 	YamlUserHost  string `yaml:"YamlUserHost,omitempty"`
-	YamlTested    string `yaml` // this is a placeholder for the test
+	YamlTested    string `yaml:""` // this is a placeholder for the test
+
+	// keywords not listed above, kept verbatim with their original spelling
+	YamlUnknownKeys map[string]string `yaml:"YamlUnknownKeys,omitempty"`
+	// repeatable keywords given more than once, in order
+	YamlUserLists map[string][]string `yaml:"YamlUserLists,omitempty"`
 }
@@ -0,0 +1,119 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/diag"
+)
+
+// FindingsError is the error of a command that found problems in the config
+// and reports them in its output, e.g. "found 2 problems".
+type FindingsError struct {
+	Count int
+	// One and Many name a single problem and several of them.
+	One, Many string
+}
+
+func (e FindingsError) Error() string {
+	if e.Count == 1 {
+		return "found 1 " + e.One
+	}
+	return fmt.Sprintf("found %d %s", e.Count, e.Many)
+}
+
+// diagnostics gathers the problems of a command run with -format: parse
+// errors, the warnings of reading the config, and the findings.
+type diagnostics struct {
+	file   string
+	report diag.Report
+}
+
+// newDiagnostics returns the diagnostics of the config args name, and sends
+// the warnings of options to them.
+func newDiagnostics(args Cmd.Args, options *Options) *diagnostics {
+	d := &diagnostics{report: diag.Report{Rules: slices.Clone(diag.Rules)}}
+	if !args.Stdin && args.Src != "" {
+		d.file = sourceName(args)
+	}
+	options.Warn = func(message string) {
+		d.add(diag.Diagnostic{Rule: diag.RuleConfigWarning, Severity: diag.SeverityWarning, Message: message})
+	}
+	return d
+}
+
+// sourceName names the config args read in reports and diffs: stdin, or the
+// file -src points to.
+func sourceName(args Cmd.Args) string {
+	if args.Stdin || args.Src == "" {
+		return "stdin"
+	}
+	if file, err := Fn.SourceFile(args.Src); err == nil {
+		return file
+	}
+	return args.Src
+}
+
+func (d *diagnostics) add(diagnostic diag.Diagnostic) {
+	diagnostic.File = d.file
+	d.report.Diagnostics = append(d.report.Diagnostics, diagnostic)
+}
+
+// addError adds a config that can not be read.
+func (d *diagnostics) addError(err error) {
+	d.report.Diagnostics = append(d.report.Diagnostics, diag.FromError(err, d.file)...)
+}
+
+// render writes the diagnostics in format, with an error counting the
+// problems; the warnings of reading the config are not counted.
+func (d *diagnostics) render(format string) ([]byte, error) {
+	problems := 0
+	for _, diagnostic := range d.report.Diagnostics {
+		if diagnostic.Rule != diag.RuleConfigWarning {
+			problems++
+		}
+	}
+	if len(d.report.Diagnostics) == 0 && !strings.EqualFold(format, diag.FormatJSON) && !strings.EqualFold(format, diag.FormatSARIF) {
+		return []byte("No problems found"), nil
+	}
+	output, err := d.report.Render(format)
+	if err != nil {
+		return nil, err
+	}
+	if problems > 0 {
+		return output, FindingsError{Count: problems, One: "problem", Many: "problems"}
+	}
+	return output, nil
+}
+
+// diagnosticRules describes the built-in lint rules and those of a policy
+// file.
+func diagnosticRules(policyRules []PolicyRule) []diag.Rule {
+	rules := make([]diag.Rule, 0, len(LintRules)+len(policyRules))
+	for _, rule := range LintRules {
+		rules = append(rules, diag.Rule{ID: rule.ID, Name: rule.Name, Severity: rule.Severity, Summary: rule.Summary})
+	}
+	for _, rule := range policyRules {
+		rules = append(rules, diag.Rule{ID: rule.ID, Severity: rule.Severity, Summary: rule.Description})
+	}
+	return rules
+}
@@ -0,0 +1,136 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+	"github.com/soulteary/ssh-config/v2/pkg/diag"
+)
+
+func TestProcess_Format(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "config")
+	input := "Host web\n    Port abc\n    StrictHostKeyChecking no\n"
+	if err := os.WriteFile(src, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    Cmd.Args
+		input   string
+		want    []diag.Diagnostic
+		wantErr string
+	}{
+		{
+			name:  "validate",
+			args:  Cmd.Args{Command: Cmd.CommandValidate, Src: src},
+			input: input,
+			want: []diag.Diagnostic{
+				{File: src, Line: 2, Column: 10, Rule: diag.RuleInvalidValue, Severity: diag.SeverityError, Label: "Host web", Message: `Port "abc": expected an integer`},
+			},
+			wantErr: "found 1 problem",
+		},
+		{
+			name:  "lint",
+			args:  Cmd.Args{Command: Cmd.CommandLint, Src: src},
+			input: input,
+			want: []diag.Diagnostic{
+				{File: src, Line: 3, Column: 5, Rule: "SSH003", Severity: diag.SeverityError, Label: "Host web", Message: "StrictHostKeyChecking no connects to hosts whose key changed, use accept-new to only trust new hosts"},
+			},
+			wantErr: "found 1 problem",
+		},
+		{
+			name:  "parse error from stdin",
+			args:  Cmd.Args{Command: Cmd.CommandLint, Src: src, Stdin: true},
+			input: "Host \"web\n",
+			want: []diag.Diagnostic{
+				{Line: 1, Column: 6, Rule: diag.RuleParse, Severity: diag.SeverityError, Message: "unclosed quoted string"},
+			},
+			wantErr: "found 1 problem",
+		},
+		{
+			name:  "no problems",
+			args:  Cmd.Args{Command: Cmd.CommandLint, Src: src},
+			input: "Host web\n    HostName example.com\n",
+			want:  []diag.Diagnostic{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args.Format = diag.FormatJSON
+			result, err := Parser.Process("TEXT", tt.input, tt.args)
+			if gotErr := ""; err != nil {
+				gotErr = err.Error()
+				if gotErr != tt.wantErr {
+					t.Errorf("Process() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if tt.wantErr != "" {
+				t.Errorf("Process() error = nil, want %q", tt.wantErr)
+			}
+			var got []diag.Diagnostic
+			if err := json.Unmarshal(result, &got); err != nil {
+				t.Fatalf("Process() = %s: %v", result, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Process() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcess_FormatSARIF(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandLint, Format: diag.FormatSARIF, Stdin: true}
+	result, err := Parser.Process("TEXT", "Host *\n    ForwardAgent yes\n", args)
+	if err == nil {
+		t.Error("Process() expected an error for the finding")
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(result, &log); err != nil {
+		t.Fatalf("Process() = %s: %v", result, err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("Process() = %s", result)
+	}
+	run := log.Runs[0]
+	res := run.Results[0]
+	if res.RuleID != "SSH001" || res.Level != "error" || run.Tool.Driver.Rules[res.RuleIndex].ID != "SSH001" {
+		t.Errorf("result = %+v", res)
+	}
+}
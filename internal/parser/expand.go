@@ -0,0 +1,112 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+// HostContext returns the expansion context of a concrete host: the base
+// context completed with the host's HostName, Port, User and ProxyJump.
+func HostContext(hostConfig Define.HostConfig, base expand.Context) expand.Context {
+	context := base
+	context.OriginalHost = hostConfig.Name
+	context.Host = hostConfig.Name
+	if hostName := hostValue(hostConfig, "HostName"); hostName != "" {
+		context.Host, _ = expand.Expand("HostName", hostName, context)
+	}
+	context.Port = hostValue(hostConfig, "Port")
+	if context.Port == "" {
+		context.Port = "22"
+	}
+	context.RemoteUser = hostValue(hostConfig, "User")
+	if context.RemoteUser == "" {
+		context.RemoteUser = context.LocalUser
+	}
+	context.ProxyJump = hostValue(hostConfig, "ProxyJump")
+	context.HostKeyAlias = hostValue(hostConfig, "HostKeyAlias")
+	return context
+}
+
+// hostValue returns the first value of key, matched case-insensitively.
+func hostValue(hostConfig Define.HostConfig, key string) string {
+	if name, ok := findKey(hostConfig, key); ok {
+		return hostConfig.Values(name)[0]
+	}
+	return ""
+}
+
+// findKey returns the spelling key has in hostConfig, matched case-insensitively.
+func findKey(hostConfig Define.HostConfig, key string) (string, bool) {
+	for name := range hostConfig.Config {
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+	for name, values := range hostConfig.Lists {
+		if strings.EqualFold(name, key) && len(values) > 0 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ExpandHostConfigs expands the tokens and ${ENV} references of every
+// concrete host. Patterns and Match blocks have no single destination, they
+// are returned unchanged; values that can not be expanded are reported.
+func ExpandHostConfigs(hostConfigs []Define.HostConfig, options Options) []Define.HostConfig {
+	expanded := make([]Define.HostConfig, 0, len(hostConfigs))
+	for _, hostConfig := range hostConfigs {
+		if hostConfig.IsMatch() || hostConfig.IsInclude() || !Define.IsConcreteHost(hostConfig.Name) {
+			expanded = append(expanded, hostConfig)
+			continue
+		}
+
+		context := HostContext(hostConfig, options.ExpandContext)
+		result := hostConfig
+		result.Config = maps.Clone(hostConfig.Config)
+		result.Lists = maps.Clone(hostConfig.Lists)
+		for key := range hostConfig.Config {
+			result.Config[key] = expandValue(hostConfig.Name, key, hostConfig.Config[key], context, options)
+		}
+		for key, values := range hostConfig.Lists {
+			list := make([]string, 0, len(values))
+			for _, value := range values {
+				list = append(list, expandValue(hostConfig.Name, key, value, context, options))
+			}
+			result.Lists[key] = list
+		}
+		expanded = append(expanded, result)
+	}
+	return expanded
+}
+
+func expandValue(host, key, value string, context expand.Context, options Options) string {
+	if strings.EqualFold(key, "HostName") {
+		context.Host = host
+	}
+	expanded, problems := expand.Expand(key, value, context)
+	for _, problem := range problems {
+		options.warn(fmt.Sprintf("Host %s: %v", host, problem))
+	}
+	return expanded
+}
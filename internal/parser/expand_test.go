@@ -0,0 +1,80 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+func TestExpandHostConfigs(t *testing.T) {
+	input := `Host work
+    HostName %h.example.com
+    IdentityFile %d/.ssh/id_%r
+    IdentityFile ~/.ssh/%u
+    ProxyCommand ssh -W %h:%p %T bastion
+    User deploy
+
+Host *.corp
+    IdentityFile %d/.ssh/id_%h
+`
+	hostConfigs, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	options := Parser.Options{
+		ExpandContext: expand.Context{LocalUser: "alice", HomeDir: "/home/alice"},
+		Warn:          func(message string) { warnings = append(warnings, message) },
+	}
+	expanded := Parser.ExpandHostConfigs(hostConfigs, options)
+
+	work := findHost(expanded, "work")
+	if got := work.Config["HostName"]; got != "work.example.com" {
+		t.Errorf("HostName = %q", got)
+	}
+	if got := work.Config["ProxyCommand"]; got != "ssh -W work.example.com:22 %T bastion" {
+		t.Errorf("ProxyCommand = %q", got)
+	}
+	if got, want := work.Values("IdentityFile"), []string{"/home/alice/.ssh/id_deploy", "~/.ssh/alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IdentityFile = %v, want %v", got, want)
+	}
+	if got := findHost(expanded, "*.corp").Values("IdentityFile"); !reflect.DeepEqual(got, []string{"%d/.ssh/id_%h"}) {
+		t.Errorf("patterns should not be expanded, IdentityFile = %v", got)
+	}
+	if want := []string{`Host work: ProxyCommand: "%T" at offset 13: token not supported by ProxyCommand`}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	if got := findHost(hostConfigs, "work").Config["HostName"]; got != "%h.example.com" {
+		t.Errorf("ExpandHostConfigs() changed its input, HostName = %q", got)
+	}
+}
+
+func findHost(hostConfigs []Define.HostConfig, name string) Define.HostConfig {
+	for _, hostConfig := range hostConfigs {
+		if hostConfig.Name == name {
+			return hostConfig
+		}
+	}
+	return Define.HostConfig{}
+}
@@ -0,0 +1,197 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/cst"
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+)
+
+// FormatSSHConfig formats SSH config text the way the fmt command does,
+// keeping its lines, their order and their comments:
+//   - directives of a block are indented with cst.DefaultIndent, headers and
+//     the directives before the first block are not;
+//   - keywords get their ssh_config(5) spelling, unless options.KeepCase;
+//   - a single space separates the keyword, '=' included, the arguments and
+//     a trailing comment;
+//   - quotes are removed from arguments that do not need them;
+//   - runs of blank lines become one, a blank line separates the blocks, and
+//     there is none at the start or the end of the file or of a block.
+//
+// Comment lines are indented like the lines they annotate. A comment at the
+// start of a line after the last directive of a block, separated from it by
+// a blank line, annotates what follows and stays at the start of the line.
+func FormatSSHConfig(input string, options Options) (string, error) {
+	file, err := parseFile(input)
+	if err != nil {
+		return "", err
+	}
+	f := formatter{keepCase: options.KeepCase}
+	lines := f.lines(file.Preamble, "")
+	for _, block := range file.Blocks {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		for _, comment := range block.Comments {
+			lines = append(lines, f.line(comment, ""))
+		}
+		lines = append(lines, f.line(block.Header, ""))
+		lines = append(lines, f.lines(block.Lines, cst.DefaultIndent)...)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	newline := "\n"
+	if index := strings.Index(input, "\n"); index > 0 && input[index-1] == '\r' {
+		newline = "\r\n"
+	}
+	return strings.Join(lines, newline) + newline, nil
+}
+
+type formatter struct {
+	keepCase bool
+}
+
+// lines formats the lines of the preamble or of a block body.
+func (f formatter) lines(lines []*cst.Line, indent string) []string {
+	last := -1
+	for index, line := range lines {
+		if !line.IsBlank() && !line.IsComment() {
+			last = index
+		}
+	}
+	var formatted []string
+	blank, detached := false, false
+	for index, line := range lines {
+		if line.IsBlank() {
+			blank = len(formatted) > 0
+			detached = detached || index > last
+			continue
+		}
+		if blank {
+			formatted = append(formatted, "")
+			blank = false
+		}
+		lineIndent := indent
+		if detached && line.Indent() == "" {
+			lineIndent = ""
+		}
+		formatted = append(formatted, f.line(line, lineIndent))
+	}
+	return formatted
+}
+
+// line formats a directive, a header or a comment line.
+func (f formatter) line(line *cst.Line, indent string) string {
+	first := line.Tokens[0]
+	if first.Kind == lexer.TokenComment {
+		return indent + rawComment(line, first)
+	}
+	if line.Keyword() == "" {
+		return indent + strings.TrimSpace(line.Raw)
+	}
+
+	var b strings.Builder
+	b.WriteString(indent)
+	b.WriteString(f.keyword(first.Value))
+	previous := first
+	for _, token := range line.Tokens[1:] {
+		switch token.Kind {
+		case lexer.TokenValue, lexer.TokenQuoted:
+			// ssh reads arguments written without space between them as one
+			if token.Offset != previous.End || previous.Kind == lexer.TokenEquals || previous == first {
+				b.WriteByte(' ')
+			}
+			if token.Kind == lexer.TokenQuoted {
+				b.WriteString(formatQuoted(token.Value, line.Raw[token.Offset:token.End]))
+			} else {
+				b.WriteString(token.Value)
+			}
+		case lexer.TokenComment:
+			b.WriteString(" " + rawComment(line, token))
+		}
+		previous = token
+	}
+	return b.String()
+}
+
+// keyword returns the spelling of keyword in formatted text.
+func (f formatter) keyword(keyword string) string {
+	if f.keepCase {
+		return keyword
+	}
+	switch strings.ToLower(keyword) {
+	case "host":
+		return "Host"
+	case "match":
+		return "Match"
+	}
+	if canonical, ok := Define.CanonicalKeyword(keyword); ok {
+		return canonical
+	}
+	if removed, ok := Define.RemovedKeyword(keyword); ok {
+		return removed
+	}
+	for _, legacy := range Define.LegacyKeywords {
+		if strings.EqualFold(legacy, keyword) {
+			return legacy
+		}
+	}
+	return keyword
+}
+
+// rawComment returns a comment token as written, '#' included.
+func rawComment(line *cst.Line, token lexer.Token) string {
+	return strings.TrimRight(line.Raw[token.Offset:token.End], " \t\r")
+}
+
+// formatQuoted writes a quoted argument without its quotes when it does not
+// need them, and as written otherwise.
+func formatQuoted(value string, raw string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n#\"'\\") {
+		return raw
+	}
+	return value
+}
+
+// processFormat runs the fmt command: the formatted config, or with -check
+// the diff to it and an error when the config is not formatted.
+func processFormat(fileType string, userInput string, args Cmd.Args, options Options) ([]byte, error) {
+	if fileType != "TEXT" {
+		return nil, fmt.Errorf("fmt formats SSH config text, convert %s input with -to-ssh first", fileType)
+	}
+	formatted, err := FormatSSHConfig(userInput, options)
+	if err != nil {
+		return nil, err
+	}
+	if !args.Check {
+		return []byte(formatted), nil
+	}
+	name := sourceName(args)
+	if formatted == userInput {
+		return []byte(name + " is formatted"), nil
+	}
+	err = fmt.Errorf("%s is not formatted", name)
+	return []byte(Fn.UnifiedDiff(name, name, userInput, formatted) + err.Error()), err
+}
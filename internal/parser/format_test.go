@@ -0,0 +1,148 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+const unformattedConfig = `
+
+# global
+include   config.d/*
+
+
+# work
+host   work "work.alias"
+	hostname = "work.example.com"   # primary
+  user "deploy user"
+
+
+
+	identityfile  "~/.ssh/id_work"
+	pubkeyacceptedkeytypes +ssh-rsa
+	setenv FOO="a b" BAR=""
+# office only
+MATCH host *.corp exec "test -f /tmp/vpn"
+ProxyJump bastion
+  # about the bastion
+
+# about the defaults
+Host *
+    ServerAliveInterval 60
+
+`
+
+const formattedConfig = `# global
+Include config.d/*
+
+# work
+Host work work.alias
+    HostName work.example.com # primary
+    User "deploy user"
+
+    IdentityFile ~/.ssh/id_work
+    PubkeyAcceptedKeyTypes +ssh-rsa
+    SetEnv FOO="a b" BAR=""
+
+# office only
+Match host *.corp exec "test -f /tmp/vpn"
+    ProxyJump bastion
+    # about the bastion
+
+# about the defaults
+Host *
+    ServerAliveInterval 60
+`
+
+func TestFormatSSHConfig(t *testing.T) {
+	got, err := Parser.FormatSSHConfig(unformattedConfig, Parser.Options{})
+	if err != nil {
+		t.Fatalf("FormatSSHConfig() error = %v", err)
+	}
+	if got != formattedConfig {
+		t.Errorf("FormatSSHConfig() =\n%s\nwant\n%s", got, formattedConfig)
+	}
+	if again, _ := Parser.FormatSSHConfig(got, Parser.Options{}); again != got {
+		t.Errorf("FormatSSHConfig() of the formatted config =\n%s", again)
+	}
+}
+
+func TestFormatSSHConfig_Lines(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Parser.Options
+		want    string
+	}{
+		{"empty", "\n\n", Parser.Options{}, ""},
+		{"keep case", "host a\n  hostname b\n", Parser.Options{KeepCase: true}, "host a\n    hostname b\n"},
+		{"unknown keyword", "Host a\n  MyOption Yes\n", Parser.Options{}, "Host a\n    MyOption Yes\n"},
+		{"removed keyword", "Host a\n  useroaming no\n", Parser.Options{}, "Host a\n    UseRoaming no\n"},
+		{"crlf", "Host a\r\n\r\n\r\n\tUser b\r\n", Parser.Options{}, "Host a\r\n    User b\r\n"},
+		{"quotes kept", "Host a\n  ProxyCommand \"nc %h %p\" '' \"x#y\"\n", Parser.Options{}, "Host a\n    ProxyCommand \"nc %h %p\" '' \"x#y\"\n"},
+		{"detached comment", "Host a\n  User b\n\n# next\n\n  # inside\nHost c\n", Parser.Options{}, "Host a\n    User b\n\n# next\n\n# inside\nHost c\n"},
+		{"no final newline", "Host a\n User b", Parser.Options{}, "Host a\n    User b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parser.FormatSSHConfig(tt.input, tt.options)
+			if err != nil || got != tt.want {
+				t.Errorf("FormatSSHConfig() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcess_Fmt(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandFmt, Src: "config"}
+	output, err := Parser.Process("TEXT", unformattedConfig, args)
+	if err != nil || string(output) != formattedConfig {
+		t.Errorf("Process(fmt) = %q, %v", output, err)
+	}
+
+	args.Check = true
+	output, err = Parser.Process("TEXT", unformattedConfig, args)
+	if err == nil || err.Error() != "config is not formatted" {
+		t.Errorf("Process(fmt -check) error = %v", err)
+	}
+	for _, line := range []string{"--- config\n+++ config\n", "+Host work work.alias\n", "\nconfig is not formatted"} {
+		if !strings.Contains(string(output), line) {
+			t.Errorf("Process(fmt -check) does not hold %q:\n%s", line, output)
+		}
+	}
+	if output, err = Parser.Process("TEXT", formattedConfig, args); err != nil || string(output) != "config is formatted" {
+		t.Errorf("Process(fmt -check) of the formatted config = %q, %v", output, err)
+	}
+
+	input := "Host a\n    User \"bob\n    Port abc\n    ProxyCommand \"nc %h %p\n"
+	for _, check := range []bool{false, true} {
+		args.Check = check
+		output, err = Parser.Process("TEXT", input, args)
+		if err == nil || err.Error() != "line 2 column 10: unclosed quoted string\nline 4 column 18: unclosed quoted string" || len(output) != 0 {
+			t.Errorf("Process(fmt, check %v) = %q, %v, want the unclosed quotes", check, output, err)
+		}
+	}
+
+	if _, err = Parser.Process("YAML", "global:\n  User: root\n", args); err == nil {
+		t.Error("Process(fmt) expected an error for YAML input")
+	}
+}
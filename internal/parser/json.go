@@ -17,6 +17,8 @@
 package parser
 
 import (
+	"fmt"
+
 	Define "github.com/soulteary/ssh-config/v2/internal/define"
 	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
 )
@@ -27,10 +29,23 @@ func ConvertToJSON(input []Define.HostConfig) []byte {
 		var config Define.HostConfigForJSON
 		config.Name = hostConfig.Name
 		config.Notes = hostConfig.Notes
+		if hostConfig.Name != "" && !Define.IsConcreteHost(hostConfig.Name) {
+			config.Patterns = hostConfig.HostPatterns()
+		}
+		config.Match = hostConfig.Match
+		config.Include = hostConfig.Include
+		if hostConfig.IsInclude() {
+			hostConfigs = append(hostConfigs, config)
+			continue
+		}
 		config.Data = make(Define.HostConfigDataForJSON)
 
-		orderMaps := Fn.GetOrderMaps(hostConfig.Config)
+		orderMaps := Fn.GetOrderConfig(hostConfig.Config, hostConfig.Lists)
 		for _, field := range orderMaps.Keys {
+			if values, ok := orderMaps.Lists[field]; ok {
+				config.Data[field] = values
+				continue
+			}
 			config.Data[field] = orderMaps.Data[field]
 		}
 		hostConfigs = append(hostConfigs, config)
@@ -39,6 +54,10 @@ func ConvertToJSON(input []Define.HostConfig) []byte {
 }
 
 func GroupJSONConfig(input string) []Define.HostConfig {
+	return GroupJSONConfigWithOptions(input, Options{})
+}
+
+func GroupJSONConfigWithOptions(input string, options Options) []Define.HostConfig {
 	jsonConfig := Fn.GetJSONData(input)
 
 	var hostConfigs []Define.HostConfig
@@ -46,13 +65,37 @@ func GroupJSONConfig(input string) []Define.HostConfig {
 	for _, hostConfig := range jsonConfig {
 		var config Define.HostConfig
 		config.Name = hostConfig.Name
+		if len(hostConfig.Patterns) > 0 {
+			config.Name = Define.FormatHostPatterns(hostConfig.Patterns)
+		}
 		config.Notes = hostConfig.Notes
+		config.Match = hostConfig.Match
+		config.Include = hostConfig.Include
+		if len(config.Include) > 0 {
+			hostConfigs = append(hostConfigs, config)
+			continue
+		}
 		config.Config = make(map[string]string)
 
 		for key, value := range hostConfig.Data {
-			config.Config[key] = value
+			switch v := value.(type) {
+			case []any:
+				values := make([]string, 0, len(v))
+				for _, item := range v {
+					values = append(values, fmt.Sprint(item))
+				}
+				config.SetValues(key, values)
+			case nil:
+				config.Config[key] = ""
+			default:
+				config.Config[key] = fmt.Sprint(v)
+			}
 		}
-		hostConfigs = append(hostConfigs, config)
+		label := "Host " + config.Name
+		if config.IsMatch() {
+			label = "Match " + FormatMatchConditions(config.Match)
+		}
+		hostConfigs = append(hostConfigs, normalizeHostConfig(config, nil, label, options))
 	}
 
 	return hostConfigs
@@ -165,3 +165,76 @@ func TestGroupJSONConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONConfig_MultiValueDirectives(t *testing.T) {
+	input := []Define.HostConfig{
+		{
+			Name:   "tunnel",
+			Config: map[string]string{"HostName": "tunnel.example.com"},
+			Lists:  map[string][]string{"RemoteForward": {"9000 localhost:9000", "9001 localhost:9001"}},
+		},
+	}
+	result := Parser.ConvertToJSON(input)
+	expected := `[{"Name":"tunnel","Data":{"HostName":"tunnel.example.com","RemoteForward":["9000 localhost:9000","9001 localhost:9001"]}}]`
+	if string(result) != expected {
+		t.Errorf("ConvertToJSON() = %s, want %s", result, expected)
+	}
+
+	got := Parser.GroupJSONConfig(string(result))
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Config, input[0].Config) || !reflect.DeepEqual(got[0].Lists, input[0].Lists) {
+		t.Errorf("GroupJSONConfig() = %v, want %v", got, input)
+	}
+}
+
+func TestJSONConfig_Match(t *testing.T) {
+	input := []Define.HostConfig{
+		{
+			Match:  []Define.MatchCondition{{Criterion: "localuser", Negate: true, Argument: "root"}},
+			Config: map[string]string{"User": "admin"},
+		},
+	}
+	result := Parser.ConvertToJSON(input)
+	expected := `[{"Match":[{"Criterion":"localuser","Negate":true,"Argument":"root"}],"Data":{"User":"admin"}}]`
+	if string(result) != expected {
+		t.Errorf("ConvertToJSON() = %s, want %s", result, expected)
+	}
+	if got := Parser.GroupJSONConfig(string(result)); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupJSONConfig() = %+v, want %+v", got, input)
+	}
+}
+
+func TestJSONConfig_Include(t *testing.T) {
+	input := []Define.HostConfig{
+		{Include: []string{"config.d/*"}},
+		{Name: "foo", Config: map[string]string{"HostName": "foo.com"}},
+	}
+	result := Parser.ConvertToJSON(input)
+	expected := `[{"Include":["config.d/*"]},{"Name":"foo","Data":{"HostName":"foo.com"}}]`
+	if string(result) != expected {
+		t.Errorf("ConvertToJSON() = %s, want %s", result, expected)
+	}
+	if got := Parser.GroupJSONConfig(string(result)); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupJSONConfig() = %+v, want %+v", got, input)
+	}
+}
+
+func TestJSONConfig_HostPatterns(t *testing.T) {
+	input := []Define.HostConfig{
+		{Name: "web1 web2 !web3 *.dev", Config: map[string]string{"User": "deploy"}},
+		{Name: "foo", Config: map[string]string{"HostName": "foo.com"}},
+	}
+	result := Parser.ConvertToJSON(input)
+	expected := `[{"Name":"web1 web2 !web3 *.dev","Patterns":[{"Pattern":"web1"},{"Pattern":"web2"},{"Pattern":"web3","Negate":true},{"Pattern":"*.dev","Wildcard":true}],"Data":{"User":"deploy"}},{"Name":"foo","Data":{"HostName":"foo.com"}}]`
+	if string(result) != expected {
+		t.Errorf("ConvertToJSON() = %s, want %s", result, expected)
+	}
+	if got := Parser.GroupJSONConfig(string(result)); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupJSONConfig() = %+v, want %+v", got, input)
+	}
+
+	// Patterns win over Name, so tools only have to rewrite the list
+	edited := `[{"Name":"old","Patterns":[{"Pattern":"a"},{"Pattern":"b*","Wildcard":true}],"Data":{"User":"x"}}]`
+	if got := Parser.GroupJSONConfig(edited); len(got) != 1 || got[0].Name != "a b*" {
+		t.Errorf("GroupJSONConfig() = %+v, want Name \"a b*\"", got)
+	}
+}
@@ -0,0 +1,169 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+)
+
+// normalizeKeywords renames the keys of config and lists to their canonical
+// spelling. With options.KeepCase a key keeps the spelling typed holds for
+// it, keyed by the lower-cased keyword, or its own. An alias becomes its
+// current keyword and a removed keyword is kept, both reported under label.
+// Keys naming the same option are merged under the first spelling: a
+// repeatable keyword keeps every value, any other keeps the value of the
+// expected spelling and reports the dropped one.
+func normalizeKeywords(config map[string]string, lists map[string][]string, typed map[string]string, label string, options Options) (map[string]string, map[string][]string) {
+	spelling := func(key string) string {
+		if keyword, ok := Define.ModernKeyword(key); ok {
+			return keyword
+		}
+		if options.KeepCase {
+			if original, ok := typed[strings.ToLower(key)]; ok {
+				return original
+			}
+			return key
+		}
+		if keyword, ok := Define.CanonicalKeyword(key); ok {
+			return keyword
+		}
+		if keyword, ok := Define.RemovedKeyword(key); ok {
+			return keyword
+		}
+		return key
+	}
+
+	source := Define.HostConfig{Config: config, Lists: lists}
+	keys := make([]string, 0, len(config)+len(lists))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	for key := range lists {
+		if _, ok := config[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	// the expected spelling first, so the result does not depend on map order
+	slices.SortFunc(keys, func(a, b string) int {
+		if aKept, bKept := a == spelling(a), b == spelling(b); aKept != bKept {
+			if aKept {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	normalized := Define.HostConfig{}
+	if config != nil {
+		normalized.Config = make(map[string]string, len(config))
+	}
+	// the key kept for each lower-cased keyword and the name it is written as
+	kept := make(map[string]string, len(keys))
+	names := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name := spelling(key)
+		values := source.Values(key)
+		if keyword, ok := Define.ModernKeyword(key); ok {
+			options.warn(fmt.Sprintf("%s: %s is deprecated, it is read as %s", label, key, keyword))
+		} else if _, ok := Define.RemovedKeyword(key); ok {
+			options.warn(fmt.Sprintf("%s: %s is no longer supported by OpenSSH and has no effect", label, key))
+		}
+		id := strings.ToLower(name)
+		previous, seen := kept[id]
+		if seen {
+			name = names[id]
+		}
+		switch {
+		case !seen:
+			kept[id] = key
+			names[id] = name
+			normalized.SetValues(name, values)
+		case Define.IsMultiValueKeyword(name):
+			normalized.SetValues(name, append(normalized.Values(name), values...))
+		default:
+			options.warn(fmt.Sprintf("%s: %s %q is ignored, %s is the same keyword and sets %q",
+				label, key, strings.Join(values, " "), previous, strings.Join(normalized.Values(name), " ")))
+		}
+	}
+	if normalized.Config == nil && len(normalized.Lists) == 0 {
+		return config, nil
+	}
+	return normalized.Config, normalized.Lists
+}
+
+// normalizeHostConfig applies normalizeKeywords to the directives of hostConfig.
+func normalizeHostConfig(hostConfig Define.HostConfig, typed map[string]string, label string, options Options) Define.HostConfig {
+	hostConfig.Config, hostConfig.Lists = normalizeKeywords(hostConfig.Config, hostConfig.Lists, typed, label, options)
+	return hostConfig
+}
+
+// typedSpellings returns how the keywords of group were first written.
+func typedSpellings(group SSHHostConfigGroup) map[string]string {
+	typed := make(map[string]string, len(group.Config)+len(group.Lists))
+	for key := range group.Config {
+		typed[strings.ToLower(key)] = key
+	}
+	for key := range group.Lists {
+		typed[strings.ToLower(key)] = key
+	}
+	return typed
+}
+
+// normalizeYAML normalizes the keywords of every section of a YAML document,
+// before hosts inherit from Common and default.
+func normalizeYAML(yamlConfig *Define.YAMLOutput, options Options) {
+	yamlConfig.Global, yamlConfig.GlobalLists = normalizeKeywords(yamlConfig.Global, yamlConfig.GlobalLists, nil, "global", options)
+	yamlConfig.Default, yamlConfig.DefaultLists = normalizeKeywords(yamlConfig.Default, yamlConfig.DefaultLists, nil, "default", options)
+	for index, match := range yamlConfig.Match {
+		yamlConfig.Match[index] = normalizeHostConfig(match, nil, "match", options)
+	}
+	for name, group := range yamlConfig.Groups {
+		group.Common, group.CommonLists = normalizeKeywords(group.Common, group.CommonLists, nil, name+" Common", options)
+		for host, hostConfig := range group.Hosts {
+			group.Hosts[host] = normalizeHostConfig(hostConfig, nil, fmt.Sprintf("Host %s in %s", host, name), options)
+		}
+		for index, match := range group.Match {
+			group.Match[index] = normalizeHostConfig(match, nil, name+" Match", options)
+		}
+		yamlConfig.Groups[name] = group
+	}
+}
+
+// legacyHostConfigs gives renamed keywords the name older OpenSSH releases
+// know them by, for ConvertToSSHWithOptions.
+func legacyHostConfigs(hostConfigs []Define.HostConfig) []Define.HostConfig {
+	legacy := make([]Define.HostConfig, 0, len(hostConfigs))
+	for _, hostConfig := range hostConfigs {
+		renamed := hostConfig
+		renamed.Config = maps.Clone(hostConfig.Config)
+		renamed.Lists = maps.Clone(hostConfig.Lists)
+		for key := range hostConfig.Config {
+			if old, ok := Define.LegacyKeywords[key]; ok {
+				renamed.SetValues(old, renamed.Values(key))
+				renamed.SetValues(key, nil)
+			}
+		}
+		legacy = append(legacy, renamed)
+	}
+	return legacy
+}
@@ -0,0 +1,192 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+func TestCanonicalKeyword(t *testing.T) {
+	for key, want := range map[string]string{"hostname": "HostName", "IDENTITYAGENT": "IdentityAgent", "macs": "MACs"} {
+		if got, ok := Define.CanonicalKeyword(key); !ok || got != want {
+			t.Errorf("CanonicalKeyword(%q) = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+	if _, ok := Define.CanonicalKeyword("UseKeychain"); ok {
+		t.Error("CanonicalKeyword(UseKeychain) = true, want false")
+	}
+}
+
+func TestGroupYAMLConfig_CanonicalKeywords(t *testing.T) {
+	input := `
+default:
+  SERVERALIVEINTERVAL: "60"
+Group work:
+  Common:
+    user: deploy
+    identityfile: ~/.ssh/common
+  Hosts:
+    work:
+      config:
+        hostname: work.example.com
+        HostName: kept.example.com
+        User: admin
+        IdentityFile: ~/.ssh/work
+        UseKeychain: "yes"
+`
+	var warnings []string
+	options := Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }}
+	got := Parser.GroupYAMLConfigWithOptions(input, options)
+
+	want := []Define.HostConfig{{
+		Name:  "work",
+		Extra: Define.HostExtraConfig{},
+		Config: map[string]string{
+			"HostName":            "kept.example.com",
+			"User":                "admin",
+			"IdentityFile":        "~/.ssh/work",
+			"ServerAliveInterval": "60",
+			"UseKeychain":         "yes",
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupYAMLConfigWithOptions() = %+v, want %+v", got, want)
+	}
+	wantWarnings := []string{`Host work in Group work: hostname "work.example.com" is ignored, HostName is the same keyword and sets "kept.example.com"`}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("warnings = %q, want %q", warnings, wantWarnings)
+	}
+
+	// the original spelling is kept on request, one per keyword
+	got = Parser.GroupYAMLConfigWithOptions(input, Parser.Options{KeepCase: true})
+	wantKept := map[string]string{
+		"HostName":            "kept.example.com",
+		"User":                "admin",
+		"IdentityFile":        "~/.ssh/work",
+		"SERVERALIVEINTERVAL": "60",
+		"UseKeychain":         "yes",
+	}
+	if !reflect.DeepEqual(got[0].Config, wantKept) {
+		t.Errorf("GroupYAMLConfigWithOptions(KeepCase) = %+v, want %+v", got[0].Config, wantKept)
+	}
+	output := string(Parser.ConvertToSSHWithOptions(got, Parser.Options{KeepCase: true}))
+	if strings.Count(strings.ToLower(output), "hostname") != 1 {
+		t.Errorf("ConvertToSSHWithOptions(KeepCase) = %q, want a single HostName", output)
+	}
+}
+
+func TestGroupJSONConfig_CanonicalKeywords(t *testing.T) {
+	input := `[{"Name":"work","Data":{"identityfile":["~/.ssh/a","~/.ssh/b"],"IdentityFile":"~/.ssh/c","PORT":2222}}]`
+	got := Parser.GroupJSONConfig(input)
+	want := []Define.HostConfig{{
+		Name:   "work",
+		Config: map[string]string{"Port": "2222"},
+		Lists:  map[string][]string{"IdentityFile": {"~/.ssh/c", "~/.ssh/a", "~/.ssh/b"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupJSONConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGroupSSHConfig_KeywordCase(t *testing.T) {
+	input := "Host work\n    hostname work.example.com\n    IDENTITYAGENT none\n    identityfile ~/.ssh/a\n    IdentityFile ~/.ssh/b\n"
+
+	got, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Define.HostConfig{
+		Name:   "work",
+		Config: map[string]string{"HostName": "work.example.com", "IdentityAgent": "none"},
+		Lists:  map[string][]string{"IdentityFile": {"~/.ssh/a", "~/.ssh/b"}},
+	}); !reflect.DeepEqual(got[0], want) {
+		t.Errorf("GroupSSHConfig() = %+v, want %+v", got[0], want)
+	}
+
+	output, err := Parser.Process("TEXT", input, Cmd.Args{ToSSH: true, KeepInclude: true, KeepCase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"    hostname work.example.com", "    IDENTITYAGENT none", "    identityfile ~/.ssh/b"} {
+		if !strings.Contains(string(output), line) {
+			t.Errorf("Process(KeepCase) = %q, want the line %q", output, line)
+		}
+	}
+}
+
+func TestGroupSSHConfig_DeprecatedKeywords(t *testing.T) {
+	input := `Host legacy
+    PubkeyAcceptedKeyTypes +ssh-rsa
+    pubkeyacceptedalgorithms +ssh-dss
+    ChallengeResponseAuthentication no
+    UseRoaming no
+    Protocol 2
+`
+	var warnings []string
+	got, err := Parser.GroupSSHConfigWithOptions(input, Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"PubkeyAcceptedAlgorithms":     "+ssh-rsa",
+		"KbdInteractiveAuthentication": "no",
+		"UseRoaming":                   "no",
+		"Protocol":                     "2",
+	}
+	if !reflect.DeepEqual(got[0].Config, want) {
+		t.Errorf("GroupSSHConfigWithOptions() = %v, want %v", got[0].Config, want)
+	}
+	wantWarnings := []string{
+		`Host legacy: pubkeyacceptedalgorithms "+ssh-dss" at line 3 is ignored, ssh uses the first value "+ssh-rsa"`,
+		"Host legacy: Protocol is no longer supported by OpenSSH and has no effect",
+		"Host legacy: UseRoaming is no longer supported by OpenSSH and has no effect",
+		"Host legacy: ChallengeResponseAuthentication is deprecated, it is read as KbdInteractiveAuthentication",
+		"Host legacy: PubkeyAcceptedKeyTypes is deprecated, it is read as PubkeyAcceptedAlgorithms",
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("warnings = %q, want %q", warnings, wantWarnings)
+	}
+}
+
+func TestConvertToSSH_LegacyKeywords(t *testing.T) {
+	hostConfigs := Parser.GroupYAMLConfig(`
+Group legacy:
+  Hosts:
+    legacy:
+      config:
+        pubkeyacceptedkeytypes: +ssh-rsa
+        KbdInteractiveAuthentication: "no"
+`)
+	modern := "Host legacy\n    KbdInteractiveAuthentication no\n    PubkeyAcceptedAlgorithms +ssh-rsa\n\n"
+	if got := string(Parser.ConvertToSSH(hostConfigs)); got != modern {
+		t.Errorf("ConvertToSSH() = %q, want %q", got, modern)
+	}
+
+	legacy := "Host legacy\n    ChallengeResponseAuthentication no\n    PubkeyAcceptedKeyTypes +ssh-rsa\n\n"
+	if got := string(Parser.ConvertToSSHWithOptions(hostConfigs, Parser.Options{LegacyKeywords: true})); got != legacy {
+		t.Errorf("ConvertToSSHWithOptions(LegacyKeywords) = %q, want %q", got, legacy)
+	}
+	if _, ok := hostConfigs[0].Config["PubkeyAcceptedAlgorithms"]; !ok {
+		t.Error("ConvertToSSHWithOptions() changed its input")
+	}
+}
@@ -0,0 +1,660 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/cst"
+	"github.com/soulteary/ssh-config/v2/pkg/diag"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+	"github.com/soulteary/ssh-config/v2/pkg/validate"
+)
+
+// LintSeverity is how serious a finding is.
+type LintSeverity = diag.Severity
+
+const (
+	SeverityError   = diag.SeverityError
+	SeverityWarning = diag.SeverityWarning
+	SeverityInfo    = diag.SeverityInfo
+)
+
+// LintRule is a check run over every block of a config.
+type LintRule struct {
+	// ID is stable and short, e.g. SSH003; Name says what it finds.
+	ID       string
+	Name     string
+	Severity LintSeverity
+	Summary  string
+	// check returns the findings of one block, blocks are all of them.
+	check func(rule *LintRule, block LintBlock, blocks []LintBlock, options LintOptions) []LintFinding
+}
+
+// LintOptions are the settings rules depend on.
+type LintOptions struct {
+	// Context holds the local values ControlPath is expanded with.
+	Context expand.Context
+	// Version is the OpenSSH release whose default algorithms the
+	// '+', '-' and '^' modifiers apply to.
+	Version validate.Version
+	// Policy is the algorithm policy, validate.ModernPolicy by default.
+	Policy validate.Policy
+	// Rules are the rules of a policy file, run after the built-in ones.
+	Rules []PolicyRule
+}
+
+// LintFinding is a setting a rule flags.
+type LintFinding struct {
+	Rule     string
+	Severity LintSeverity
+	// Label names the block, e.g. "Host *", empty before the first block.
+	Label string
+	Key   string
+	// Line and Column locate the directive, YAML only has a line and JSON
+	// neither.
+	Line    int
+	Column  int
+	Message string
+
+	// fix is the change lint -fix makes, nil when it has none.
+	fix *lintFix
+}
+
+// Fixable reports whether lint -fix can fix the finding.
+func (f LintFinding) Fixable() bool {
+	return f.fix != nil
+}
+
+func (f LintFinding) String() string {
+	name := ""
+	if rule := findLintRule(f.Rule); rule != nil {
+		name = rule.Name
+	}
+	return f.Diagnostic("").Format(name)
+}
+
+// Diagnostic returns the finding as a diagnostic of file.
+func (f LintFinding) Diagnostic(file string) diag.Diagnostic {
+	return diag.Diagnostic{
+		File:     file,
+		Line:     f.Line,
+		Column:   f.Column,
+		Rule:     f.Rule,
+		Severity: f.Severity,
+		Label:    f.Label,
+		Message:  f.Message,
+	}
+}
+
+// LintBlock is a block as the rules see it: its directives with canonical
+// keywords, the first value of each, every value of repeatable ones.
+type LintBlock struct {
+	Label string
+	Host  Define.HostConfig
+	// Group is the YAML group of the host, empty for other formats.
+	Group string
+	// AllHosts is set for directives every host gets: those before the
+	// first block, Host * and Match all.
+	AllHosts bool
+	Line     int
+	// positions holds where each value of a keyword is written.
+	positions map[string][]lintPosition
+	// ignored are the rules suppressed for the whole block.
+	ignored map[string]bool
+	// directives are the block's own directives as they are written, for
+	// the rules about keyword spelling and repetition.
+	directives []lintDirective
+	// source is where lint -fix changes the block.
+	source lintSource
+}
+
+type lintPosition struct {
+	line, column int
+}
+
+// lintDirective is a directive with its keyword as written.
+type lintDirective struct {
+	key string
+	lintPosition
+	// node is the line of SSH config text, nil for other formats.
+	node *cst.Line
+}
+
+// lintSource locates a block in the document it comes from: a block of SSH
+// config text, the mapping of a YAML document holding its values, or the
+// index of a JSON array.
+type lintSource struct {
+	node  *cst.Block
+	path  []string
+	index int
+	// ordered is set when the document keeps the blocks in the order ssh
+	// reads them.
+	ordered bool
+}
+
+// finding returns a finding of rule on the first value of key.
+func (b LintBlock) finding(rule *LintRule, key string, format string, args ...any) LintFinding {
+	finding := LintFinding{
+		Rule:     rule.ID,
+		Severity: rule.Severity,
+		Label:    b.Label,
+		Key:      key,
+		Line:     b.Line,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if positions := b.positions[key]; len(positions) > 0 {
+		finding.Line, finding.Column = positions[0].line, positions[0].column
+	}
+	return finding
+}
+
+// value returns the first value of key, "" when it is not set.
+func (b LintBlock) value(key string) string {
+	if values := b.Host.Values(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// LintIgnoreDirective starts a comment suppressing rules, by ID or name:
+//
+//	# lint:ignore SSH001,SSH007 the bastion needs the agent
+//
+// At the end of a directive line, or on the line right above it, the
+// comment covers that directive; above a Host or Match line it covers the
+// whole block. LintFileIgnoreDirective covers the whole file, wherever it is.
+const (
+	LintIgnoreDirective     = "lint:ignore"
+	LintFileIgnoreDirective = "lint:file-ignore"
+)
+
+// parseLintIgnore returns the rules a comment suppresses and whether it
+// covers the file.
+func parseLintIgnore(comment string) (rules []string, file bool) {
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(comment), "#"))
+	directive, rest, _ := strings.Cut(text, " ")
+	switch directive {
+	case LintIgnoreDirective:
+	case LintFileIgnoreDirective:
+		file = true
+	default:
+		return nil, false
+	}
+	list, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	for _, rule := range strings.Split(list, ",") {
+		if rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, file
+}
+
+// lintIgnores are the suppressions of a config.
+type lintIgnores struct {
+	file  map[string]bool
+	lines map[int]map[string]bool
+}
+
+func (i *lintIgnores) add(set *map[string]bool, rules []string) {
+	if len(rules) == 0 {
+		return
+	}
+	if *set == nil {
+		*set = make(map[string]bool)
+	}
+	for _, rule := range rules {
+		(*set)[rule] = true
+	}
+}
+
+func (i *lintIgnores) addLine(line int, rules []string) {
+	if i.lines == nil {
+		i.lines = make(map[int]map[string]bool)
+	}
+	set := i.lines[line]
+	i.add(&set, rules)
+	i.lines[line] = set
+}
+
+// suppressed reports whether finding is covered by a comment.
+func (i lintIgnores) suppressed(finding LintFinding, block LintBlock) bool {
+	rule := findLintRule(finding.Rule)
+	covers := func(set map[string]bool) bool {
+		return set[finding.Rule] || rule != nil && set[rule.Name]
+	}
+	return covers(i.file) || covers(block.ignored) || covers(i.lines[finding.Line])
+}
+
+// lintBlocksFromSSH reads SSH config text into blocks, keeping the position
+// of every directive and the suppression comments.
+func lintBlocksFromSSH(input string) ([]LintBlock, lintIgnores, error) {
+	file, err := parseFile(input)
+	if err != nil {
+		return nil, lintIgnores{}, err
+	}
+	blocks, ignores := lintBlocksFromFile(file)
+	return blocks, ignores, nil
+}
+
+// parseFile parses SSH config text for the rules and fmt, failing with every
+// problem lexer.LexAll finds, as they would miss what it skips after one.
+func parseFile(input string) (*cst.File, error) {
+	if _, diagnostics := lexer.LexAll(input); len(diagnostics) > 0 {
+		return nil, joinDiagnostics(diagnostics)
+	}
+	return cst.Parse(input)
+}
+
+// lintBlocksFromFile reads the blocks of a parsed SSH config, their sources
+// point into file.
+func lintBlocksFromFile(file *cst.File) ([]LintBlock, lintIgnores) {
+	var ignores lintIgnores
+
+	preamble := LintBlock{AllHosts: true}
+	addLintLines(&preamble, file.Preamble, &ignores)
+	blocks := []LintBlock{preamble}
+	for _, b := range file.Blocks {
+		header := b.Header.Tokens[0]
+		block := LintBlock{Line: header.Line, source: lintSource{node: b, ordered: true}}
+		args := b.Header.Args()
+		if b.Keyword() == "host" {
+			block.Label = "Host " + strings.Join(args, " ")
+			block.Host.Name = strings.Join(args, " ")
+			block.AllHosts = slices.Contains(args, "*")
+		} else {
+			block.Label = "Match " + strings.Join(args, " ")
+			block.AllHosts = len(args) == 1 && strings.EqualFold(args[0], "all")
+			var argTokens []lexer.Token
+			for _, token := range b.Header.Tokens[1:] {
+				if token.Kind == lexer.TokenValue || token.Kind == lexer.TokenQuoted {
+					argTokens = append(argTokens, token)
+				}
+			}
+			// criteria ssh does not support leave the conditions empty
+			block.Host.Match, _ = parseMatchConditions(argTokens, header)
+		}
+		for _, line := range append(slices.Clone(b.Comments), b.Header) {
+			for _, token := range line.Tokens {
+				if token.Kind != lexer.TokenComment {
+					continue
+				}
+				if rules, isFile := parseLintIgnore(token.Value); isFile {
+					ignores.add(&ignores.file, rules)
+				} else {
+					ignores.add(&block.ignored, rules)
+				}
+			}
+		}
+		addLintLines(&block, b.Lines, &ignores)
+		blocks = append(blocks, block)
+	}
+	return blocks, ignores
+}
+
+// addLintLines adds the directives of lines to block.
+func addLintLines(block *LintBlock, lines []*cst.Line, ignores *lintIgnores) {
+	var pending []string
+	for _, line := range lines {
+		if line.IsBlank() {
+			pending = nil
+			continue
+		}
+		if line.IsComment() {
+			rules, isFile := parseLintIgnore(line.Tokens[0].Value)
+			if isFile {
+				ignores.add(&ignores.file, rules)
+			} else {
+				pending = append(pending, rules...)
+			}
+			continue
+		}
+		keyword := line.Tokens[0]
+		if line.Keyword() == "include" {
+			pending = nil
+			continue
+		}
+		last := line.Tokens[len(line.Tokens)-1]
+		if last.Kind == lexer.TokenComment {
+			rules, isFile := parseLintIgnore(last.Value)
+			if isFile {
+				ignores.add(&ignores.file, rules)
+			} else {
+				pending = append(pending, rules...)
+			}
+		}
+		if len(pending) > 0 {
+			ignores.addLine(keyword.Line, pending)
+			pending = nil
+		}
+
+		position := lintPosition{keyword.Line, keyword.Column}
+		block.directives = append(block.directives, lintDirective{keyword.Value, position, line})
+		key := lintKeyword(keyword.Value)
+		if block.Host.HasKey(key) && !Define.IsMultiValueKeyword(key) {
+			// ssh uses the first value
+			continue
+		}
+		block.Host.SetValues(key, append(block.Host.Values(key), strings.Join(line.Args(), " ")))
+		if block.positions == nil {
+			block.positions = make(map[string][]lintPosition)
+		}
+		block.positions[key] = append(block.positions[key], position)
+	}
+}
+
+// lintKeyword returns the canonical spelling of key, aliases included.
+func lintKeyword(key string) string {
+	if modern, ok := Define.ModernKeyword(key); ok {
+		return modern
+	}
+	if canonical, ok := Define.CanonicalKeyword(key); ok {
+		return canonical
+	}
+	return key
+}
+
+// lintBlocksFromHostConfigs turns grouped configs into blocks, source is the
+// YAML or JSON document they come from, "" when there is none. A host's
+// Notes may hold suppression comments, which cover the whole host.
+func lintBlocksFromHostConfigs(hostConfigs []Define.HostConfig, source string) ([]LintBlock, lintIgnores) {
+	var ignores lintIgnores
+	var blocks []LintBlock
+	var sources map[string][]yamlSource
+	var jsonKeys [][]string
+	switch {
+	case source == "":
+	case Fn.DetectStringType(source) == "JSON":
+		jsonKeys = jsonDataKeys(source, len(hostConfigs))
+	default:
+		sources = yamlSources(source)
+	}
+	for i, hostConfig := range hostConfigs {
+		if hostConfig.IsInclude() {
+			continue
+		}
+		block := LintBlock{Host: hostConfig}
+		block.Host.Config, block.Host.Lists = nil, nil
+		block.addValues(hostConfig.Config, hostConfig.Lists)
+		switch {
+		case hostConfig.IsMatch():
+			block.Label = "Match " + FormatMatchConditions(hostConfig.Match)
+			block.AllHosts = len(hostConfig.Match) == 1 && hostConfig.Match[0].Criterion == "all"
+		default:
+			block.Label = "Host " + hostConfig.Name
+			block.AllHosts = slices.Contains(strings.Fields(hostConfig.Name), "*")
+		}
+		if candidates := sources[block.Label]; len(candidates) > 0 {
+			sources[block.Label] = candidates[1:]
+			candidates[0].locate(&block)
+		}
+		if jsonKeys != nil {
+			block.source = lintSource{index: i, ordered: true}
+			for _, key := range jsonKeys[i] {
+				block.directives = append(block.directives, lintDirective{key: key})
+			}
+		}
+		for _, line := range strings.Split(hostConfig.Notes, "\n") {
+			rules, isFile := parseLintIgnore(line)
+			if isFile {
+				ignores.add(&ignores.file, rules)
+			} else {
+				ignores.add(&block.ignored, rules)
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, ignores
+}
+
+// addValues adds the values of a config to the block under their canonical
+// keywords.
+func (b *LintBlock) addValues(config map[string]string, lists map[string][]string) {
+	orderMaps := Fn.GetOrderConfig(config, lists)
+	for _, key := range orderMaps.Keys {
+		name := lintKeyword(key)
+		b.Host.SetValues(name, append(b.Host.Values(name), orderMaps.Values(key)...))
+	}
+}
+
+// addYAMLDirectives adds the keys of the YAML mapping at path, in the order
+// they are written.
+func (b *LintBlock) addYAMLDirectives(lines Fn.YAMLLines, path []string) {
+	for rawKey, line := range lines.Keys(path...) {
+		b.directives = append(b.directives, lintDirective{key: rawKey, lintPosition: lintPosition{line: line}})
+	}
+	slices.SortFunc(b.directives, func(a, b lintDirective) int { return cmp.Compare(a.line, b.line) })
+}
+
+// yamlSource is where a block is written in a YAML document.
+type yamlSource struct {
+	group string
+	line  int
+	// paths lead to the mappings the values come from, the block's own
+	// first, then the group's Common and the default section.
+	paths [][]string
+	lines Fn.YAMLLines
+}
+
+// locate sets the group of block, the line of the block and its values,
+// and the directives of its own mapping.
+func (s yamlSource) locate(block *LintBlock) {
+	block.Group, block.Line = s.group, s.line
+	block.source.path = s.paths[0]
+	block.addYAMLDirectives(s.lines, s.paths[0])
+	for _, path := range s.paths {
+		for rawKey, line := range s.lines.Keys(path...) {
+			key := lintKeyword(rawKey)
+			if !block.Host.HasKey(key) || block.positions[key] != nil {
+				continue
+			}
+			if block.positions == nil {
+				block.positions = make(map[string][]lintPosition)
+			}
+			block.positions[key] = []lintPosition{{line: line}}
+		}
+	}
+}
+
+// yamlSources returns where each block of a YAML document is written, keyed
+// by the block label; blocks with the same label come in document order.
+func yamlSources(input string) map[string][]yamlSource {
+	data := Fn.GetYamlData(input)
+	lines := Fn.IndexYAMLLines(input)
+	sources := make(map[string][]yamlSource)
+	add := func(label, group string, path ...string) {
+		source := yamlSource{group: group, line: lines.Line(path...), lines: lines}
+		switch {
+		case path[0] == "global":
+			source.paths = [][]string{path}
+		case group == "" || slices.Contains(path, "Match"):
+			source.paths = [][]string{append(slices.Clone(path), "config")}
+		default:
+			source.paths = [][]string{append(slices.Clone(path), "config"), {group, "Common"}, {"default"}}
+		}
+		sources[label] = append(sources[label], source)
+	}
+
+	if data.Global != nil || data.GlobalLists != nil {
+		add("Host *", "", "global")
+	}
+	for _, groupName := range slices.Sorted(maps.Keys(data.Groups)) {
+		group := data.Groups[groupName]
+		for _, hostName := range slices.Sorted(maps.Keys(group.Hosts)) {
+			name := hostName
+			if patterns := group.Hosts[hostName].Patterns; len(patterns) > 0 {
+				name = Define.FormatHostPatterns(patterns)
+			}
+			add("Host "+name, groupName, groupName, "Hosts", hostName)
+		}
+		for i, match := range group.Match {
+			add("Match "+FormatMatchConditions(match.Match), groupName, groupName, "Match", "["+strconv.Itoa(i)+"]")
+		}
+	}
+	for i, match := range data.Match {
+		add("Match "+FormatMatchConditions(match.Match), "", "match", "["+strconv.Itoa(i)+"]")
+	}
+	return sources
+}
+
+// runLint runs every rule over blocks and drops the suppressed findings.
+// Findings with a position come in source order.
+func runLint(blocks []LintBlock, ignores lintIgnores, options LintOptions) []LintFinding {
+	return runRules(LintRules, blocks, ignores, options)
+}
+
+// runRules is runLint with rules instead of LintRules.
+func runRules(rules []LintRule, blocks []LintBlock, ignores lintIgnores, options LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, block := range blocks {
+		for i := range rules {
+			for _, finding := range rules[i].check(&rules[i], block, blocks, options) {
+				if !ignores.suppressed(finding, block) {
+					findings = append(findings, finding)
+				}
+			}
+		}
+		for i := range options.Rules {
+			for _, finding := range options.Rules[i].check(block, blocks) {
+				if !ignores.suppressed(finding, block) {
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(findings, func(a, b LintFinding) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return findings
+}
+
+// LintSSHConfig runs the rules over SSH config text.
+func LintSSHConfig(input string, options LintOptions) ([]LintFinding, error) {
+	blocks, ignores, err := lintBlocksFromSSH(input)
+	if err != nil {
+		return nil, err
+	}
+	return runLint(blocks, ignores, options), nil
+}
+
+// LintHostConfigs runs the rules over grouped configs. source is the YAML
+// or JSON document they come from, which gives the keywords as written and,
+// for YAML, the groups and the line of each value.
+func LintHostConfigs(hostConfigs []Define.HostConfig, source string, options LintOptions) []LintFinding {
+	blocks, ignores := lintBlocksFromHostConfigs(hostConfigs, source)
+	return runLint(blocks, ignores, options)
+}
+
+// lintOptionsFromArgs reads the OpenSSH version, the algorithm policy and
+// the policy rules the command is given.
+func lintOptionsFromArgs(args Cmd.Args, options Options) (LintOptions, error) {
+	lintOptions := LintOptions{Context: options.ExpandContext, Version: validate.LatestVersion, Policy: validate.ModernPolicy}
+	if args.OpenSSHVersion != "" {
+		version, err := validate.ParseVersion(args.OpenSSHVersion)
+		if err != nil {
+			return lintOptions, err
+		}
+		lintOptions.Version = version
+	}
+	if args.CryptoPolicy != "" {
+		data, err := os.ReadFile(args.CryptoPolicy)
+		if err != nil {
+			return lintOptions, err
+		}
+		if lintOptions.Policy, err = validate.ParsePolicy(data); err != nil {
+			return lintOptions, fmt.Errorf("%s: %w", args.CryptoPolicy, err)
+		}
+	}
+	if args.Policy != "" {
+		data, err := os.ReadFile(args.Policy)
+		if err != nil {
+			return lintOptions, err
+		}
+		if lintOptions.Rules, err = ParsePolicyRules(data); err != nil {
+			return lintOptions, fmt.Errorf("%s: %w", args.Policy, err)
+		}
+	}
+	return lintOptions, nil
+}
+
+// lintInput runs the rules over userInput. SSH config text is returned
+// parsed, the sources of the findings' fixes point into it.
+func lintInput(fileType string, userInput string, args Cmd.Args, options Options, lintOptions LintOptions) ([]LintFinding, *cst.File, error) {
+	if strings.EqualFold(fileType, "TEXT") {
+		file, err := parseFile(userInput)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks, ignores := lintBlocksFromFile(file)
+		return runLint(blocks, ignores, lintOptions), file, nil
+	}
+	hostConfigs, err := groupInput(fileType, userInput, args, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return LintHostConfigs(hostConfigs, userInput, lintOptions), nil, nil
+}
+
+// formatFindings writes one finding per line.
+func formatFindings(findings []LintFinding) string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// processLint runs the lint command: one line per finding, and an error when
+// there is any.
+func processLint(fileType string, userInput string, args Cmd.Args, options Options) ([]byte, error) {
+	lintOptions, err := lintOptionsFromArgs(args, options)
+	if err != nil {
+		return nil, err
+	}
+	var diagnostics *diagnostics
+	if args.Format != "" {
+		diagnostics = newDiagnostics(args, &options)
+		diagnostics.report.Rules = append(diagnostics.report.Rules, diagnosticRules(lintOptions.Rules)...)
+	}
+	findings, _, err := lintInput(fileType, userInput, args, options, lintOptions)
+	if diagnostics != nil {
+		if err != nil {
+			diagnostics.addError(err)
+		}
+		for _, finding := range findings {
+			diagnostics.add(finding.Diagnostic(""))
+		}
+		return diagnostics.render(args.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(findings) == 0 {
+		return []byte("No problems found"), nil
+	}
+	return []byte(formatFindings(findings)), FindingsError{Count: len(findings), One: "problem", Many: "problems"}
+}
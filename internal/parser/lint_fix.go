@@ -0,0 +1,450 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/cst"
+	"gopkg.in/yaml.v2"
+)
+
+// lintFixKind is what a fix does, in the order fixes are applied.
+type lintFixKind int
+
+const (
+	// fixRemove removes the directive.
+	fixRemove lintFixKind = iota
+	// fixRename writes the directive with key.
+	fixRename
+	// fixReplace writes the directive as key and value.
+	fixReplace
+	// fixMerge moves the directive to the end of the target block.
+	fixMerge
+	// fixAdd adds key and value to the block, after the directive.
+	fixAdd
+	// fixRemoveBlock removes the block.
+	fixRemoveBlock
+	// fixMoveAfter moves the block right after the target block.
+	fixMoveAfter
+)
+
+// lintFix is the change lint -fix or modernize makes for a finding.
+type lintFix struct {
+	kind       lintFixKind
+	source     lintSource
+	directive  lintDirective
+	key, value string
+	// target is the block a merged directive or a moved block moves to.
+	target lintSource
+}
+
+// withFix returns finding with fix when the format of block supports it:
+// SSH config text supports every fix, YAML all but moves, and JSON every
+// fix when the hosts could be matched with the array.
+func (b LintBlock) withFix(finding LintFinding, fix lintFix) LintFinding {
+	fix.source = b.source
+	var ok bool
+	switch {
+	case fix.kind == fixMerge || fix.kind == fixRemoveBlock:
+		// blocks are only merged in SSH config text
+		ok = b.source.node != nil
+	case fix.kind == fixAdd && fix.directive.node != nil:
+		// nothing is added before the first block
+		ok = b.source.node != nil
+	case fix.directive.node != nil || b.source.node != nil:
+		ok = true
+	case b.source.path != nil:
+		ok = fix.kind != fixMoveAfter && fix.directive.line > 0
+	default:
+		ok = b.source.ordered
+	}
+	if ok {
+		finding.fix = &fix
+	}
+	return finding
+}
+
+// fixesOf returns the fixes of findings in the order they are applied.
+func fixesOf(findings []LintFinding) []*lintFix {
+	var fixes []*lintFix
+	for _, finding := range findings {
+		if finding.fix != nil {
+			fixes = append(fixes, finding.fix)
+		}
+	}
+	slices.SortStableFunc(fixes, func(a, b *lintFix) int { return cmp.Compare(a.kind, b.kind) })
+	return fixes
+}
+
+// fixSSHConfig applies fixes to the parsed SSH config they point into.
+func fixSSHConfig(file *cst.File, fixes []*lintFix) error {
+	// the lines that replaced a directive, nil for removed ones, so a later
+	// fix of the same directive moves what is left of it
+	current := make(map[*cst.Line]*cst.Line)
+	for _, fix := range fixes {
+		switch fix.kind {
+		case fixRemove:
+			file.RemoveLine(fix.directive.node)
+			current[fix.directive.node] = nil
+		case fixRename, fixReplace:
+			line, err := fix.directive.node.WithKeyword(fix.key)
+			if err == nil && fix.kind == fixReplace {
+				line, err = line.WithValue(fix.value)
+			}
+			if err != nil {
+				return err
+			}
+			file.ReplaceLine(fix.directive.node, line)
+			current[fix.directive.node] = line
+		case fixMerge:
+			line := fix.directive.node
+			if replaced, ok := current[line]; ok {
+				if line = replaced; line == nil {
+					continue
+				}
+			}
+			file.RemoveLine(line)
+			if err := fix.target.node.Add(line.Tokens[0].Value, line.Value()); err != nil {
+				return err
+			}
+		case fixRemoveBlock:
+			file.RemoveBlock(fix.source.node)
+		case fixAdd:
+			if err := fix.source.node.Add(fix.key, fix.value); err != nil {
+				return err
+			}
+		case fixMoveAfter:
+			file.MoveAfter(fix.source.node, fix.target.node)
+		}
+	}
+	return nil
+}
+
+// fixYAML applies fixes to the lines of a YAML document, leaving the other
+// lines and their comments as they are.
+func fixYAML(input string, fixes []*lintFix) string {
+	lines := strings.SplitAfter(input, "\n")
+	// from the last line up, so the line of the next fix stays valid
+	slices.SortStableFunc(fixes, func(a, b *lintFix) int {
+		return cmp.Or(cmp.Compare(b.directive.line, a.directive.line), cmp.Compare(b.kind, a.kind))
+	})
+	removed := make(map[int]bool)
+	for _, fix := range fixes {
+		start := fix.directive.line - 1
+		if start < 0 || start >= len(lines) || removed[start] {
+			continue
+		}
+		content := strings.TrimLeft(lines[start], " ")
+		indent := lines[start][:len(lines[start])-len(content)]
+		end := yamlValueEnd(lines, start)
+		switch fix.kind {
+		case fixRemove:
+			lines = slices.Delete(lines, start, end)
+			removed[start] = true
+		case fixRename:
+			lines[start] = indent + strings.Replace(content, fix.directive.key, fix.key, 1)
+		case fixReplace:
+			newline := lines[end-1][len(strings.TrimRight(lines[end-1], "\r\n")):]
+			value, _ := yaml.Marshal(fix.value)
+			lines = slices.Replace(lines, start, end, indent+fix.key+": "+strings.TrimSpace(string(value))+newline)
+		case fixAdd:
+			newline := "\n"
+			if strings.HasSuffix(lines[start], "\r\n") {
+				newline = "\r\n"
+			}
+			if !strings.HasSuffix(lines[end-1], "\n") {
+				lines[end-1] += newline
+			}
+			value, _ := yaml.Marshal(fix.value)
+			lines = slices.Insert(lines, end, indent+fix.key+": "+strings.TrimSpace(string(value))+newline)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// yamlValueEnd returns the index after the last line of the value of the
+// key on lines[start]: the lines indented deeper, or list items at its
+// indent.
+func yamlValueEnd(lines []string, start int) int {
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " "))
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		content := strings.TrimLeft(lines[i], " ")
+		trimmed := strings.TrimSpace(content)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lineIndent := len(lines[i]) - len(content)
+		if lineIndent < indent || lineIndent == indent && trimmed != "-" && !strings.HasPrefix(trimmed, "- ") {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+// jsonMember is a member of a JSON object with its value as written.
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// jsonObject reads the members of a JSON object in order.
+func jsonObject(data []byte) ([]jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	var members []jsonMember
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, jsonMember{token.(string), value})
+	}
+	return members, nil
+}
+
+// encodeJSONObject writes members as a compact JSON object.
+func encodeJSONObject(members []jsonMember) json.RawMessage {
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		key, _ := json.Marshal(member.key)
+		out.Write(key)
+		out.WriteByte(':')
+		_ = json.Compact(&out, member.value)
+	}
+	out.WriteByte('}')
+	return out.Bytes()
+}
+
+// jsonData returns the members of the host at element and the index of its
+// Data member, -1 when it has none.
+func jsonData(element json.RawMessage) (host []jsonMember, dataIndex int, data []jsonMember, err error) {
+	if host, err = jsonObject(element); err != nil {
+		return nil, -1, nil, err
+	}
+	dataIndex = slices.IndexFunc(host, func(member jsonMember) bool { return strings.EqualFold(member.key, "Data") })
+	if dataIndex >= 0 {
+		if data, err = jsonObject(host[dataIndex].value); err != nil {
+			return nil, -1, nil, err
+		}
+	}
+	return host, dataIndex, data, nil
+}
+
+// jsonDataKeys returns the keywords each host of a JSON document sets, in
+// the order they are written; nil when the document does not hold count
+// hosts.
+func jsonDataKeys(input string, count int) [][]string {
+	var elements []json.RawMessage
+	if json.Unmarshal([]byte(input), &elements) != nil || len(elements) != count {
+		return nil
+	}
+	keys := make([][]string, len(elements))
+	for i, element := range elements {
+		_, _, data, err := jsonData(element)
+		if err != nil {
+			return nil
+		}
+		for _, member := range data {
+			keys[i] = append(keys[i], member.key)
+		}
+	}
+	return keys
+}
+
+// fixJSON applies fixes to a JSON document. The members keep their order,
+// and the document its indentation.
+func fixJSON(input string, fixes []*lintFix) (string, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(input), &elements); err != nil {
+		return "", err
+	}
+	// the index of each moved host, by the index of the host it moves after
+	moved := make(map[int][]int)
+	for index := range elements {
+		var own []*lintFix
+		for _, fix := range fixes {
+			switch {
+			case fix.source.index != index:
+			case fix.kind == fixMoveAfter:
+				moved[fix.target.index] = append(moved[fix.target.index], index)
+			default:
+				own = append(own, fix)
+			}
+		}
+		if len(own) == 0 {
+			continue
+		}
+		host, dataIndex, data, err := jsonData(elements[index])
+		if err != nil || dataIndex < 0 {
+			return "", fmt.Errorf("host %d: %v", index+1, cmp.Or(err, fmt.Errorf("no Data")))
+		}
+		for _, fix := range own {
+			// a repeated key is removed from the end, the others are found
+			// from the start
+			i := -1
+			for j, member := range data {
+				if member.key == fix.directive.key && (i < 0 || fix.kind == fixRemove) {
+					i = j
+				}
+			}
+			if i < 0 {
+				continue
+			}
+			switch fix.kind {
+			case fixRemove:
+				data = slices.Delete(data, i, i+1)
+			case fixRename:
+				data[i].key = fix.key
+			case fixReplace:
+				value, _ := json.Marshal(fix.value)
+				data[i] = jsonMember{fix.key, value}
+			case fixAdd:
+				value, _ := json.Marshal(fix.value)
+				data = slices.Insert(data, i+1, jsonMember{fix.key, value})
+			}
+		}
+		host[dataIndex].value = encodeJSONObject(data)
+		elements[index] = encodeJSONObject(host)
+	}
+	isMoved := make(map[int]bool)
+	for _, indexes := range moved {
+		for _, index := range indexes {
+			isMoved[index] = true
+		}
+	}
+	ordered := make([]json.RawMessage, 0, len(elements))
+	for index, element := range elements {
+		if !isMoved[index] {
+			ordered = append(ordered, element)
+		}
+		for _, movedIndex := range moved[index] {
+			ordered = append(ordered, elements[movedIndex])
+		}
+	}
+	elements = ordered
+
+	var compact bytes.Buffer
+	compact.WriteByte('[')
+	for i, element := range elements {
+		if i > 0 {
+			compact.WriteByte(',')
+		}
+		if err := json.Compact(&compact, element); err != nil {
+			return "", err
+		}
+	}
+	compact.WriteByte(']')
+
+	trimmed := strings.TrimSpace(input)
+	output := compact.String()
+	if strings.Contains(trimmed, "\n") {
+		var indented bytes.Buffer
+		_ = json.Indent(&indented, compact.Bytes(), "", jsonIndent(trimmed))
+		output = indented.String()
+	}
+	return output + input[len(strings.TrimRight(input, " \r\n")):], nil
+}
+
+// jsonIndent returns the indentation of the second line of an indented
+// JSON document.
+func jsonIndent(input string) string {
+	_, rest, _ := strings.Cut(input, "\n")
+	if indent := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]; indent != "" {
+		return indent
+	}
+	return "  "
+}
+
+// applyFixes returns userInput with fixes applied. file is userInput parsed
+// when it is SSH config text, the fixes point into it.
+func applyFixes(fileType string, userInput string, file *cst.File, fixes []*lintFix) (string, error) {
+	switch {
+	case len(fixes) == 0:
+		return userInput, nil
+	case file != nil:
+		if err := fixSSHConfig(file, fixes); err != nil {
+			return "", err
+		}
+		return file.String(), nil
+	case strings.EqualFold(fileType, "JSON"):
+		return fixJSON(userInput, fixes)
+	}
+	return fixYAML(userInput, fixes), nil
+}
+
+// fixReport writes one line per finding, then the unified diff of the fixes
+// to the config name.
+func fixReport(findings []LintFinding, name string, input string, fixed string) string {
+	report := formatFindings(findings)
+	if diff := Fn.UnifiedDiff(name, name, input, fixed); diff != "" {
+		return report + "\n\n" + strings.TrimSuffix(diff, "\n")
+	}
+	return report + "\n\nNothing to fix"
+}
+
+// LintFix runs the lint command and applies the fixes of its findings. It
+// returns the fixed input, nil when the input can not be linted, and a
+// report with the findings and the changes as a unified diff. The error
+// counts the problems the fixes leave.
+func LintFix(fileType string, userInput string, args Cmd.Args) ([]byte, []byte, error) {
+	options := OptionsFromArgs(args)
+	if err := options.Validate(); err != nil {
+		return nil, nil, err
+	}
+	lintOptions, err := lintOptionsFromArgs(args, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	findings, file, err := lintInput(fileType, userInput, args, options, lintOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(findings) == 0 {
+		return []byte(userInput), []byte("No problems found"), nil
+	}
+
+	fixes := fixesOf(findings)
+	fixed, err := applyFixes(fileType, userInput, file, fixes)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := fixReport(findings, sourceName(args), userInput, fixed)
+	if left := len(findings) - len(fixes); left > 0 {
+		return []byte(fixed), []byte(report), FindingsError{Count: left, One: "problem -fix can not fix", Many: "problems -fix can not fix"}
+	}
+	return []byte(fixed), []byte(report), nil
+}
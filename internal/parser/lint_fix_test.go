@@ -0,0 +1,234 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+const fixableConfig = `# defaults
+Host *
+    User deploy
+
+# web servers
+Host web
+    HostName web.example.com
+    User www
+    PubkeyAcceptedKeyTypes +ssh-rsa
+    IdentityFile ~/.ssh/web
+    Port 22
+    port 2222
+`
+
+func TestLintFix_SSH(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandLint, Fix: true, Src: "config"}
+	fixed, report, err := Parser.LintFix("TEXT", fixableConfig, args)
+	if err != nil {
+		t.Fatalf("LintFix() error = %v\n%s", err, report)
+	}
+	// the comment that starts the file stays there
+	want := `# defaults
+
+# web servers
+Host web
+    HostName web.example.com
+    User www
+    PubkeyAcceptedAlgorithms +ssh-rsa
+    IdentityFile ~/.ssh/web
+    Port 22
+    IdentitiesOnly yes
+
+Host *
+    User deploy
+`
+	if string(fixed) != want {
+		t.Errorf("LintFix() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+	for _, line := range []string{
+		"2: warning: Host *: Host * comes before Host web and overrides its User, move it after the specific hosts (SSH012 all-hosts-first)",
+		"9:5: warning: Host web: PubkeyAcceptedKeyTypes is the old name of PubkeyAcceptedAlgorithms (SSH010 deprecated-keyword)",
+		"12:5: warning: Host web: Port is already set on line 11, ssh uses the first value (SSH013 duplicate-key)",
+		"--- config\n+++ config\n@@ -1,12 +1,13 @@\n # defaults\n-Host *\n-    User deploy\n \n # web servers\n",
+	} {
+		if !strings.Contains(string(report), line) {
+			t.Errorf("LintFix() report does not hold %q:\n%s", line, report)
+		}
+	}
+
+	if fixed, report, err = Parser.LintFix("TEXT", string(fixed), args); err != nil || string(report) != "No problems found" {
+		t.Errorf("LintFix() of the fixed config = %s, %v", report, err)
+	}
+}
+
+func TestLintFix_DiffName(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(fixableConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args Cmd.Args
+		want string
+	}{
+		{Cmd.Args{Src: dir, Stdin: true}, "--- stdin\n+++ stdin\n"},
+		{Cmd.Args{Src: dir}, "--- " + filepath.Join(dir, "config") + "\n"},
+	}
+	for _, tt := range tests {
+		tt.args.Command, tt.args.Fix = Cmd.CommandLint, true
+		_, report, _ := Parser.LintFix("TEXT", fixableConfig, tt.args)
+		if !strings.Contains(string(report), tt.want) {
+			t.Errorf("LintFix(%+v) report does not hold %q:\n%s", tt.args, tt.want, report)
+		}
+	}
+}
+
+func TestLintFix_MoveFirstBlock(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandLint, Fix: true}
+	tests := []struct {
+		name, input, want string
+	}{
+		{
+			"header comment",
+			"# ssh config of alice\nHost *\n    User deploy\n\nHost web\n    User www\n",
+			"# ssh config of alice\n\nHost web\n    User www\n\nHost *\n    User deploy\n",
+		},
+		{
+			"blank lines before",
+			"\n\nHost *\n    User deploy\n\nHost web\n    User www\n",
+			"Host web\n    User www\n\nHost *\n    User deploy\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, report, err := Parser.LintFix("TEXT", tt.input, args)
+			if err != nil || string(fixed) != tt.want {
+				t.Errorf("LintFix() = %q, %v, want %q\n%s", fixed, err, tt.want, report)
+			}
+		})
+	}
+}
+
+func TestLintFix_AllHostsBeforeMatch(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandLint, Fix: true}
+	input := "Host *\n    User deploy\n\nMatch host foo\n    User foo\n"
+	if fixed, report, err := Parser.LintFix("TEXT", input, args); err != nil || string(fixed) != input {
+		t.Errorf("LintFix() = %q, %s, %v, want no change", fixed, report, err)
+	}
+
+	// Host * moves after web, the Match block keeps reading its User first
+	input = "Host *\n    User deploy\n\nHost web\n    User www\n\nMatch host foo\n    User foo\n\nHost db\n    User db\n"
+	fixed, report, err := Parser.LintFix("TEXT", input, args)
+	if err != nil {
+		t.Fatalf("LintFix() error = %v\n%s", err, report)
+	}
+	want := "Host web\n    User www\n\nHost *\n    User deploy\n\nMatch host foo\n    User foo\n\nHost db\n    User db\n"
+	if string(fixed) != want {
+		t.Errorf("LintFix() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+
+	// db only comes after the Match block, so Host * can not move after it
+	input = "Host *\n    User deploy\n\nMatch host foo\n    Port 22\n\nHost db\n    User db\n"
+	fixed, report, err = Parser.LintFix("TEXT", input, args)
+	if err == nil || err.Error() != "found 1 problem -fix can not fix" || string(fixed) != input {
+		t.Errorf("LintFix() = %q, %s, %v", fixed, report, err)
+	}
+}
+
+func TestLintFix_YAML(t *testing.T) {
+	input := `global:
+  User: deploy
+Group web:
+  Hosts:
+    web1:
+      config:
+        HostName: web1.example.com
+        PubkeyAcceptedKeyTypes: +ssh-rsa # legacy server
+        IdentityFile:
+          - ~/.ssh/web
+        Port: 22
+        port: 2222
+`
+	args := Cmd.Args{Command: Cmd.CommandLint, Fix: true}
+	fixed, report, err := Parser.LintFix("YAML", input, args)
+	if err != nil {
+		t.Fatalf("LintFix() error = %v\n%s", err, report)
+	}
+	want := `global:
+  User: deploy
+Group web:
+  Hosts:
+    web1:
+      config:
+        HostName: web1.example.com
+        PubkeyAcceptedAlgorithms: +ssh-rsa # legacy server
+        IdentityFile:
+          - ~/.ssh/web
+        IdentitiesOnly: "yes"
+        Port: 22
+`
+	if string(fixed) != want {
+		t.Errorf("LintFix() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+}
+
+func TestLintFix_JSON(t *testing.T) {
+	input := `[
+  {"Name": "*", "Data": {"User": "deploy"}},
+  {"Name": "web", "Data": {"HostName": "web.example.com", "User": "www", "PubkeyAcceptedKeyTypes": "+ssh-rsa", "IdentityFile": "~/.ssh/web"}}
+]
+`
+	args := Cmd.Args{Command: Cmd.CommandLint, Fix: true}
+	fixed, report, err := Parser.LintFix("JSON", input, args)
+	if err != nil {
+		t.Fatalf("LintFix() error = %v\n%s", err, report)
+	}
+	want := `[
+  {
+    "Name": "web",
+    "Data": {
+      "HostName": "web.example.com",
+      "User": "www",
+      "PubkeyAcceptedAlgorithms": "+ssh-rsa",
+      "IdentityFile": "~/.ssh/web",
+      "IdentitiesOnly": "yes"
+    }
+  },
+  {
+    "Name": "*",
+    "Data": {
+      "User": "deploy"
+    }
+  }
+]
+`
+	if string(fixed) != want {
+		t.Errorf("LintFix() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+}
+
+func TestLintFix_Unfixable(t *testing.T) {
+	input := "Host *\n    StrictHostKeyChecking no\n"
+	fixed, report, err := Parser.LintFix("TEXT", input, Cmd.Args{Command: Cmd.CommandLint, Fix: true})
+	if err == nil || err.Error() != "found 1 problem -fix can not fix" || string(fixed) != input || !strings.HasSuffix(string(report), "\n\nNothing to fix") {
+		t.Errorf("LintFix() = %q, %q, %v", fixed, report, err)
+	}
+}
@@ -0,0 +1,400 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+	"github.com/soulteary/ssh-config/v2/pkg/validate"
+)
+
+// LintRules are the rules the lint command runs, in ID order.
+var LintRules = []LintRule{
+	{
+		ID:       "SSH001",
+		Name:     "forward-agent-all-hosts",
+		Severity: SeverityError,
+		Summary:  "ForwardAgent is enabled for every host",
+		check:    checkAllHostsFlag("ForwardAgent", "lets the admin of every host you reach use your keys, enable it only for the hosts that need it"),
+	},
+	{
+		ID:       "SSH002",
+		Name:     "forward-x11-trusted-all-hosts",
+		Severity: SeverityError,
+		Summary:  "ForwardX11Trusted is enabled for every host",
+		check:    checkAllHostsFlag("ForwardX11Trusted", "gives every host you reach full access to your display, enable it only for the hosts that need it"),
+	},
+	{
+		ID:       "SSH003",
+		Name:     "strict-host-key-checking-off",
+		Severity: SeverityError,
+		Summary:  "StrictHostKeyChecking no accepts any host key",
+		check:    checkStrictHostKeyChecking,
+	},
+	{
+		ID:       "SSH004",
+		Name:     "known-hosts-discarded",
+		Severity: SeverityError,
+		Summary:  "known hosts are read from and written to /dev/null",
+		check:    checkKnownHostsFile,
+	},
+	{
+		ID:       "SSH005",
+		Name:     "password-auth-production",
+		Severity: SeverityWarning,
+		Summary:  "PasswordAuthentication yes on a production host",
+		check:    checkProductionPassword,
+	},
+	{
+		ID:       "SSH006",
+		Name:     "permit-local-command",
+		Severity: SeverityWarning,
+		Summary:  "PermitLocalCommand runs a LocalCommand after every connection",
+		check:    checkLocalCommand,
+	},
+	{
+		ID:       "SSH007",
+		Name:     "control-path-without-hash",
+		Severity: SeverityWarning,
+		Summary:  "ControlPath does not use %C",
+		check:    checkControlPathHash,
+	},
+	{
+		ID:       "SSH008",
+		Name:     "control-path-too-long",
+		Severity: SeverityError,
+		Summary:  "ControlPath is longer than a unix socket path can be",
+		check:    checkControlPathLength,
+	},
+	{
+		ID:       "SSH009",
+		Name:     "weak-algorithm",
+		Severity: SeverityWarning,
+		Summary:  "an algorithm list enables algorithms the algorithm policy does not accept",
+		check:    checkAlgorithmPolicy,
+	},
+	{
+		ID:       "SSH010",
+		Name:     "deprecated-keyword",
+		Severity: SeverityWarning,
+		Summary:  "a keyword is written with a name OpenSSH only keeps for compatibility",
+		check:    checkDeprecatedKeyword,
+	},
+	{
+		ID:       "SSH011",
+		Name:     "identities-only",
+		Severity: SeverityWarning,
+		Summary:  "IdentityFile without IdentitiesOnly yes also offers every key of the agent",
+		check:    checkIdentitiesOnly,
+	},
+	{
+		ID:       "SSH012",
+		Name:     "all-hosts-first",
+		Severity: SeverityWarning,
+		Summary:  "Host * comes before specific hosts and overrides their settings",
+		check:    checkAllHostsFirst,
+	},
+	{
+		ID:       "SSH013",
+		Name:     "duplicate-key",
+		Severity: SeverityWarning,
+		Summary:  "a keyword is set twice in a block, ssh ignores all but the first",
+		check:    checkDuplicateKey,
+	},
+}
+
+// findLintRule returns the rule with id, nil when there is none.
+func findLintRule(id string) *LintRule {
+	for _, rules := range [][]LintRule{LintRules, ModernizeRules} {
+		for i := range rules {
+			if rules[i].ID == id {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// checkAllHostsFlag flags key when a block every host gets turns it on.
+// ForwardAgent also takes a socket path, anything but no forwards.
+func checkAllHostsFlag(key, risk string) func(*LintRule, LintBlock, []LintBlock, LintOptions) []LintFinding {
+	return func(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+		value := block.value(key)
+		if !block.AllHosts || value == "" || strings.EqualFold(value, "no") {
+			return nil
+		}
+		return []LintFinding{block.finding(rule, key, "%s %s %s", key, value, risk)}
+	}
+}
+
+func checkStrictHostKeyChecking(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	value := block.value("StrictHostKeyChecking")
+	if !strings.EqualFold(value, "no") && !strings.EqualFold(value, "off") {
+		return nil
+	}
+	return []LintFinding{block.finding(rule, "StrictHostKeyChecking",
+		"StrictHostKeyChecking %s connects to hosts whose key changed, use accept-new to only trust new hosts", value)}
+}
+
+func checkKnownHostsFile(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, key := range []string{"UserKnownHostsFile", "GlobalKnownHostsFile"} {
+		if slices.Contains(strings.Fields(block.value(key)), "/dev/null") {
+			findings = append(findings, block.finding(rule, key,
+				"%s /dev/null forgets every host key, so a changed key is never noticed", key))
+		}
+	}
+	return findings
+}
+
+// productionPattern matches names such as prod, prod-db or production, but
+// not product.
+var productionPattern = regexp.MustCompile(`(?i)(^|[^a-z])prod(uction)?([^a-z]|$)`)
+
+// isProduction reports whether the group, patterns, HostName or Tag of
+// block name a production environment.
+func isProduction(block LintBlock) bool {
+	names := []string{block.Group, block.Host.Name, block.value("HostName")}
+	names = append(names, block.Host.Values("Tag")...)
+	for _, condition := range block.Host.Match {
+		names = append(names, condition.Argument)
+	}
+	return slices.ContainsFunc(names, productionPattern.MatchString)
+}
+
+func checkProductionPassword(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	if !strings.EqualFold(block.value("PasswordAuthentication"), "yes") || !isProduction(block) {
+		return nil
+	}
+	return []LintFinding{block.finding(rule, "PasswordAuthentication",
+		"PasswordAuthentication yes on a production host, use keys or certificates")}
+}
+
+// checkLocalCommand flags a LocalCommand that PermitLocalCommand lets run,
+// either set in the block or inherited from a block every host gets. The
+// finding goes to the block that sets one of the two.
+func checkLocalCommand(rule *LintRule, block LintBlock, blocks []LintBlock, _ LintOptions) []LintFinding {
+	permit, command := block.value("PermitLocalCommand"), block.value("LocalCommand")
+	if permit == "" && command == "" {
+		return nil
+	}
+	for _, other := range blocks {
+		if !other.AllHosts {
+			continue
+		}
+		if permit == "" {
+			permit = other.value("PermitLocalCommand")
+		}
+		if command == "" {
+			command = other.value("LocalCommand")
+		}
+	}
+	if !strings.EqualFold(permit, "yes") || command == "" {
+		return nil
+	}
+	key := "LocalCommand"
+	if !block.Host.HasKey(key) {
+		key = "PermitLocalCommand"
+	}
+	return []LintFinding{block.finding(rule, key,
+		"PermitLocalCommand yes runs %q on this machine after every connection", command)}
+}
+
+// controlPathValue returns the ControlPath of block, "" when it has none.
+func controlPathValue(block LintBlock) string {
+	value := block.value("ControlPath")
+	if strings.EqualFold(value, "none") {
+		return ""
+	}
+	return value
+}
+
+func checkControlPathHash(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	value := controlPathValue(block)
+	if value == "" || strings.Contains(value, "%C") {
+		return nil
+	}
+	return []LintFinding{block.finding(rule, "ControlPath",
+		"ControlPath %q does not use %%C, connections that differ in user, port or jump host may share a socket", value)}
+}
+
+// maxControlPath is the longest ControlPath ssh can bind: the 104 bytes of
+// sun_path on BSD and macOS, less the NUL and the ".XXXXXXXXXXXXXXXX"
+// suffix of the temporary socket.
+const maxControlPath = 104 - 1 - len(".XXXXXXXXXXXXXXXX")
+
+func checkControlPathLength(rule *LintRule, block LintBlock, _ []LintBlock, options LintOptions) []LintFinding {
+	value := controlPathValue(block)
+	if value == "" {
+		return nil
+	}
+	hostConfig := block.Host
+	if patterns := Define.ParseHostPatterns(hostConfig.Name); len(patterns) > 0 {
+		hostConfig.Name = patterns[0].Pattern
+	}
+	path, _ := expand.Expand("ControlPath", value, HostContext(hostConfig, options.Context))
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/') {
+		path = options.Context.HomeDir + rest
+	}
+	if len(path) <= maxControlPath {
+		return nil
+	}
+	return []LintFinding{block.finding(rule, "ControlPath",
+		"ControlPath %q expands to %d bytes, more than the %d a unix socket allows", value, len(path), maxControlPath)}
+}
+
+// checkAlgorithmPolicy checks the algorithms each list of the block enables
+// once its modifier is applied to the defaults of options.Version. Lists
+// the block does not set are left to the defaults.
+func checkAlgorithmPolicy(rule *LintRule, block LintBlock, _ []LintBlock, options LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, key := range validate.PolicyKeywords {
+		value := block.value(key)
+		if value == "" {
+			continue
+		}
+		violations := options.Policy.Check(key, validate.EffectiveAlgorithms(key, value, options.Version))
+		if len(violations) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			names = append(names, violation.Algorithm)
+		}
+		findings = append(findings, block.finding(rule, key,
+			"%s %q enables %s, which the algorithm policy does not accept", key, value, strings.Join(names, ", ")))
+	}
+	return findings
+}
+
+// directiveFinding returns a finding of rule on directive d.
+func (b LintBlock) directiveFinding(rule *LintRule, d lintDirective, format string, args ...any) LintFinding {
+	finding := b.finding(rule, lintKeyword(d.key), format, args...)
+	if d.line > 0 {
+		finding.Line, finding.Column = d.line, d.column
+	}
+	return finding
+}
+
+func checkDeprecatedKeyword(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, d := range block.directives {
+		modern, ok := Define.ModernKeyword(d.key)
+		if !ok {
+			continue
+		}
+		finding := block.directiveFinding(rule, d, "%s is the old name of %s", d.key, modern)
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixRename, directive: d, key: modern}))
+	}
+	return findings
+}
+
+// checkIdentitiesOnly flags the IdentityFile of a block when neither the
+// block nor one every host gets sets IdentitiesOnly: ssh then offers the
+// keys of the agent first, and a server may close the connection after
+// MaxAuthTries keys before the right one. The fix adds it to the block.
+func checkIdentitiesOnly(rule *LintRule, block LintBlock, blocks []LintBlock, _ LintOptions) []LintFinding {
+	if !block.Host.HasKey("IdentityFile") || policyValues(block, blocks, "IdentitiesOnly") != nil {
+		return nil
+	}
+	finding := block.finding(rule, "IdentityFile",
+		"IdentityFile without IdentitiesOnly yes, ssh offers every key of the agent before it")
+	i := slices.IndexFunc(block.directives, func(d lintDirective) bool { return lintKeyword(d.key) == "IdentityFile" })
+	if i < 0 {
+		return []LintFinding{finding}
+	}
+	// after the last IdentityFile
+	for j, d := range block.directives {
+		if lintKeyword(d.key) == "IdentityFile" {
+			i = j
+		}
+	}
+	return []LintFinding{block.withFix(finding, lintFix{kind: fixAdd, directive: block.directives[i], key: "IdentitiesOnly", value: "yes"})}
+}
+
+// checkAllHostsFirst flags a Host * block that comes before a Host block
+// setting the same keywords: ssh uses the first value it reads, so the
+// specific values are ignored. The fix moves the block after the last of the
+// specific Host blocks that follow it, but never past a Match block or
+// another block every host gets, which would change what they set.
+func checkAllHostsFirst(rule *LintRule, block LintBlock, blocks []LintBlock, _ LintOptions) []LintFinding {
+	if !block.AllHosts || block.Host.Name == "" || !block.source.ordered {
+		return nil
+	}
+	index := slices.IndexFunc(blocks, func(other LintBlock) bool {
+		return other.source.node == block.source.node && other.source.index == block.source.index
+	})
+	end := index + 1
+	for end < len(blocks) && isSpecificHost(blocks[end]) {
+		end++
+	}
+	for position, later := range blocks[index+1:] {
+		if !isSpecificHost(later) {
+			continue
+		}
+		var overridden []string
+		for _, key := range Fn.GetOrderConfig(block.Host.Config, block.Host.Lists).Keys {
+			if later.Host.HasKey(key) && !Define.IsMultiValueKeyword(key) {
+				overridden = append(overridden, key)
+			}
+		}
+		if len(overridden) == 0 {
+			continue
+		}
+		finding := block.finding(rule, "", "%s comes before %s and overrides its %s, move it after the specific hosts",
+			block.Label, later.Label, strings.Join(overridden, ", "))
+		if index+1+position >= end {
+			return []LintFinding{finding}
+		}
+		return []LintFinding{block.withFix(finding, lintFix{kind: fixMoveAfter, target: blocks[end-1].source})}
+	}
+	return nil
+}
+
+// isSpecificHost reports whether block is a Host block some hosts do not get.
+func isSpecificHost(block LintBlock) bool {
+	return block.Host.Name != "" && !block.AllHosts
+}
+
+func checkDuplicateKey(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	var findings []LintFinding
+	first := make(map[string]lintDirective)
+	for _, d := range block.directives {
+		key := lintKeyword(d.key)
+		if Define.IsMultiValueKeyword(key) {
+			continue
+		}
+		previous, seen := first[key]
+		if !seen {
+			first[key] = d
+			continue
+		}
+		where := ""
+		if previous.line > 0 {
+			where = fmt.Sprintf(" on line %d", previous.line)
+		}
+		finding := block.directiveFinding(rule, d, "%s is already set%s, ssh uses the first value", key, where)
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixRemove, directive: d}))
+	}
+	return findings
+}
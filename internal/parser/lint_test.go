@@ -0,0 +1,159 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+	"github.com/soulteary/ssh-config/v2/pkg/validate"
+)
+
+const riskyConfig = `StrictHostKeyChecking no
+Host *
+    ForwardAgent yes
+    ForwardX11Trusted yes
+    PermitLocalCommand yes
+Host prod-db
+    PasswordAuthentication yes
+    UserKnownHostsFile /dev/null
+    LocalCommand echo connected
+Host cache
+    ControlPath ~/.ssh/sockets/%r@%h-%p
+# lint:ignore SSH007
+Host build
+    ControlPath ~/.ssh/a-very-long-directory-name-for-control-sockets/and-another-one/%C
+Host product
+    PasswordAuthentication yes
+    StrictHostKeyChecking no # lint:ignore strict-host-key-checking-off
+Host legacy
+    Ciphers aes128-cbc,aes256-ctr
+    HostKeyAlgorithms +ssh-rsa
+    KexAlgorithms -diffie-hellman-group14-sha1
+`
+
+var lintOptions = Parser.LintOptions{
+	Context: expand.Context{LocalUser: "alice", HomeDir: "/home/alice", LocalHostname: "laptop"},
+	Version: validate.LatestVersion,
+	Policy:  validate.ModernPolicy,
+}
+
+func findingStrings(findings []Parser.LintFinding) []string {
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.String())
+	}
+	return messages
+}
+
+func TestLintSSHConfig(t *testing.T) {
+	findings, err := Parser.LintSSHConfig(riskyConfig, lintOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`1:1: error: StrictHostKeyChecking no connects to hosts whose key changed, use accept-new to only trust new hosts (SSH003 strict-host-key-checking-off)`,
+		`3:5: error: Host *: ForwardAgent yes lets the admin of every host you reach use your keys, enable it only for the hosts that need it (SSH001 forward-agent-all-hosts)`,
+		`4:5: error: Host *: ForwardX11Trusted yes gives every host you reach full access to your display, enable it only for the hosts that need it (SSH002 forward-x11-trusted-all-hosts)`,
+		`7:5: warning: Host prod-db: PasswordAuthentication yes on a production host, use keys or certificates (SSH005 password-auth-production)`,
+		`8:5: error: Host prod-db: UserKnownHostsFile /dev/null forgets every host key, so a changed key is never noticed (SSH004 known-hosts-discarded)`,
+		`9:5: warning: Host prod-db: PermitLocalCommand yes runs "echo connected" on this machine after every connection (SSH006 permit-local-command)`,
+		`11:5: warning: Host cache: ControlPath "~/.ssh/sockets/%r@%h-%p" does not use %C, connections that differ in user, port or jump host may share a socket (SSH007 control-path-without-hash)`,
+		`14:5: error: Host build: ControlPath "~/.ssh/a-very-long-directory-name-for-control-sockets/and-another-one/%C" expands to 120 bytes, more than the 86 a unix socket allows (SSH008 control-path-too-long)`,
+		`19:5: warning: Host legacy: Ciphers "aes128-cbc,aes256-ctr" enables aes128-cbc, which the algorithm policy does not accept (SSH009 weak-algorithm)`,
+		`20:5: warning: Host legacy: HostKeyAlgorithms "+ssh-rsa" enables ssh-rsa, which the algorithm policy does not accept (SSH009 weak-algorithm)`,
+	}
+	if got := findingStrings(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("LintSSHConfig() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	findings, err = Parser.LintSSHConfig("# lint:file-ignore SSH003\nHost a\n    StrictHostKeyChecking off\n", lintOptions)
+	if err != nil || len(findings) != 0 {
+		t.Errorf("LintSSHConfig() = %v, %v, want no findings", findingStrings(findings), err)
+	}
+
+	findings, err = Parser.LintSSHConfig("Match host prod-*\n    PasswordAuthentication yes\n", lintOptions)
+	want = []string{`2:5: warning: Match host prod-*: PasswordAuthentication yes on a production host, use keys or certificates (SSH005 password-auth-production)`}
+	if got := findingStrings(findings); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LintSSHConfig() = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestLintHostConfigs(t *testing.T) {
+	input := `
+global:
+  ForwardAgent: "yes"
+Group production:
+  Hosts:
+    db:
+      config:
+        HostName: 10.0.0.5
+        PasswordAuthentication: "yes"
+    api:
+      Notes: "lint:ignore SSH005"
+      config:
+        PasswordAuthentication: "yes"
+`
+	hostConfigs := Parser.GroupYAMLConfig(input)
+	want := []string{
+		`3: error: Host *: ForwardAgent yes lets the admin of every host you reach use your keys, enable it only for the hosts that need it (SSH001 forward-agent-all-hosts)`,
+		`9: warning: Host db: PasswordAuthentication yes on a production host, use keys or certificates (SSH005 password-auth-production)`,
+	}
+	got := findingStrings(Parser.LintHostConfigs(hostConfigs, input, lintOptions))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintHostConfigs() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProcess_Lint(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandLint}
+	result, err := Parser.Process("TEXT", riskyConfig, args)
+	if err == nil || err.Error() != "found 10 problems" {
+		t.Errorf("Process() error = %v", err)
+	}
+	if len(result) == 0 {
+		t.Error("Process() returned no findings")
+	}
+
+	result, err = Parser.Process("TEXT", "Host ok\n    ForwardAgent yes\n", args)
+	if err != nil || string(result) != "No problems found" {
+		t.Errorf("Process() = %q, %v", result, err)
+	}
+
+	// a quote left open must not hide the lines after it
+	input := "Host a\n    User \"bob\nHost *\n    StrictHostKeyChecking no\n    ForwardAgent yes\n"
+	result, err = Parser.Process("TEXT", input, args)
+	if err == nil || err.Error() != "line 2 column 10: unclosed quoted string" || len(result) != 0 {
+		t.Errorf("Process() = %q, %v, want the unclosed quote", result, err)
+	}
+
+	// the 7.4 defaults a '+' list builds on still hold SHA-1 key exchange
+	input = "Host old\n    KexAlgorithms +curve25519-sha256\n"
+	args.OpenSSHVersion = "OpenSSH_7.4p1"
+	result, err = Parser.Process("TEXT", input, args)
+	if err == nil || !strings.Contains(string(result), "enables diffie-hellman-group-exchange-sha1, diffie-hellman-group14-sha1,") {
+		t.Errorf("Process() = %q, %v", result, err)
+	}
+	args.OpenSSHVersion = "9"
+	if _, err := Parser.Process("TEXT", input, args); err == nil {
+		t.Error("Process() expected an error for an invalid version")
+	}
+}
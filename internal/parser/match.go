@@ -0,0 +1,181 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os/exec"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+// MatchEnv is the local side of Match criteria, everything ssh would ask
+// the system for. Tests inject it to evaluate Match blocks without touching
+// the machine.
+type MatchEnv struct {
+	// LocalUser is the local user name, for localuser.
+	LocalUser string
+	// LocalAddresses are the addresses of the local interfaces, for localnetwork.
+	LocalAddresses []netip.Addr
+	// Tag is the tag given on the command line (ssh -P), for tagged. When
+	// empty a Tag set by the config is used.
+	Tag string
+	// Canonical and Final are true during the pass after hostname
+	// canonicalization and during the final pass.
+	Canonical bool
+	Final     bool
+	// Exec runs the command of an exec criterion, already expanded, and
+	// reports whether it exited with status 0. exec criteria fail with
+	// ErrExecDisabled when it is nil.
+	Exec func(command string) (bool, error)
+}
+
+// ErrExecDisabled is returned for an exec criterion when MatchEnv.Exec is nil.
+var ErrExecDisabled = errors.New("exec criteria are not run")
+
+// LocalMatchEnv returns the environment of this machine for user. Exec is
+// left nil, running commands is up to the caller.
+func LocalMatchEnv(user string) MatchEnv {
+	env := MatchEnv{LocalUser: user}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if prefix, err := netip.ParsePrefix(addr.String()); err == nil {
+				env.LocalAddresses = append(env.LocalAddresses, prefix.Addr())
+			}
+		}
+	}
+	return env
+}
+
+// ShellExec runs command with "sh -c" like ssh does, for MatchEnv.Exec.
+func ShellExec(command string) (bool, error) {
+	err := exec.Command("sh", "-c", command).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MatchTarget is the connection a Match line is evaluated for, with the
+// values obtained from the config so far.
+type MatchTarget struct {
+	// Host is the host after HostName substitution, OriginalHost the one given.
+	Host         string
+	OriginalHost string
+	// User is the remote user.
+	User string
+	// Tag is the Tag obtained from the config.
+	Tag string
+	// Context expands the tokens of exec commands.
+	Context expand.Context
+}
+
+// EvaluateMatch reports whether every criterion of a Match line holds for
+// target in env. As ssh does, exec commands are not run once an earlier
+// criterion of the line failed.
+func EvaluateMatch(conditions []Define.MatchCondition, target MatchTarget, env MatchEnv) (bool, error) {
+	result := true
+	for _, condition := range conditions {
+		var matched bool
+		switch condition.Criterion {
+		case "all":
+			matched = true
+		case "canonical":
+			matched = env.Canonical
+		case "final":
+			matched = env.Final
+		case "host":
+			matched = matchPatternList(condition.Argument, target.Host)
+		case "originalhost":
+			matched = matchPatternList(condition.Argument, target.OriginalHost)
+		case "user":
+			matched = matchUserList(condition.Argument, target.User)
+		case "localuser":
+			matched = matchUserList(condition.Argument, env.LocalUser)
+		case "tagged":
+			tag := env.Tag
+			if tag == "" {
+				tag = target.Tag
+			}
+			matched = matchPatternList(condition.Argument, tag)
+		case "localnetwork":
+			var err error
+			if matched, err = matchLocalNetwork(condition.Argument, env.LocalAddresses); err != nil {
+				return false, err
+			}
+		case "exec":
+			if !result {
+				continue
+			}
+			if env.Exec == nil {
+				return false, ErrExecDisabled
+			}
+			command, problems := expand.Expand("exec", condition.Argument, target.Context)
+			if len(problems) > 0 {
+				return false, problems[0]
+			}
+			var err error
+			if matched, err = env.Exec(command); err != nil {
+				return false, fmt.Errorf("exec %q: %w", command, err)
+			}
+		default:
+			return false, fmt.Errorf("unsupported match criterion %q", condition.Criterion)
+		}
+		if matched == condition.Negate {
+			result = false
+		}
+	}
+	return result, nil
+}
+
+// matchPatternList matches a comma-separated list of patterns; an empty
+// value only matches an empty list.
+func matchPatternList(list, value string) bool {
+	return Define.MatchHostPatterns(Define.ParsePatternList(list), value)
+}
+
+// matchUserList is matchPatternList for user names, compared with their case.
+func matchUserList(list, user string) bool {
+	return Define.MatchUserPatterns(Define.ParsePatternList(list), user)
+}
+
+// matchLocalNetwork reports whether one of addresses is in the comma-separated
+// list of networks, written as CIDR or single addresses.
+func matchLocalNetwork(list string, addresses []netip.Addr) (bool, error) {
+	for _, network := range strings.Split(list, ",") {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(network)
+			if addrErr != nil {
+				return false, fmt.Errorf("invalid localnetwork %q", network)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		for _, address := range addresses {
+			if prefix.Contains(address.Unmap()) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,184 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+func TestEvaluateMatch(t *testing.T) {
+	target := Parser.MatchTarget{Host: "web1.example.com", OriginalHost: "web1", User: "deploy", Tag: "prod"}
+	env := Parser.MatchEnv{
+		LocalUser:      "alice",
+		LocalAddresses: []netip.Addr{netip.MustParseAddr("192.168.1.20"), netip.MustParseAddr("fe80::1")},
+	}
+
+	tests := []struct {
+		line string
+		env  func(env *Parser.MatchEnv)
+		want bool
+	}{
+		{line: "all", want: true},
+		{line: "host *.example.com", want: true},
+		{line: "host web1", want: false},
+		{line: "originalhost web?", want: true},
+		{line: "!originalhost web1", want: false},
+		{line: "host *.example.com,!web1.*", want: false},
+		{line: "user deploy,ops localuser alice", want: true},
+		{line: "localuser root", want: false},
+		{line: "user Deploy", want: false},
+		{line: "user dep*", want: true},
+		{line: "localuser ALICE", want: false},
+		{line: "host WEB1.example.com", want: true},
+		{line: "localnetwork 10.0.0.0/8,192.168.1.0/24", want: true},
+		{line: "localnetwork fe80::1", want: true},
+		{line: "!localnetwork 10.0.0.0/8", want: true},
+		{line: "tagged prod", want: true},
+		{line: "tagged prod", env: func(env *Parser.MatchEnv) { env.Tag = "dev" }, want: false},
+		{line: "canonical all", want: false},
+		{line: "final", env: func(env *Parser.MatchEnv) { env.Final = true }, want: true},
+		{line: "canonical host *.example.com", env: func(env *Parser.MatchEnv) { env.Canonical = true }, want: true},
+	}
+	for _, tt := range tests {
+		conditions := matchConditions(t, tt.line)
+		env := env
+		if tt.env != nil {
+			tt.env(&env)
+		}
+		got, err := Parser.EvaluateMatch(conditions, target, env)
+		if err != nil {
+			t.Errorf("EvaluateMatch(%s) error = %v", tt.line, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvaluateMatch(%s) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := Parser.EvaluateMatch(matchConditions(t, "localnetwork 10.0.0/8"), target, env); err == nil {
+		t.Error("EvaluateMatch() expected an error for an invalid network")
+	}
+	if _, err := Parser.EvaluateMatch(matchConditions(t, `exec "true"`), target, env); !errors.Is(err, Parser.ErrExecDisabled) {
+		t.Errorf("EvaluateMatch() error = %v, want ErrExecDisabled", err)
+	}
+}
+
+func TestEvaluateMatch_Exec(t *testing.T) {
+	var commands []string
+	env := Parser.MatchEnv{Exec: func(command string) (bool, error) {
+		commands = append(commands, command)
+		return command == "on-vpn web1.example.com 22", nil
+	}}
+	target := Parser.MatchTarget{
+		Host:    "web1.example.com",
+		Context: expand.Context{Host: "web1.example.com", Port: "22"},
+	}
+
+	if got, err := Parser.EvaluateMatch(matchConditions(t, `exec "on-vpn %h %p"`), target, env); err != nil || !got {
+		t.Errorf("EvaluateMatch() = %v, %v, want true", got, err)
+	}
+	if got, err := Parser.EvaluateMatch(matchConditions(t, `!exec "on-vpn %h %p"`), target, env); err != nil || got {
+		t.Errorf("EvaluateMatch(!exec) = %v, %v, want false", got, err)
+	}
+	// like ssh, exec is not run once the line can no longer match
+	if got, _ := Parser.EvaluateMatch(matchConditions(t, `host other exec "skipped"`), target, env); got {
+		t.Error("EvaluateMatch() = true, want false")
+	}
+	if want := []string{"on-vpn web1.example.com 22", "on-vpn web1.example.com 22"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+
+	env.Exec = func(string) (bool, error) { return false, errors.New("no shell") }
+	if _, err := Parser.EvaluateMatch(matchConditions(t, `exec "x"`), target, env); err == nil {
+		t.Error("EvaluateMatch() expected the exec error")
+	}
+}
+
+// The office network routes directly, everywhere else goes through the bastion.
+const officeConfig = `Match host *.corp exec "on-office-network"
+    ProxyJump none
+
+Match host *.corp
+    ProxyJump bastion.example.com
+
+Match tagged admin
+    User root
+
+Match final host *.corp
+    ServerAliveInterval 30
+
+Host *.corp
+    User deploy
+`
+
+func TestResolve_MatchExec(t *testing.T) {
+	hostConfigs, err := Parser.GroupSSHConfigWithOptions(officeConfig, Parser.Options{KeepOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		inOffice bool
+		tag      string
+		want     map[string]string
+	}{
+		{
+			name:     "office",
+			inOffice: true,
+			want:     map[string]string{"HostName": "db.corp", "Port": "22", "ProxyJump": "none", "ServerAliveInterval": "30", "User": "deploy"},
+		},
+		{
+			name: "laptop",
+			tag:  "admin",
+			want: map[string]string{"HostName": "db.corp", "Port": "22", "ProxyJump": "bastion.example.com", "ServerAliveInterval": "30", "Tag": "admin", "User": "root"},
+		},
+	} {
+		runs := 0
+		options := Parser.Options{MatchEnv: Parser.MatchEnv{
+			Tag: tt.tag,
+			Exec: func(command string) (bool, error) {
+				runs++
+				return tt.inOffice, nil
+			},
+		}}
+		got := Parser.Resolve(hostConfigs, Parser.Destination{Host: "db.corp"}, options)
+		if !reflect.DeepEqual(got.Config, tt.want) {
+			t.Errorf("%s: Resolve() = %v, want %v", tt.name, got.Config, tt.want)
+		}
+		// the final pass evaluates the exec line again
+		if runs != 2 {
+			t.Errorf("%s: exec ran %d times, want 2", tt.name, runs)
+		}
+	}
+}
+
+func matchConditions(t *testing.T, line string) []Define.MatchCondition {
+	t.Helper()
+	blocks, err := Parser.GroupSSHBlocksFromString("Match " + line + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blocks[0].Match
+}
@@ -0,0 +1,381 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/cst"
+	"github.com/soulteary/ssh-config/v2/pkg/validate"
+)
+
+// ModernizeRules are the legacy patterns the modernize command rewrites, in
+// ID order. Each finding is a change, those without a fix are patterns it
+// found but can not rewrite.
+var ModernizeRules = []LintRule{
+	{
+		ID:       "MOD001",
+		Name:     "proxy-jump",
+		Severity: SeverityInfo,
+		Summary:  "a ProxyCommand that runs ssh to a jump host is written as ProxyJump",
+		check:    checkProxyJump,
+	},
+	{
+		ID:       "MOD002",
+		Name:     "removed-keyword",
+		Severity: SeverityInfo,
+		Summary:  "a keyword OpenSSH no longer supports, such as Protocol, is removed",
+		check:    checkRemovedKeyword,
+	},
+	{
+		ID:       "MOD003",
+		Name:     "renamed-keyword",
+		Severity: SeverityInfo,
+		Summary:  "a keyword is written with its current name",
+		check:    checkDeprecatedKeyword,
+	},
+	{
+		ID:       "MOD004",
+		Name:     "weak-algorithms",
+		Severity: SeverityInfo,
+		Summary:  "the algorithms the algorithm policy does not accept are removed from algorithm lists",
+		check:    checkWeakAlgorithms,
+	},
+	{
+		ID:       "MOD005",
+		Name:     "duplicate-all-hosts",
+		Severity: SeverityInfo,
+		Summary:  "a repeated Host * block is merged into the first one",
+		check:    checkDuplicateAllHosts,
+	},
+}
+
+// directiveValue returns the value of directive d as ssh reads it.
+func (b LintBlock) directiveValue(d lintDirective) string {
+	if d.node != nil {
+		return strings.Join(d.node.Args(), " ")
+	}
+	return b.value(lintKeyword(d.key))
+}
+
+// proxyJumpOf returns the ProxyJump a ProxyCommand stands for: ssh to a jump
+// host that forwards the connection with -W %h:%p or runs nc %h %p there.
+// Commands using the shell or other ssh options have no ProxyJump.
+func proxyJumpOf(command string) (string, bool) {
+	if strings.ContainsAny(command, ";|&$`<>(){}\\\"") {
+		return "", false
+	}
+	fields := strings.Fields(command)
+	if len(fields) > 0 && fields[0] == "exec" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || path.Base(fields[0]) != "ssh" {
+		return "", false
+	}
+	unquote := func(field string) string {
+		if len(field) > 1 && strings.HasPrefix(field, "'") && strings.HasSuffix(field, "'") {
+			return field[1 : len(field)-1]
+		}
+		return field
+	}
+
+	var user, host, port string
+	forward := false
+	for args := fields[1:]; len(args) > 0; {
+		arg := unquote(args[0])
+		args = args[1:]
+		if !strings.HasPrefix(arg, "-") {
+			if host == "" {
+				host = arg
+				continue
+			}
+			// the command run on the jump host
+			netcat := []string{"nc", "ncat", "netcat"}
+			if forward || len(args) != 2 || !slices.Contains(netcat, path.Base(arg)) || unquote(args[0]) != "%h" || unquote(args[1]) != "%p" {
+				return "", false
+			}
+			forward = true
+			break
+		}
+		if len(arg) < 2 {
+			return "", false
+		}
+		switch option, value := arg[1], arg[2:]; option {
+		case 'W', 'l', 'p':
+			if value == "" {
+				if len(args) == 0 {
+					return "", false
+				}
+				value, args = unquote(args[0]), args[1:]
+			}
+			switch {
+			case option == 'l':
+				user = value
+			case option == 'p':
+				port = value
+			case forward || value != "%h:%p" && value != "[%h]:%p":
+				return "", false
+			default:
+				forward = true
+			}
+		default:
+			// quiet, no tty and no X11 forwarding change nothing for a jump
+			if strings.Trim(arg[1:], "qTx") != "" {
+				return "", false
+			}
+		}
+	}
+
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		if user != "" {
+			return "", false
+		}
+		user, host = host[:i], host[i+1:]
+	}
+	if !forward || host == "" || strings.Contains(user+host+port, "%") {
+		return "", false
+	}
+	jump := host
+	if port != "" {
+		if _, err := strconv.Atoi(port); err != nil {
+			return "", false
+		}
+		if strings.Contains(host, ":") {
+			jump = "[" + host + "]"
+		}
+		jump += ":" + port
+	}
+	if user != "" {
+		jump = user + "@" + jump
+	}
+	return jump, true
+}
+
+// checkProxyJump rewrites a ProxyCommand that only reaches the host through
+// a jump host as ProxyJump, unless the block sets ProxyJump too.
+func checkProxyJump(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	if block.Host.HasKey("ProxyJump") {
+		return nil
+	}
+	var findings []LintFinding
+	for _, d := range block.directives {
+		if lintKeyword(d.key) != "ProxyCommand" {
+			continue
+		}
+		command := block.directiveValue(d)
+		jump, ok := proxyJumpOf(command)
+		if !ok {
+			continue
+		}
+		finding := block.directiveFinding(rule, d, "ProxyCommand %s becomes ProxyJump %s", command, jump)
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixReplace, directive: d, key: "ProxyJump", value: jump}))
+	}
+	return findings
+}
+
+func checkRemovedKeyword(rule *LintRule, block LintBlock, _ []LintBlock, _ LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, d := range block.directives {
+		if _, ok := Define.RemovedKeyword(d.key); !ok {
+			continue
+		}
+		finding := block.directiveFinding(rule, d, "%s %s is removed, OpenSSH no longer supports it and ssh ignores it", d.key, block.directiveValue(d))
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixRemove, directive: d}))
+	}
+	return findings
+}
+
+// checkWeakAlgorithms removes the algorithms the algorithm policy does not
+// accept from the lists that set or add algorithms. A list left empty is
+// removed, so ssh uses its defaults.
+func checkWeakAlgorithms(rule *LintRule, block LintBlock, _ []LintBlock, options LintOptions) []LintFinding {
+	var findings []LintFinding
+	for _, d := range block.directives {
+		key := lintKeyword(d.key)
+		if !slices.Contains(validate.PolicyKeywords, key) {
+			continue
+		}
+		value := block.directiveValue(d)
+		modifier, names := validate.SplitAlgorithms(value)
+		if modifier == '-' {
+			continue
+		}
+		var kept, dropped []string
+		for _, name := range names {
+			if len(options.Policy.Check(key, []string{name})) > 0 {
+				dropped = append(dropped, name)
+			} else if name != "" {
+				kept = append(kept, name)
+			}
+		}
+		if len(dropped) == 0 {
+			continue
+		}
+		if len(kept) == 0 {
+			finding := block.directiveFinding(rule, d, "%s %s is removed, the algorithm policy accepts none of its algorithms and ssh uses its defaults", d.key, value)
+			findings = append(findings, block.withFix(finding, lintFix{kind: fixRemove, directive: d}))
+			continue
+		}
+		list := strings.Join(kept, ",")
+		if modifier != 0 {
+			list = string(modifier) + list
+		}
+		finding := block.directiveFinding(rule, d, "%s drops %s, which the algorithm policy does not accept", d.key, strings.Join(dropped, ", "))
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixReplace, directive: d, key: d.key, value: list}))
+	}
+	return findings
+}
+
+// matchedKeywords are the keywords Match criteria read, a Match block
+// between two Host * blocks may depend on where they are set.
+var matchedKeywords = []string{"HostName", "User", "Tag"}
+
+// checkDuplicateAllHosts merges a Host * block into the first Host * block
+// of SSH config text. ssh uses the first value of a keyword, so a value the
+// first block already sets is dropped, and the others move there unless a
+// block in between sets them too. The block is removed once it is empty.
+func checkDuplicateAllHosts(rule *LintRule, block LintBlock, blocks []LintBlock, _ LintOptions) []LintFinding {
+	if block.Host.Name != "*" || block.source.node == nil {
+		return nil
+	}
+	index := slices.IndexFunc(blocks, func(other LintBlock) bool { return other.source.node == block.source.node })
+	first := slices.IndexFunc(blocks[:index], func(other LintBlock) bool {
+		return other.Host.Name == "*" && other.source.node != nil
+	})
+	if first < 0 {
+		return nil
+	}
+	target, between := blocks[first], blocks[first+1:index]
+
+	var findings []LintFinding
+	merged := !slices.ContainsFunc(block.source.node.Lines, func(line *cst.Line) bool { return line.Keyword() == "include" })
+	for _, d := range block.directives {
+		key := lintKeyword(d.key)
+		other := slices.IndexFunc(between, func(other LintBlock) bool {
+			if other.source.node.Keyword() == "match" && slices.Contains(matchedKeywords, key) {
+				return true
+			}
+			return other.Host.HasKey(key)
+		})
+		switch {
+		case target.Host.HasKey(key) && !Define.IsMultiValueKeyword(key):
+			finding := block.directiveFinding(rule, d, "%s is removed, Host * on line %d sets it first", d.key, target.Line)
+			findings = append(findings, block.withFix(finding, lintFix{kind: fixRemove, directive: d}))
+		case other >= 0:
+			merged = false
+			findings = append(findings, block.directiveFinding(rule, d, "%s can not move to Host * on line %d, %s in between depends on it",
+				d.key, target.Line, between[other].Label))
+		default:
+			finding := block.directiveFinding(rule, d, "%s moves to Host * on line %d", d.key, target.Line)
+			findings = append(findings, block.withFix(finding, lintFix{kind: fixMerge, directive: d, target: target.source}))
+		}
+	}
+	if merged {
+		finding := block.finding(rule, "", "the block is removed, it repeats Host * on line %d", target.Line)
+		findings = append(findings, block.withFix(finding, lintFix{kind: fixRemoveBlock}))
+	}
+	return findings
+}
+
+// yamlSectionBlocks returns the sections of a YAML document every host of a
+// group, or every host, gets: the default section and the Common section of
+// each group. Their values are not part of any block.
+func yamlSectionBlocks(input string) []LintBlock {
+	data := Fn.GetYamlData(input)
+	lines := Fn.IndexYAMLLines(input)
+	var blocks []LintBlock
+	add := func(label string, config map[string]string, lists map[string][]string, path ...string) {
+		if len(config) == 0 && len(lists) == 0 {
+			return
+		}
+		block := LintBlock{Label: label, Line: lines.Line(path...), source: lintSource{path: path}}
+		block.addValues(config, lists)
+		block.addYAMLDirectives(lines, path)
+		blocks = append(blocks, block)
+	}
+	add("default", data.Default, data.DefaultLists, "default")
+	for _, groupName := range slices.Sorted(maps.Keys(data.Groups)) {
+		group := data.Groups[groupName]
+		add(groupName+" Common", group.Common, group.CommonLists, groupName, "Common")
+	}
+	return blocks
+}
+
+// modernizeInput runs ModernizeRules over userInput. SSH config text is
+// returned parsed, the sources of the findings' fixes point into it.
+func modernizeInput(fileType string, userInput string, args Cmd.Args, options Options, lintOptions LintOptions) ([]LintFinding, *cst.File, error) {
+	if strings.EqualFold(fileType, "TEXT") {
+		file, err := parseFile(userInput)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks, ignores := lintBlocksFromFile(file)
+		return runRules(ModernizeRules, blocks, ignores, lintOptions), file, nil
+	}
+	hostConfigs, err := groupInput(fileType, userInput, args, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocks, ignores := lintBlocksFromHostConfigs(hostConfigs, userInput)
+	if strings.EqualFold(fileType, "YAML") {
+		blocks = append(blocks, yamlSectionBlocks(userInput)...)
+	}
+	return runRules(ModernizeRules, blocks, ignores, lintOptions), nil, nil
+}
+
+// Modernize runs the modernize command: it rewrites the patterns of
+// ModernizeRules in the format of userInput, keeping everything else as it
+// is. It returns the rewritten input, nil when the input can not be read,
+// and a report with every change and their unified diff. The error counts
+// the patterns it can not rewrite.
+func Modernize(fileType string, userInput string, args Cmd.Args) ([]byte, []byte, error) {
+	options := OptionsFromArgs(args)
+	if err := options.Validate(); err != nil {
+		return nil, nil, err
+	}
+	lintOptions, err := lintOptionsFromArgs(args, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	// policy rules are for lint
+	lintOptions.Rules = nil
+	findings, file, err := modernizeInput(fileType, userInput, args, options, lintOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(findings) == 0 {
+		return []byte(userInput), []byte("Nothing to modernize"), nil
+	}
+
+	fixes := fixesOf(findings)
+	fixed, err := applyFixes(fileType, userInput, file, fixes)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := fixReport(findings, sourceName(args), userInput, fixed)
+	if left := len(findings) - len(fixes); left > 0 {
+		return []byte(fixed), []byte(report), FindingsError{Count: left, One: "pattern modernize can not rewrite", Many: "patterns modernize can not rewrite"}
+	}
+	return []byte(fixed), []byte(report), nil
+}
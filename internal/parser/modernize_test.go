@@ -0,0 +1,195 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+const legacyConfig = `# defaults
+Host *
+    User deploy
+    Protocol 2
+
+Host web
+    ProxyCommand ssh -q -W %h:%p admin@bastion -p 2222
+    Ciphers aes256-gcm@openssh.com,aes128-cbc
+    MACs hmac-md5
+    PubkeyAcceptedKeyTypes +ssh-rsa
+
+# more defaults
+Host *
+    User root
+    ServerAliveInterval 30
+`
+
+func TestModernize_SSH(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandModernize, Src: "config"}
+	fixed, report, err := Parser.Modernize("TEXT", legacyConfig, args)
+	if err != nil {
+		t.Fatalf("Modernize() error = %v\n%s", err, report)
+	}
+	want := `# defaults
+Host *
+    User deploy
+    ServerAliveInterval 30
+
+Host web
+    ProxyJump admin@bastion:2222
+    Ciphers aes256-gcm@openssh.com
+    PubkeyAcceptedAlgorithms +ssh-rsa
+`
+	if string(fixed) != want {
+		t.Errorf("Modernize() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+	for _, line := range []string{
+		"4:5: info: Host *: Protocol 2 is removed, OpenSSH no longer supports it and ssh ignores it (MOD002 removed-keyword)",
+		"7:5: info: Host web: ProxyCommand ssh -q -W %h:%p admin@bastion -p 2222 becomes ProxyJump admin@bastion:2222 (MOD001 proxy-jump)",
+		"8:5: info: Host web: Ciphers drops aes128-cbc, which the algorithm policy does not accept (MOD004 weak-algorithms)",
+		"9:5: info: Host web: MACs hmac-md5 is removed, the algorithm policy accepts none of its algorithms and ssh uses its defaults (MOD004 weak-algorithms)",
+		"10:5: info: Host web: PubkeyAcceptedKeyTypes is the old name of PubkeyAcceptedAlgorithms (MOD003 renamed-keyword)",
+		"13: info: Host *: the block is removed, it repeats Host * on line 2 (MOD005 duplicate-all-hosts)",
+		"14:5: info: Host *: User is removed, Host * on line 2 sets it first (MOD005 duplicate-all-hosts)",
+		"15:5: info: Host *: ServerAliveInterval moves to Host * on line 2 (MOD005 duplicate-all-hosts)",
+		"--- config\n+++ config\n",
+	} {
+		if !strings.Contains(string(report), line) {
+			t.Errorf("Modernize() report does not hold %q:\n%s", line, report)
+		}
+	}
+
+	if fixed, report, err = Parser.Modernize("TEXT", string(fixed), args); err != nil || string(report) != "Nothing to modernize" {
+		t.Errorf("Modernize() of the modernized config = %s, %v", report, err)
+	}
+
+	// a piped config is not named after the -src default
+	args.Src, args.Stdin = "/home/alice/.ssh", true
+	if _, report, _ = Parser.Modernize("TEXT", legacyConfig, args); !strings.Contains(string(report), "--- stdin\n+++ stdin\n") {
+		t.Errorf("Modernize() report does not name stdin:\n%s", report)
+	}
+}
+
+func TestModernize_ProxyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"ssh -W %h:%p bastion", "ProxyJump bastion"},
+		{"exec /usr/bin/ssh -W '[%h]:%p' -l admin bastion", "ProxyJump admin@bastion"},
+		{"ssh bastion -p2222 -W %h:%p", "ProxyJump bastion:2222"},
+		{"ssh -p 2222 fd00::1 nc %h %p", "ProxyJump [fd00::1]:2222"},
+		{"ssh bastion netcat %h %p", "ProxyJump bastion"},
+		{"ssh -A -W %h:%p bastion", ""},
+		{"ssh -W %h:%p bastion-%r", ""},
+		{"ssh bastion nc %h 22", ""},
+		{"ssh -W %h:%p bastion 2>/dev/null", ""},
+		{"nc -X connect -x proxy:8080 %h %p", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			input := "Host web\n    ProxyCommand " + tt.command + "\n"
+			fixed, _, err := Parser.Modernize("TEXT", input, Cmd.Args{Command: Cmd.CommandModernize})
+			if err != nil {
+				t.Fatalf("Modernize() error = %v", err)
+			}
+			want := input
+			if tt.want != "" {
+				want = "Host web\n    " + tt.want + "\n"
+			}
+			if string(fixed) != want {
+				t.Errorf("Modernize() = %q, want %q", fixed, want)
+			}
+		})
+	}
+}
+
+func TestModernize_YAML(t *testing.T) {
+	input := `global:
+  Protocol: 2
+  ServerAliveInterval: 30
+Group web:
+  Common:
+    # all web hosts go through the bastion
+    ProxyCommand: ssh -W %h:%p bastion
+  Hosts:
+    web1:
+      config:
+        HostName: 10.0.0.1
+        KexAlgorithms: diffie-hellman-group1-sha1,curve25519-sha256
+`
+	fixed, report, err := Parser.Modernize("YAML", input, Cmd.Args{Command: Cmd.CommandModernize})
+	if err != nil {
+		t.Fatalf("Modernize() error = %v\n%s", err, report)
+	}
+	want := `global:
+  ServerAliveInterval: 30
+Group web:
+  Common:
+    # all web hosts go through the bastion
+    ProxyJump: bastion
+  Hosts:
+    web1:
+      config:
+        HostName: 10.0.0.1
+        KexAlgorithms: curve25519-sha256
+`
+	if string(fixed) != want {
+		t.Errorf("Modernize() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+	if line := "7: info: Group web Common: ProxyCommand ssh -W %h:%p bastion becomes ProxyJump bastion (MOD001 proxy-jump)"; !strings.Contains(string(report), line) {
+		t.Errorf("Modernize() report does not hold %q:\n%s", line, report)
+	}
+}
+
+func TestModernize_JSON(t *testing.T) {
+	input := `[
+  {"Name": "web", "Data": {"HostName": "10.0.0.1", "ProxyCommand": "ssh bastion nc %h %p", "UseRoaming": "no"}}
+]
+`
+	fixed, _, err := Parser.Modernize("JSON", input, Cmd.Args{Command: Cmd.CommandModernize})
+	if err != nil {
+		t.Fatalf("Modernize() error = %v", err)
+	}
+	want := `[
+  {
+    "Name": "web",
+    "Data": {
+      "HostName": "10.0.0.1",
+      "ProxyJump": "bastion"
+    }
+  }
+]
+`
+	if string(fixed) != want {
+		t.Errorf("Modernize() fixed =\n%s\nwant\n%s", fixed, want)
+	}
+}
+
+func TestModernize_Unmovable(t *testing.T) {
+	input := "Host *\n    User deploy\n\nHost web\n    Port 2222\n\nHost *\n    Port 22\n"
+	fixed, report, err := Parser.Modernize("TEXT", input, Cmd.Args{Command: Cmd.CommandModernize})
+	if err == nil || err.Error() != "found 1 pattern modernize can not rewrite" || string(fixed) != input {
+		t.Fatalf("Modernize() = %q, %v", fixed, err)
+	}
+	if line := "8:5: info: Host *: Port can not move to Host * on line 1, Host web in between depends on it (MOD005 duplicate-all-hosts)"; !strings.Contains(string(report), line) {
+		t.Errorf("Modernize() report does not hold %q:\n%s", line, report)
+	}
+}
@@ -17,35 +17,137 @@
 package parser
 
 import (
+	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	Cmd "github.com/soulteary/ssh-config/v2/cmd"
 	Define "github.com/soulteary/ssh-config/v2/internal/define"
 	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
 )
 
-func Process(fileType string, userInput string, args Cmd.Args) ([]byte, error) {
-	var hostConfigs []Define.HostConfig
+// Options tunes how configs are grouped and rendered, the zero value keeps
+// the historical output.
+type Options struct {
+	// KeepOrder keeps blocks in the order they were written instead of
+	// sorting them, as ssh uses the first value it obtains.
+	KeepOrder bool
+	// Duplicates decides what happens to Host blocks sharing the same
+	// patterns, DuplicateWarn when empty.
+	Duplicates DuplicatePolicy
+	// Expand replaces the tokens and ${ENV} references of concrete hosts,
+	// ExpandContext provides the local values.
+	Expand        bool
+	ExpandContext expand.Context
+	// MatchEnv is the local environment Match blocks are evaluated in when
+	// resolving a destination.
+	MatchEnv MatchEnv
+	// KeepCase keeps keywords as they are written instead of giving them
+	// their ssh_config(5) spelling.
+	KeepCase bool
+	// LegacyKeywords makes ConvertToSSHWithOptions write renamed keywords
+	// with their old name, e.g. PubkeyAcceptedKeyTypes, for OpenSSH < 8.5.
+	LegacyKeywords bool
+	// Warn receives the warnings, they are dropped when nil.
+	Warn func(message string)
+}
 
-	switch strings.ToUpper(fileType) {
-	case "YAML":
-		hostConfigs = GroupYAMLConfig(userInput)
-	case "JSON":
-		hostConfigs = GroupJSONConfig(userInput)
-	case "TEXT":
-		var err error
-		hostConfigs, err = GroupSSHConfig(userInput)
-		if err != nil {
-			return nil, err
+// DuplicatePolicy is how repeated Host blocks are handled.
+type DuplicatePolicy string
+
+const (
+	// DuplicateWarn merges the blocks like DuplicateMerge and reports it.
+	DuplicateWarn DuplicatePolicy = "warn"
+	// DuplicateError refuses the config.
+	DuplicateError DuplicatePolicy = "error"
+	// DuplicateMerge merges the blocks, the first value of a keyword wins.
+	DuplicateMerge DuplicatePolicy = "merge"
+	// DuplicateKeep keeps every block on its own, in source order.
+	DuplicateKeep DuplicatePolicy = "keep"
+)
+
+var DuplicatePolicies = []DuplicatePolicy{DuplicateWarn, DuplicateError, DuplicateMerge, DuplicateKeep}
+
+func OptionsFromArgs(args Cmd.Args) Options {
+	options := Options{
+		KeepOrder:      args.KeepOrder,
+		Duplicates:     DuplicatePolicy(strings.ToLower(args.Duplicates)),
+		Expand:         args.Expand,
+		KeepCase:       args.KeepCase,
+		LegacyKeywords: args.LegacyKeywords,
+		Warn: func(message string) {
+			fmt.Fprintln(os.Stderr, "Warning:", message)
+		},
+	}
+	if options.Expand || args.Command == Cmd.CommandResolve || args.Command == Cmd.CommandLint {
+		options.ExpandContext = expand.LocalContext()
+	}
+	if args.Command == Cmd.CommandResolve {
+		options.MatchEnv = LocalMatchEnv(options.ExpandContext.LocalUser)
+		options.MatchEnv.Tag = args.Tag
+		if args.MatchExec {
+			options.MatchEnv.Exec = ShellExec
 		}
 	}
+	return options
+}
+
+func (o Options) Validate() error {
+	if o.Duplicates != "" && !slices.Contains(DuplicatePolicies, o.Duplicates) {
+		return fmt.Errorf("unsupported duplicate policy %q, use one of %v", o.Duplicates, DuplicatePolicies)
+	}
+	return nil
+}
+
+func (o Options) duplicates() DuplicatePolicy {
+	if o.Duplicates == "" {
+		return DuplicateWarn
+	}
+	return o.Duplicates
+}
+
+func (o Options) warn(message string) {
+	if o.Warn != nil {
+		o.Warn(message)
+	}
+}
+
+func Process(fileType string, userInput string, args Cmd.Args) ([]byte, error) {
+	options := OptionsFromArgs(args)
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if args.Command == Cmd.CommandResolve {
+		return processResolve(fileType, userInput, args, options)
+	}
+	if args.Command == Cmd.CommandValidate {
+		return processValidate(fileType, userInput, args, options)
+	}
+	if args.Command == Cmd.CommandLint {
+		return processLint(fileType, userInput, args, options)
+	}
+	if args.Command == Cmd.CommandFmt {
+		return processFormat(fileType, userInput, args, options)
+	}
+
+	hostConfigs, err := groupInput(fileType, userInput, args, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Expand {
+		hostConfigs = ExpandHostConfigs(hostConfigs, options)
+	}
 
 	if args.ToYAML {
-		return Fn.TidyLastEmptyLines(ConvertToYAML(hostConfigs)), nil
+		return Fn.TidyLastEmptyLines(ConvertToYAMLWithOptions(hostConfigs, options)), nil
 	}
 
 	if args.ToSSH {
-		return Fn.TidyLastEmptyLines(ConvertToSSH(hostConfigs)), nil
+		return Fn.TidyLastEmptyLines(ConvertToSSHWithOptions(hostConfigs, options)), nil
 	}
 
 	if args.ToJSON {
@@ -53,3 +155,36 @@ func Process(fileType string, userInput string, args Cmd.Args) ([]byte, error) {
 	}
 	return nil, nil
 }
+
+// groupInput reads userInput of the detected fileType into host configs.
+func groupInput(fileType string, userInput string, args Cmd.Args, options Options) ([]Define.HostConfig, error) {
+	switch strings.ToUpper(fileType) {
+	case "YAML":
+		if args.Strict {
+			if _, err := Fn.GetYamlDataStrict(userInput); err != nil {
+				return nil, err
+			}
+		}
+		return GroupYAMLConfigWithOptions(userInput, options), nil
+	case "JSON":
+		if args.Strict {
+			if _, err := Fn.GetJSONDataStrict(userInput); err != nil {
+				return nil, err
+			}
+		}
+		return GroupJSONConfigWithOptions(userInput, options), nil
+	case "TEXT":
+		if !args.KeepInclude {
+			var err error
+			userInput, err = Fn.ResolveIncludes(userInput, Fn.IncludeOptions{
+				Source:  Fn.IncludeSource(args.Src),
+				BaseDir: args.IncludeDir,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return GroupSSHConfigWithOptions(userInput, options)
+	}
+	return nil, nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 
 	Cmd "github.com/soulteary/ssh-config/v2/cmd"
@@ -143,3 +144,94 @@ func TestProcess_InvalidTEXT_ReturnsError(t *testing.T) {
 		t.Error("Process() expected error for invalid TEXT input, got nil")
 	}
 }
+
+func TestProcess_Include(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := path.Join(home, ".ssh")
+	if err := os.MkdirAll(path.Join(dir, "config.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(dir, "config.d", "work"), []byte("Host work\n    HostName work.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := path.Join(dir, "config")
+	input := "Include config.d/*\nHost main\n    HostName main.example.com\n"
+	if err := os.WriteFile(config, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Parser.Process("TEXT", input, Cmd.Args{ToSSH: true, Src: dir})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := "Host main\n    HostName main.example.com\n\nHost work\n    HostName work.example.com"
+	if string(got) != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+
+	got, err = Parser.Process("TEXT", input, Cmd.Args{ToSSH: true, Src: config, KeepInclude: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want = "Include config.d/*\n\nHost main\n    HostName main.example.com"
+	if string(got) != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+
+	if _, err := Parser.Process("TEXT", "Include config\n", Cmd.Args{ToSSH: true, Src: config}); err == nil {
+		t.Error("Process() expected include cycle error, got nil")
+	}
+}
+
+func TestProcess_IncludeOutsideSSHDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	src := t.TempDir()
+	for file, content := range map[string]string{
+		path.Join(home, ".ssh", "config.d", "work"): "Host work\n    HostName ssh-dir.example.com\n",
+		path.Join(src, "config.d", "work"):          "Host work\n    HostName src-dir.example.com\n",
+	} {
+		if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := path.Join(src, "config")
+	input := "Include config.d/*\n"
+
+	got, err := Parser.Process("TEXT", input, Cmd.Args{ToSSH: true, Src: config})
+	if want := "Host work\n    HostName ssh-dir.example.com"; err != nil || string(got) != want {
+		t.Errorf("Process() = %q, %v, want %q", got, err, want)
+	}
+	got, err = Parser.Process("TEXT", input, Cmd.Args{ToSSH: true, Src: config, IncludeDir: src})
+	if want := "Host work\n    HostName src-dir.example.com"; err != nil || string(got) != want {
+		t.Errorf("Process(-include-dir) = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestProcess_InvalidDuplicatePolicy(t *testing.T) {
+	_, err := Parser.Process("TEXT", "Host a\n", Cmd.Args{ToSSH: true, Duplicates: "first"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported duplicate policy") {
+		t.Errorf("Process() error = %v, want unsupported duplicate policy", err)
+	}
+}
+
+func TestProcess_Strict(t *testing.T) {
+	yamlInput := "Group web:\n  Comon:\n    User: deploy\n"
+	if _, err := Parser.Process("YAML", yamlInput, Cmd.Args{ToSSH: true}); err != nil {
+		t.Errorf("Process() error = %v, want the field ignored without -strict", err)
+	}
+	_, err := Parser.Process("YAML", yamlInput, Cmd.Args{ToSSH: true, Strict: true})
+	if err == nil || err.Error() != `line 2: Group web.Comon: unknown field "Comon", did you mean Common?` {
+		t.Errorf("Process() error = %v", err)
+	}
+
+	jsonInput := `[{"Name": "web", "Data": {"ProxyJmp": "bastion"}}]`
+	_, err = Parser.Process("JSON", jsonInput, Cmd.Args{ToSSH: true, Strict: true})
+	if err == nil || err.Error() != `line 1: [0].Data.ProxyJmp: unknown keyword "ProxyJmp", did you mean ProxyJump?` {
+		t.Errorf("Process() error = %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	"gopkg.in/yaml.v2"
+)
+
+// PolicyRule is a rule of a policy file: the hosts it selects must pass
+// every assertion.
+//
+//	rules:
+//	  - id: TEAM001
+//	    description: production hosts use a dedicated key as a regular user
+//	    severity: error
+//	    select:
+//	      groups: [prod-*]
+//	    assert:
+//	      equals: {IdentitiesOnly: "yes"}
+//	      required: [User]
+//	      forbidden: [User=root]
+type PolicyRule struct {
+	ID          string           `yaml:"id"`
+	Description string           `yaml:"description,omitempty"`
+	Severity    LintSeverity     `yaml:"severity,omitempty"`
+	Select      PolicySelector   `yaml:"select,omitempty"`
+	Assert      PolicyAssertions `yaml:"assert"`
+}
+
+// PolicySelector picks the hosts a rule applies to, every host when empty.
+// Each list is matched like a Host line, with wildcards and negations, and
+// a host must match every list that is given.
+type PolicySelector struct {
+	// Groups match the YAML group, with or without its "Group " prefix.
+	Groups []string `yaml:"groups,omitempty"`
+	// Hosts match the patterns of the Host line.
+	Hosts []string `yaml:"hosts,omitempty"`
+	// Tags match the values of Tag.
+	Tags []string `yaml:"tags,omitempty"`
+	// Keys are keywords the host sets, all of them; "Key=pattern" also
+	// matches the value.
+	Keys []string `yaml:"keys,omitempty"`
+}
+
+// PolicyAssertions are the checks of a rule. Values are compared ignoring
+// case, and a keyword a host does not set is looked up in Host * and the
+// other blocks every host gets.
+type PolicyAssertions struct {
+	// Required keywords must be set.
+	Required []string `yaml:"required,omitempty"`
+	// Forbidden keywords must not be set, "Key=pattern" only forbids the
+	// values matching pattern.
+	Forbidden []string `yaml:"forbidden,omitempty"`
+	// Equals, Matches and OneOf check the value of a keyword, which must be
+	// set.
+	Equals  map[string]string   `yaml:"equals,omitempty"`
+	Matches map[string]string   `yaml:"matches,omitempty"`
+	OneOf   map[string][]string `yaml:"one-of,omitempty"`
+
+	patterns map[string]*regexp.Regexp
+}
+
+// ParsePolicyRules reads the rules of a YAML policy file.
+func ParsePolicyRules(data []byte) ([]PolicyRule, error) {
+	var file struct {
+		Rules []PolicyRule `yaml:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		switch {
+		case rule.ID == "":
+			return nil, fmt.Errorf("rule %d: missing id", i+1)
+		case seen[rule.ID] || findLintRule(rule.ID) != nil:
+			return nil, fmt.Errorf("rule %s: the id is already used", rule.ID)
+		}
+		seen[rule.ID] = true
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// compile checks the rule, writes its keywords with their canonical
+// spelling and compiles its regular expressions.
+func (r *PolicyRule) compile() error {
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityWarning
+	case SeverityError, SeverityWarning, SeverityInfo:
+	default:
+		return fmt.Errorf("unknown severity %q, use error, warning or info", r.Severity)
+	}
+
+	a := &r.Assert
+	if len(a.Required)+len(a.Forbidden)+len(a.Equals)+len(a.Matches)+len(a.OneOf) == 0 {
+		return fmt.Errorf("no assertion")
+	}
+	for _, list := range [][]string{r.Select.Keys, a.Required, a.Forbidden} {
+		for i, entry := range list {
+			key, pattern, hasPattern := strings.Cut(entry, "=")
+			list[i] = lintKeyword(strings.TrimSpace(key))
+			if hasPattern {
+				list[i] += "=" + strings.TrimSpace(pattern)
+			}
+		}
+	}
+	a.Equals = canonicalKeys(a.Equals)
+	a.OneOf = canonicalKeys(a.OneOf)
+	a.Matches = canonicalKeys(a.Matches)
+	a.patterns = make(map[string]*regexp.Regexp)
+	for key, expression := range a.Matches {
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return fmt.Errorf("matches %s: %w", key, err)
+		}
+		a.patterns[key] = pattern
+	}
+	return nil
+}
+
+func canonicalKeys[V any](m map[string]V) map[string]V {
+	canonical := make(map[string]V, len(m))
+	for key, value := range m {
+		canonical[lintKeyword(key)] = value
+	}
+	return canonical
+}
+
+// policySource returns the block key comes from for block: the block itself
+// or else the first block every host gets that sets it.
+func policySource(block LintBlock, blocks []LintBlock, key string) LintBlock {
+	if block.Host.HasKey(key) {
+		return block
+	}
+	for _, other := range blocks {
+		if other.AllHosts && other.Host.HasKey(key) {
+			return other
+		}
+	}
+	return block
+}
+
+// policyValues returns the values of key for block, nil when it is not set.
+func policyValues(block LintBlock, blocks []LintBlock, key string) []string {
+	return policySource(block, blocks, key).Host.Values(key)
+}
+
+// matchList reports whether one of names is selected by the patterns of
+// list, read like a Host line.
+func matchList(list []string, names ...string) bool {
+	patterns := Define.ParseHostPatterns(strings.Join(list, " "))
+	return slices.ContainsFunc(names, func(name string) bool { return Define.MatchHostPatterns(patterns, name) })
+}
+
+// selects reports whether the rule applies to block. Match blocks and the
+// blocks every host gets are not hosts, they are never selected.
+func (r *PolicyRule) selects(block LintBlock, blocks []LintBlock) bool {
+	if block.AllHosts || block.Host.IsMatch() {
+		return false
+	}
+	s := r.Select
+	if len(s.Groups) > 0 && (block.Group == "" || !matchList(s.Groups, block.Group, strings.TrimPrefix(block.Group, "Group "))) {
+		return false
+	}
+	if len(s.Hosts) > 0 {
+		var names []string
+		for _, pattern := range Define.ParseHostPatterns(block.Host.Name) {
+			if !pattern.Negate {
+				names = append(names, pattern.Pattern)
+			}
+		}
+		if !matchList(s.Hosts, names...) {
+			return false
+		}
+	}
+	if len(s.Tags) > 0 && !matchList(s.Tags, policyValues(block, blocks, "Tag")...) {
+		return false
+	}
+	for _, entry := range s.Keys {
+		key, pattern, hasPattern := strings.Cut(entry, "=")
+		values := policyValues(block, blocks, key)
+		if values == nil || hasPattern && !slices.ContainsFunc(values, func(value string) bool { return Define.MatchPattern(pattern, value) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// check returns a finding for every assertion block fails.
+func (r *PolicyRule) check(block LintBlock, blocks []LintBlock) []LintFinding {
+	if !r.selects(block, blocks) {
+		return nil
+	}
+	rule := &LintRule{ID: r.ID, Severity: r.Severity, Summary: r.Description}
+	var findings []LintFinding
+	// findings point at the value, which may be inherited from Host *
+	report := func(key, format string, args ...any) {
+		finding := policySource(block, blocks, key).finding(rule, key, format, args...)
+		finding.Label = block.Label
+		if r.Description != "" {
+			finding.Message += ": " + r.Description
+		}
+		findings = append(findings, finding)
+	}
+
+	a := r.Assert
+	for _, key := range a.Required {
+		if policyValues(block, blocks, key) == nil {
+			report(key, "%s is not set", key)
+		}
+	}
+	for _, entry := range a.Forbidden {
+		key, pattern, hasPattern := strings.Cut(entry, "=")
+		for _, value := range policyValues(block, blocks, key) {
+			if !hasPattern {
+				report(key, "%s is set", key)
+				break
+			}
+			if Define.MatchPattern(pattern, value) {
+				report(key, "%s is %q", key, value)
+				break
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.Equals)) {
+		want := a.Equals[key]
+		checkPolicyValues(block, blocks, key, fmt.Sprintf("%q", want), report, func(value string) bool {
+			return strings.EqualFold(value, want)
+		})
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.Matches)) {
+		pattern := a.patterns[key]
+		checkPolicyValues(block, blocks, key, "a value matching "+pattern.String(), report, pattern.MatchString)
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.OneOf)) {
+		choices := a.OneOf[key]
+		checkPolicyValues(block, blocks, key, "one of "+strings.Join(choices, ", "), report, func(value string) bool {
+			return slices.ContainsFunc(choices, func(choice string) bool { return strings.EqualFold(value, choice) })
+		})
+	}
+	return findings
+}
+
+// checkPolicyValues reports key when it is not set or one of its values
+// fails ok.
+func checkPolicyValues(block LintBlock, blocks []LintBlock, key, expected string, report func(key, format string, args ...any), ok func(string) bool) {
+	values := policyValues(block, blocks, key)
+	if values == nil {
+		report(key, "%s is not set, expected %s", key, expected)
+		return
+	}
+	for _, value := range values {
+		if !ok(value) {
+			report(key, "%s is %q, expected %s", key, value, expected)
+			return
+		}
+	}
+}
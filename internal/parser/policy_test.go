@@ -0,0 +1,122 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+const teamPolicy = `
+rules:
+  - id: TEAM001
+    description: production hosts use a dedicated key as a regular user
+    severity: error
+    select:
+      groups: [prod-*]
+    assert:
+      equals: {identitiesonly: "yes"}
+      required: [User]
+      forbidden: [User=root]
+  - id: TEAM002
+    select:
+      tags: [critical]
+      keys: [ProxyJump]
+    assert:
+      one-of: {StrictHostKeyChecking: ["yes", ask]}
+      matches: {ProxyJump: '^bastion\.'}
+`
+
+const teamHosts = `global:
+  StrictHostKeyChecking: accept-new
+Group prod-db:
+  Common:
+    User: root
+  Hosts:
+    db1:
+      config:
+        HostName: 10.0.0.1
+        IdentitiesOnly: "yes"
+    db2:
+      config:
+        User: postgres
+        Tag: critical
+        ProxyJump: jump.example.com
+Group staging:
+  Hosts:
+    web:
+      config:
+        Tag: critical
+`
+
+func TestLintHostConfigs_Policy(t *testing.T) {
+	rules, err := Parser.ParsePolicyRules([]byte(teamPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := lintOptions
+	options.Rules = rules
+	findings := Parser.LintHostConfigs(Parser.GroupYAMLConfig(teamHosts), teamHosts, options)
+	want := []string{
+		`2: warning: Host db2: StrictHostKeyChecking is "accept-new", expected one of yes, ask (TEAM002)`,
+		`5: error: Host db1: User is "root": production hosts use a dedicated key as a regular user (TEAM001)`,
+		`11: error: Host db2: IdentitiesOnly is not set, expected "yes": production hosts use a dedicated key as a regular user (TEAM001)`,
+		`15: warning: Host db2: ProxyJump is "jump.example.com", expected a value matching ^bastion\. (TEAM002)`,
+	}
+	if got := findingStrings(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("LintHostConfigs() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParsePolicyRules_Errors(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"rules:\n  - assert: {required: [User]}\n", "rule 1: missing id"},
+		{"rules:\n  - id: SSH001\n    assert: {required: [User]}\n", "rule SSH001: the id is already used"},
+		{"rules:\n  - id: A\n    severity: fatal\n    assert: {required: [User]}\n", `rule A: unknown severity "fatal", use error, warning or info`},
+		{"rules:\n  - id: A\n", "rule A: no assertion"},
+		{"rules:\n  - id: A\n    assert: {matches: {User: '('}}\n", "rule A: matches User: error parsing regexp: missing closing ): `(`"},
+		{"rules:\n  - id: A\n    assert: {required: [User]}\n    when: {}\n", "field when not found"},
+	}
+	for _, tt := range tests {
+		if _, err := Parser.ParsePolicyRules([]byte(tt.input)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParsePolicyRules(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestProcess_LintPolicy(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policy, []byte(teamPolicy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := Cmd.Args{Command: Cmd.CommandLint, Policy: policy}
+	result, err := Parser.Process("YAML", teamHosts, args)
+	if err == nil || err.Error() != "found 4 problems" || !strings.Contains(string(result), "(TEAM002)") {
+		t.Errorf("Process() = %q, %v", result, err)
+	}
+
+	args.Policy = filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := Parser.Process("YAML", teamHosts, args); err == nil {
+		t.Error("Process() expected an error for a missing policy file")
+	}
+}
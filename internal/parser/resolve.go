@@ -0,0 +1,284 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+// Destination is the target of a connection, User and Port are empty
+// unless they were given.
+type Destination struct {
+	User string
+	Host string
+	Port string
+}
+
+// ParseDestination reads a destination the way ssh accepts it:
+// [user@]host, [user@]host:port, [user@][address]:port or
+// ssh://[user@]host[:port].
+func ParseDestination(destination string) (Destination, error) {
+	var d Destination
+	if strings.HasPrefix(destination, "ssh://") {
+		u, err := url.Parse(destination)
+		if err != nil {
+			return d, fmt.Errorf("invalid destination %q: %w", destination, err)
+		}
+		if u.Path != "" && u.Path != "/" || u.RawQuery != "" || u.Fragment != "" {
+			return d, fmt.Errorf("invalid destination %q: only user, host and port are allowed", destination)
+		}
+		d.Host, d.Port = u.Hostname(), u.Port()
+		if u.User != nil {
+			d.User = u.User.Username()
+		}
+	} else {
+		rest := destination
+		if at := strings.LastIndex(rest, "@"); at >= 0 {
+			d.User, rest = rest[:at], rest[at+1:]
+		}
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return d, fmt.Errorf("invalid destination %q: missing ']'", destination)
+			}
+			d.Host = rest[1:end]
+			if port, ok := strings.CutPrefix(rest[end+1:], ":"); ok {
+				d.Port = port
+			} else if rest[end+1:] != "" {
+				return d, fmt.Errorf("invalid destination %q: unexpected %q", destination, rest[end+1:])
+			}
+		case strings.Count(rest, ":") == 1:
+			d.Host, d.Port, _ = strings.Cut(rest, ":")
+		default:
+			// no colon, or a bare IPv6 address
+			d.Host = rest
+		}
+	}
+
+	if d.Host == "" {
+		return d, fmt.Errorf("invalid destination %q: missing host", destination)
+	}
+	if d.Port != "" {
+		if port, err := strconv.Atoi(d.Port); err != nil || port < 1 || port > 65535 {
+			return d, fmt.Errorf("invalid destination %q: bad port %q", destination, d.Port)
+		}
+	}
+	return d, nil
+}
+
+// Resolve returns the settings ssh uses to connect to destination, like
+// ssh -G: the blocks of hostConfigs are read in order, Host patterns are
+// matched against the host as given, Match blocks are evaluated in
+// options.MatchEnv and the first value of a keyword wins. A Match line that
+// can not be evaluated is skipped with a warning. HostName, Port and User
+// get their defaults when no block sets them.
+func Resolve(hostConfigs []Define.HostConfig, destination Destination, options Options) Define.HostConfig {
+	// values given on the command line come first
+	group := SSHHostConfigGroup{Config: make(map[string]string)}
+	if destination.User != "" {
+		addDirective(&group, "User", destination.User)
+	}
+	if destination.Port != "" {
+		addDirective(&group, "Port", destination.Port)
+	}
+	if options.MatchEnv.Tag != "" {
+		addDirective(&group, "Tag", options.MatchEnv.Tag)
+	}
+
+	// the final pass reads the blocks again, report its problems once
+	reported := make(map[string]bool)
+	warn := options.Warn
+	options.Warn = func(message string) {
+		if !reported[message] && warn != nil {
+			warn(message)
+		}
+		reported[message] = true
+	}
+
+	env := options.MatchEnv
+	if final := resolvePass(&group, hostConfigs, destination, env, options); final && !env.Final {
+		// "Match final" makes ssh read the config again once it is done
+		env.Final = true
+		resolvePass(&group, hostConfigs, destination, env, options)
+	}
+
+	resolved := Define.HostConfig{Name: destination.Host, Config: group.Config, Lists: group.Lists}
+	if name, ok := findKey(resolved, "HostName"); ok && !options.Expand {
+		// HostName only knows %h, the host as given
+		context := options.ExpandContext
+		context.Host = destination.Host
+		hostName, _ := expand.Expand("HostName", resolved.Config[name], context)
+		resolved.Config[name] = hostName
+	} else if !ok {
+		resolved.Config["HostName"] = destination.Host
+	}
+	if _, ok := findKey(resolved, "Port"); !ok {
+		resolved.Config["Port"] = "22"
+	}
+	if _, ok := findKey(resolved, "User"); !ok && options.ExpandContext.LocalUser != "" {
+		resolved.Config["User"] = options.ExpandContext.LocalUser
+	}
+
+	if options.Expand {
+		resolved = ExpandHostConfigs([]Define.HostConfig{resolved}, options)[0]
+	}
+	return resolved
+}
+
+// resolvePass adds the values of the blocks that apply to destination to
+// group, and reports whether a Match line uses the final criterion.
+func resolvePass(group *SSHHostConfigGroup, hostConfigs []Define.HostConfig, destination Destination, env MatchEnv, options Options) (final bool) {
+	for _, hostConfig := range hostConfigs {
+		switch {
+		case hostConfig.IsInclude():
+			options.warn(fmt.Sprintf("Include %s is not followed", strings.Join(hostConfig.Include, " ")))
+			continue
+		case hostConfig.IsMatch():
+			final = final || slices.ContainsFunc(hostConfig.Match, func(condition Define.MatchCondition) bool {
+				return condition.Criterion == "final"
+			})
+			matched, err := EvaluateMatch(hostConfig.Match, matchTarget(*group, destination, options), env)
+			if err != nil {
+				options.warn(fmt.Sprintf("Match %s: %v, the block is skipped", FormatMatchConditions(hostConfig.Match), err))
+				continue
+			}
+			if !matched {
+				continue
+			}
+		case hostConfig.Name != "" && !Define.MatchHostPatterns(hostConfig.HostPatterns(), destination.Host):
+			continue
+		}
+		orderMaps := Fn.GetOrderConfig(hostConfig.Config, hostConfig.Lists)
+		for _, key := range orderMaps.Keys {
+			for _, value := range orderMaps.Values(key) {
+				// ssh drops a value its list already holds, which the
+				// final pass reads again
+				if env.Final && Define.IsMultiValueKeyword(key) && slices.Contains(groupValues(*group, key), value) {
+					continue
+				}
+				addDirective(group, key, value)
+			}
+		}
+	}
+	return final
+}
+
+// groupValues returns the values group holds for key, in any spelling.
+func groupValues(group SSHHostConfigGroup, key string) []string {
+	current := Define.HostConfig{Config: group.Config, Lists: group.Lists}
+	name, _ := findKey(current, key)
+	return current.Values(name)
+}
+
+// matchTarget describes destination with the values obtained so far, which
+// is what a Match line is evaluated against.
+func matchTarget(group SSHHostConfigGroup, destination Destination, options Options) MatchTarget {
+	current := Define.HostConfig{Name: destination.Host, Config: group.Config, Lists: group.Lists}
+	context := HostContext(current, options.ExpandContext)
+	return MatchTarget{
+		Host:         context.Host,
+		OriginalHost: destination.Host,
+		User:         context.RemoteUser,
+		Tag:          hostValue(current, "Tag"),
+		Context:      context,
+	}
+}
+
+// SSHOrder returns hostConfigs in the order ConvertToSSHWithOptions writes
+// them, which is the order ssh reads them in.
+func SSHOrder(hostConfigs []Define.HostConfig, options Options) []Define.HostConfig {
+	if options.KeepOrder {
+		return hostConfigs
+	}
+	ordered := Fn.FindIncludeConfig(hostConfigs)
+	ordered = append(ordered, Fn.FindGlobalConfig(hostConfigs)...)
+	ordered = append(ordered, Fn.FindNormalConfig(hostConfigs)...)
+	return append(ordered, Fn.FindMatchConfig(hostConfigs)...)
+}
+
+// FormatResolved renders a resolved config like ssh -G: "host" first, then
+// user, hostname and port, then the other keywords sorted, lower-cased, one
+// value per line.
+func FormatResolved(hostConfig Define.HostConfig) []byte {
+	leading := []string{"user", "hostname", "port"}
+	orderMaps := Fn.GetOrderConfig(hostConfig.Config, hostConfig.Lists)
+	keys := slices.Clone(orderMaps.Keys)
+	slices.SortStableFunc(keys, func(a, b string) int {
+		a, b = strings.ToLower(a), strings.ToLower(b)
+		ia, ib := slices.Index(leading, a), slices.Index(leading, b)
+		switch {
+		case ia >= 0 && ib >= 0:
+			return ia - ib
+		case ia >= 0:
+			return -1
+		case ib >= 0:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	lines := []string{"host " + hostConfig.Name}
+	for _, key := range keys {
+		for _, value := range orderMaps.Values(key) {
+			lines = append(lines, strings.ToLower(key)+" "+value)
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// processResolve runs the resolve command: the settings of the destination
+// in Operands, as ssh -G text unless YAML or JSON is asked for.
+func processResolve(fileType string, userInput string, args Cmd.Args, options Options) ([]byte, error) {
+	destination, err := ParseDestination(args.Operands[0])
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.EqualFold(fileType, "TEXT")
+	if text {
+		// resolve against the blocks exactly as written
+		options.KeepOrder = true
+		options.Duplicates = DuplicateKeep
+	}
+	hostConfigs, err := groupInput(fileType, userInput, args, options)
+	if err != nil {
+		return nil, err
+	}
+	if !text {
+		hostConfigs = SSHOrder(hostConfigs, options)
+	}
+
+	resolved := Resolve(hostConfigs, destination, options)
+	switch {
+	case args.ToYAML:
+		return Fn.TidyLastEmptyLines(ConvertToYAML([]Define.HostConfig{resolved})), nil
+	case args.ToJSON:
+		return Fn.TidyLastEmptyLines(ConvertToJSON([]Define.HostConfig{resolved})), nil
+	default:
+		return FormatResolved(resolved), nil
+	}
+}
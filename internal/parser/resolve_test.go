@@ -0,0 +1,247 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+	"github.com/soulteary/ssh-config/v2/pkg/expand"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Parser.Destination
+		wantErr bool
+	}{
+		{input: "work", want: Parser.Destination{Host: "work"}},
+		{input: "deploy@work", want: Parser.Destination{User: "deploy", Host: "work"}},
+		{input: "a@b@work:2222", want: Parser.Destination{User: "a@b", Host: "work", Port: "2222"}},
+		{input: "[::1]:22", want: Parser.Destination{Host: "::1", Port: "22"}},
+		{input: "root@fe80::1", want: Parser.Destination{User: "root", Host: "fe80::1"}},
+		{input: "ssh://deploy@work.example.com:2200", want: Parser.Destination{User: "deploy", Host: "work.example.com", Port: "2200"}},
+		{input: "ssh://work/path", wantErr: true},
+		{input: "deploy@", wantErr: true},
+		{input: "work:http", wantErr: true},
+		{input: "work:0", wantErr: true},
+		{input: "[::1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parser.ParseDestination(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDestination(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseDestination(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+const resolveInput = `User everyone
+
+Host *.corp !legacy.corp
+    User corp
+    IdentityFile ~/.ssh/corp
+
+Host web? db
+    HostName %h.example.com
+    IdentityFile ~/.ssh/web
+
+Match all
+    Compression yes
+
+Match exec "test -f /tmp/vpn"
+    Port 2200
+
+Host *
+    Port 2222
+    ForwardAgent no
+`
+
+func TestResolve(t *testing.T) {
+	hostConfigs, err := Parser.GroupSSHConfigWithOptions(resolveInput, Parser.Options{KeepOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		destination string
+		want        Define.HostConfig
+	}{
+		{
+			destination: "web1.corp",
+			want: Define.HostConfig{
+				Name:   "web1.corp",
+				Config: map[string]string{"User": "everyone", "HostName": "web1.corp", "Port": "2222", "ForwardAgent": "no", "Compression": "yes", "IdentityFile": "~/.ssh/corp"},
+			},
+		},
+		{
+			destination: "web1",
+			want: Define.HostConfig{
+				Name:   "web1",
+				Config: map[string]string{"User": "everyone", "HostName": "web1.example.com", "Port": "2222", "ForwardAgent": "no", "Compression": "yes", "IdentityFile": "~/.ssh/web"},
+			},
+		},
+		{
+			destination: "ops@legacy.corp:22",
+			want: Define.HostConfig{
+				Name:   "legacy.corp",
+				Config: map[string]string{"User": "ops", "HostName": "legacy.corp", "Port": "22", "ForwardAgent": "no", "Compression": "yes"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		destination, err := Parser.ParseDestination(tt.destination)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var warnings []string
+		got := Parser.Resolve(hostConfigs, destination, Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Resolve(%s) = %+v, want %+v", tt.destination, got, tt.want)
+		}
+		if want := []string{`Match exec "test -f /tmp/vpn": exec criteria are not run, the block is skipped`}; !reflect.DeepEqual(warnings, want) {
+			t.Errorf("Resolve(%s) warnings = %q, want %q", tt.destination, warnings, want)
+		}
+	}
+}
+
+func TestResolve_MatchFinal(t *testing.T) {
+	input := `Host db
+    IdentityFile ~/.ssh/db
+    LocalForward 8080 localhost:80
+Match final
+    IdentityFile ~/.ssh/final
+Host *
+    IdentityFile ~/.ssh/default
+`
+	hostConfigs, err := Parser.GroupSSHConfigWithOptions(input, Parser.Options{KeepOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Parser.Resolve(hostConfigs, Parser.Destination{Host: "db"}, Parser.Options{})
+	if want := []string{"~/.ssh/db", "~/.ssh/default", "~/.ssh/final"}; !reflect.DeepEqual(got.Values("IdentityFile"), want) {
+		t.Errorf("IdentityFile = %q, want %q", got.Values("IdentityFile"), want)
+	}
+	if want := []string{"8080 localhost:80"}; !reflect.DeepEqual(got.Values("LocalForward"), want) {
+		t.Errorf("LocalForward = %q, want %q", got.Values("LocalForward"), want)
+	}
+}
+
+func TestResolve_DefaultsAndExpand(t *testing.T) {
+	hostConfigs := []Define.HostConfig{
+		{Name: "*", Config: map[string]string{"ControlPath": "~/.ssh/cm-%r@%h:%p"}},
+		{Name: "dev", Lists: map[string][]string{"IdentityFile": {"%d/a", "%d/b"}}},
+	}
+	options := Parser.Options{ExpandContext: expand.Context{LocalUser: "alice", HomeDir: "/home/alice"}}
+
+	got := Parser.Resolve(hostConfigs, Parser.Destination{Host: "dev"}, options)
+	if got.Config["User"] != "alice" || got.Config["Port"] != "22" || got.Config["ControlPath"] != "~/.ssh/cm-%r@%h:%p" {
+		t.Errorf("Resolve() = %+v", got)
+	}
+
+	options.Expand = true
+	got = Parser.Resolve(hostConfigs, Parser.Destination{Host: "dev"}, options)
+	if want := "~/.ssh/cm-alice@dev:22"; got.Config["ControlPath"] != want {
+		t.Errorf("ControlPath = %q, want %q", got.Config["ControlPath"], want)
+	}
+	if want := []string{"/home/alice/a", "/home/alice/b"}; !reflect.DeepEqual(got.Lists["IdentityFile"], want) {
+		t.Errorf("IdentityFile = %v, want %v", got.Lists["IdentityFile"], want)
+	}
+}
+
+func TestSSHOrder(t *testing.T) {
+	hostConfigs := []Define.HostConfig{
+		{Name: "work"},
+		{Match: []Define.MatchCondition{{Criterion: "all"}}},
+		{Name: "*"},
+		{Include: []string{"config.d/*"}},
+	}
+	want := []Define.HostConfig{hostConfigs[3], hostConfigs[2], hostConfigs[0], hostConfigs[1]}
+	if got := Parser.SSHOrder(hostConfigs, Parser.Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("SSHOrder() = %+v, want %+v", got, want)
+	}
+	if got := Parser.SSHOrder(hostConfigs, Parser.Options{KeepOrder: true}); !reflect.DeepEqual(got, hostConfigs) {
+		t.Errorf("SSHOrder(KeepOrder) = %+v, want the input order", got)
+	}
+}
+
+func TestFormatResolved(t *testing.T) {
+	got := Parser.FormatResolved(Define.HostConfig{
+		Name:   "web1",
+		Config: map[string]string{"Port": "22", "ForwardAgent": "no", "HostName": "web1.example.com", "User": "deploy"},
+		Lists:  map[string][]string{"IdentityFile": {"~/.ssh/a", "~/.ssh/b"}},
+	})
+	want := `host web1
+user deploy
+hostname web1.example.com
+port 22
+forwardagent no
+identityfile ~/.ssh/a
+identityfile ~/.ssh/b`
+	if string(got) != want {
+		t.Errorf("FormatResolved() = %q, want %q", got, want)
+	}
+}
+
+func TestProcess_Resolve(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"deploy@db"}}
+	got, err := Parser.Process("TEXT", resolveInput, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `host db
+user deploy
+hostname db.example.com
+port 2222
+compression yes
+forwardagent no
+identityfile ~/.ssh/web`
+	if string(got) != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+
+	// converted configs are read in the order -to-ssh writes them, Host * first
+	yamlInput := string(Parser.ConvertToYAML(mustGroup(t, resolveInput)))
+	args.ToJSON = true
+	got, err = Parser.Process("YAML", yamlInput, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `[{"Name":"db","Data":{"Compression":"yes","ForwardAgent":"no","HostName":"db.example.com","IdentityFile":"~/.ssh/web","Port":"2222","User":"deploy"}}]`
+	if string(got) != want {
+		t.Errorf("Process() = %s, want %s", got, want)
+	}
+
+	if _, err := Parser.Process("TEXT", resolveInput, Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"db:port"}}); err == nil {
+		t.Error("Process() expected an error for an invalid destination")
+	}
+}
+
+func mustGroup(t *testing.T, input string) []Define.HostConfig {
+	t.Helper()
+	hostConfigs, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hostConfigs
+}
@@ -17,6 +17,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
@@ -30,135 +31,443 @@ import (
 type SSHHostConfigGroup struct {
 	Comments []string
 	Config   map[string]string
+	// Lists holds repeatable directives given more than once, in source order.
+	Lists map[string][]string
+}
+
+// SSHConfigBlock is a Host or Match block, or an Include line outside of
+// any block, in the order it appears in the input.
+type SSHConfigBlock struct {
+	Host    string
+	Match   []Define.MatchCondition
+	Include []string
+	// Line is where the block starts in the input, 0 for the unnamed block.
+	Line int
+	// Shadowed lists the repeated directives ssh ignores in this block.
+	Shadowed []ShadowedValue
+	SSHHostConfigGroup
+}
+
+// ShadowedValue is a directive given again after its keyword already has a
+// value: ssh keeps the first one, Kept.
+type ShadowedValue struct {
+	Key   string
+	Value string
+	Kept  string
+	Line  int
+}
+
+// Label names the block in messages.
+func (b SSHConfigBlock) Label() string {
+	switch {
+	case b.IsMatch():
+		return "Match " + FormatMatchConditions(b.Match)
+	case b.IsInclude():
+		return "Include " + strings.Join(b.Include, " ")
+	case b.Host == "":
+		return "directives before the first Host"
+	default:
+		return "Host " + b.Host
+	}
+}
+
+func (b SSHConfigBlock) IsMatch() bool {
+	return len(b.Match) > 0
+}
+
+func (b SSHConfigBlock) IsInclude() bool {
+	return len(b.Include) > 0
+}
+
+// isUnnamed reports whether b holds directives written outside of any Host line.
+func (b SSHConfigBlock) isUnnamed() bool {
+	return b.Host == "" && !b.IsMatch() && !b.IsInclude()
 }
 
 func GroupSSHConfigFromString(input string) (map[string]SSHHostConfigGroup, error) {
-	tokens, err := lexer.Lex(input)
+	blocks, err := GroupSSHBlocksFromString(input)
 	if err != nil {
 		return nil, err
 	}
-	return groupFromTokens(tokens), nil
+	return groupBlocks(blocks), nil
 }
 
-// groupFromTokens builds SSHHostConfigGroup map from a lexer token stream.
-func groupFromTokens(tokens []lexer.Token) map[string]SSHHostConfigGroup {
-	hostConfigs := make(map[string]SSHHostConfigGroup)
-	var currentHost string
-	var currentComments []string
-	i := 0
-	next := func() (lexer.Token, bool) {
-		if i >= len(tokens) {
-			return lexer.Token{Kind: lexer.TokenEOF}, false
+// GroupSSHBlocksFromString reports every lexer problem of input at once,
+// each with its line and column.
+func GroupSSHBlocksFromString(input string) ([]SSHConfigBlock, error) {
+	tokens, diagnostics := lexer.LexAll(input)
+	blocks, err := blocksFromTokens(tokens)
+	if len(diagnostics) > 0 {
+		errs := make([]error, 0, len(diagnostics)+1)
+		for _, diagnostic := range diagnostics {
+			errs = append(errs, diagnostic)
 		}
-		t := tokens[i]
-		i++
-		return t, true
+		return nil, errors.Join(append(errs, err)...)
 	}
-	peek := func() (lexer.Token, bool) {
-		if i >= len(tokens) {
-			return lexer.Token{Kind: lexer.TokenEOF}, false
+	return blocks, err
+}
+
+// groupBlocks indexes the Host blocks by their patterns, duplicates merged
+// the way ssh reads them; Match blocks and Include lines are left out.
+func groupBlocks(blocks []SSHConfigBlock) map[string]SSHHostConfigGroup {
+	blocks, _ = resolveDuplicates(blocks, Options{Duplicates: DuplicateMerge})
+	hostConfigs := make(map[string]SSHHostConfigGroup)
+	for _, block := range blocks {
+		if block.IsMatch() || block.IsInclude() {
+			continue
 		}
-		return tokens[i], true
+		// the values a block in between kept apart, the map has no order
+		if earlier, ok := hostConfigs[block.Host]; ok {
+			hostConfigs[block.Host] = mergeGroups(earlier, block.SSHHostConfigGroup)
+			continue
+		}
+		hostConfigs[block.Host] = block.SSHHostConfigGroup
 	}
+	return hostConfigs
+}
 
-	for {
-		tok, ok := next()
-		if !ok || tok.Kind == lexer.TokenEOF {
-			break
+// resolveDuplicates applies the duplicate policy to Host blocks sharing the
+// same patterns. Merged blocks take the place of the first one, except for
+// the values a block in between may also set for these hosts: ssh would read
+// that block first, so they stay in a block where the duplicate was.
+func resolveDuplicates(blocks []SSHConfigBlock, options Options) ([]SSHConfigBlock, error) {
+	first := make(map[string]int)
+	resolved := make([]SSHConfigBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if block.IsMatch() || block.IsInclude() {
+			resolved = append(resolved, block)
+			continue
 		}
-		switch tok.Kind {
-		case lexer.TokenNewline:
+		index, ok := first[block.Host]
+		if !ok {
+			first[block.Host] = len(resolved)
+			resolved = append(resolved, block)
 			continue
-		case lexer.TokenComment:
-			currentComments = append(currentComments, tok.Value)
-		case lexer.TokenKeyword:
-			kw := strings.ToLower(tok.Value)
-			switch kw {
-			case "host":
-				var parts []string
-				for {
-					t, _ := peek()
-					if t.Kind != lexer.TokenValue && t.Kind != lexer.TokenQuoted {
-						break
-					}
-					next()
-					parts = append(parts, t.Value)
-				}
-				currentHost = strings.TrimSpace(strings.Join(parts, " "))
-				if currentHost != "" {
-					hostConfigs[currentHost] = SSHHostConfigGroup{
-						Comments: currentComments,
-						Config:   make(map[string]string),
-					}
-					currentComments = nil
-				}
-			case "include", "match":
-				for {
-					t, _ := peek()
-					if t.Kind == lexer.TokenNewline || t.Kind == lexer.TokenEOF {
-						break
-					}
-					next()
-				}
-			}
-		case lexer.TokenIdent:
-			key := tok.Value
-			var valueParts []string
-			// optional Equals
-			if t, _ := peek(); t.Kind == lexer.TokenEquals {
-				next()
-			}
-			for {
-				t, _ := peek()
-				if t.Kind != lexer.TokenValue && t.Kind != lexer.TokenQuoted {
-					break
-				}
-				next()
-				valueParts = append(valueParts, t.Value)
+		}
+
+		message := fmt.Sprintf("duplicate Host block %q at line %d, first defined at line %d", block.Host, block.Line, resolved[index].Line)
+		switch options.duplicates() {
+		case DuplicateError:
+			return nil, fmt.Errorf("%s", message)
+		case DuplicateKeep:
+			resolved = append(resolved, block)
+			continue
+		}
+		moved, kept, keys := splitDuplicate(resolved[index], block, resolved[index+1:])
+		if options.duplicates() == DuplicateWarn {
+			message += ", merged with ssh first-value-wins semantics"
+			if len(keys) > 0 {
+				message += fmt.Sprintf(", %s stay at line %d as a block in between may set them first", strings.Join(keys, ", "), block.Line)
 			}
-			value := strings.TrimSpace(strings.Join(valueParts, " "))
-			// Allow empty values per ssh_config(5): first obtained value is used; empty is valid.
-			if hostConfigs[currentHost].Config == nil {
-				cfg := hostConfigs[currentHost]
-				cfg.Config = make(map[string]string)
-				hostConfigs[currentHost] = cfg
+			options.warn(message)
+		}
+		resolved[index].SSHHostConfigGroup = mergeGroups(resolved[index].SSHHostConfigGroup, moved.SSHHostConfigGroup)
+		if len(keys) > 0 {
+			resolved = append(resolved, kept)
+		}
+	}
+	return resolved, nil
+}
+
+// splitDuplicate splits the values of a duplicate Host block into those
+// that can move to the first block and those a block in between may set
+// first for the same hosts, which keep their place; keys names the latter.
+func splitDuplicate(first, duplicate SSHConfigBlock, between []SSHConfigBlock) (moved, kept SSHConfigBlock, keys []string) {
+	moved = SSHConfigBlock{SSHHostConfigGroup: SSHHostConfigGroup{Comments: duplicate.Comments}}
+	kept = SSHConfigBlock{Host: duplicate.Host, Line: duplicate.Line}
+	orderMaps := Fn.GetOrderConfig(duplicate.Config, duplicate.Lists)
+	for _, key := range orderMaps.Keys {
+		// ssh ignores the value anyway, the first block sets the keyword
+		ignored := hasKey(first.SSHHostConfigGroup, key) && !Define.IsMultiValueKeyword(key)
+		target := &moved
+		if !ignored && slices.ContainsFunc(between, func(other SSHConfigBlock) bool {
+			return hasKey(other.SSHHostConfigGroup, key) && mayApply(other, duplicate.Host)
+		}) {
+			target = &kept
+			keys = append(keys, key)
+		}
+		for _, value := range orderMaps.Values(key) {
+			addDirective(&target.SSHHostConfigGroup, key, value)
+		}
+	}
+	return moved, kept, keys
+}
+
+// hasKey reports whether group sets the option of key, aliases included.
+func hasKey(group SSHHostConfigGroup, key string) bool {
+	id := Define.KeywordID(key)
+	for other := range group.Config {
+		if Define.KeywordID(other) == id {
+			return true
+		}
+	}
+	for other := range group.Lists {
+		if Define.KeywordID(other) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mayApply reports whether the values of block may apply to a host the
+// patterns of a Host line select: a Match block may apply to any host, a
+// Host block to the hosts its patterns match.
+func mayApply(block SSHConfigBlock, host string) bool {
+	switch {
+	case block.IsMatch():
+		return true
+	case block.IsInclude():
+		return false
+	}
+	patterns := Define.ParseHostPatterns(block.Host)
+	for _, name := range strings.Fields(host) {
+		if !Define.IsConcreteHost(name) || Define.MatchHostPatterns(patterns, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeGroups appends the directives of later to earlier: single-valued
+// keywords keep the first value, repeatable ones collect every value.
+func mergeGroups(earlier SSHHostConfigGroup, later SSHHostConfigGroup) SSHHostConfigGroup {
+	merged := SSHHostConfigGroup{
+		Comments: append(slices.Clone(earlier.Comments), later.Comments...),
+		Config:   make(map[string]string, len(earlier.Config)),
+	}
+	for key, value := range earlier.Config {
+		merged.Config[key] = value
+	}
+	for key, values := range earlier.Lists {
+		if merged.Lists == nil {
+			merged.Lists = make(map[string][]string)
+		}
+		merged.Lists[key] = slices.Clone(values)
+	}
+
+	orderMaps := Fn.GetOrderConfig(later.Config, later.Lists)
+	for _, key := range orderMaps.Keys {
+		for _, value := range orderMaps.Values(key) {
+			addDirective(&merged, key, value)
+		}
+	}
+	return merged
+}
+
+// blocksFromTokens builds the Host and Match blocks from a lexer token stream.
+// Directives outside of any Host line all belong to the first unnamed block.
+func blocksFromTokens(tokens []lexer.Token) ([]SSHConfigBlock, error) {
+	index := 0
+	scanner := newBlockScanner(func() (lexer.Token, error) {
+		if index >= len(tokens) {
+			return lexer.Token{Kind: lexer.TokenEOF}, nil
+		}
+		index++
+		return tokens[index-1], nil
+	})
+
+	return collectBlocks(scanner)
+}
+
+// addDirective stores a directive of a block, repeatable ones are collected in
+// Lists. For any other keyword ssh uses the first value it obtains, so a later
+// one is ignored: addDirective then returns the value in use and false.
+func addDirective(cfg *SSHHostConfigGroup, key string, value string) (string, bool) {
+	if cfg.Config == nil {
+		cfg.Config = make(map[string]string)
+	}
+	id := Define.KeywordID(key)
+	for name, values := range cfg.Lists {
+		if Define.KeywordID(name) == id {
+			cfg.Lists[name] = append(values, value)
+			return value, true
+		}
+	}
+	for name, first := range cfg.Config {
+		if Define.KeywordID(name) != id {
+			continue
+		}
+		if !Define.IsMultiValueKeyword(key) {
+			return first, false
+		}
+		if cfg.Lists == nil {
+			cfg.Lists = make(map[string][]string)
+		}
+		cfg.Lists[name] = []string{first, value}
+		delete(cfg.Config, name)
+		return value, true
+	}
+	cfg.Config[key] = value
+	return value, true
+}
+
+// parseMatchConditions reads the criteria of a Match line, e.g. "host *.corp !exec "test -f x"".
+func parseMatchConditions(args []lexer.Token, keyword lexer.Token) ([]Define.MatchCondition, error) {
+	if len(args) == 0 {
+		return nil, lexer.Diagnostic{Line: keyword.Line, Column: keyword.Column, Message: "match without criteria"}
+	}
+	var conditions []Define.MatchCondition
+	for index := 0; index < len(args); index++ {
+		word := args[index].Value
+		condition := Define.MatchCondition{}
+		if strings.HasPrefix(word, "!") {
+			condition.Negate = true
+			word = word[1:]
+		}
+		condition.Criterion = strings.ToLower(word)
+		if !slices.Contains(Define.MatchCriteria, condition.Criterion) {
+			return nil, lexer.Diagnostic{Line: args[index].Line, Column: args[index].Column,
+				Message: fmt.Sprintf("unsupported match criterion %q", word)}
+		}
+		if !slices.Contains(Define.MatchCriteriaWithoutArgument, condition.Criterion) {
+			if index+1 >= len(args) {
+				return nil, lexer.Diagnostic{Line: args[index].Line, Column: args[index].Column,
+					Message: fmt.Sprintf("match criterion %q requires an argument", word)}
 			}
-			hostConfigs[currentHost].Config[key] = value
-		default:
-			// TokenValue at line start is only after Keyword; already handled
+			index++
+			condition.Argument = args[index].Value
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// FormatMatchConditions renders the criteria of a Match line.
+func FormatMatchConditions(conditions []Define.MatchCondition) string {
+	parts := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		criterion := condition.Criterion
+		if condition.Negate {
+			criterion = "!" + criterion
+		}
+		if condition.Argument == "" && slices.Contains(Define.MatchCriteriaWithoutArgument, condition.Criterion) {
+			parts = append(parts, criterion)
 			continue
 		}
+		parts = append(parts, criterion+" "+quoteArgument(condition.Argument))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteArgument wraps an argument in double quotes when it would not survive as a single word.
+func quoteArgument(argument string) string {
+	if argument != "" && !strings.ContainsAny(argument, " \t\"#") {
+		return argument
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(argument) + `"`
+}
+
+func sshGroupToHostConfig(host string, group SSHHostConfigGroup) Define.HostConfig {
+	rawInfo := GetSSHConfigContent(host, group)
+	hostInfo := ParseSSHConfig(rawInfo.Config, rawInfo.Comments)
+	config, name, notes := GetSingleHostData(hostInfo)
+
+	return Define.HostConfig{
+		Name:   name,
+		Notes:  notes,
+		Config: config,
+		Lists:  hostInfo.YamlUserLists,
 	}
-	return hostConfigs
 }
 
 func GroupSSHConfig(userInput string) ([]Define.HostConfig, error) {
-	configs, err := GroupSSHConfigFromString(userInput)
+	return GroupSSHConfigWithOptions(userInput, Options{})
+}
+
+func GroupSSHConfigWithOptions(userInput string, options Options) ([]Define.HostConfig, error) {
+	blocks, err := GroupSSHBlocksFromString(userInput)
 	if err != nil {
 		return nil, err
 	}
-	hosts := make([]string, 0, len(configs))
-	for host := range configs {
-		hosts = append(hosts, host)
+	return groupSSHBlocks(blocks, options)
+}
+
+func groupSSHBlocks(blocks []SSHConfigBlock, options Options) ([]Define.HostConfig, error) {
+	blocks, err := resolveDuplicates(blocks, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		for _, shadowed := range block.Shadowed {
+			options.warn(fmt.Sprintf("%s: %s %q at line %d is ignored, ssh uses the first value %q",
+				block.Label(), shadowed.Key, shadowed.Value, shadowed.Line, shadowed.Kept))
+		}
 	}
-	slices.Sort(hosts)
 
-	hostConfigs := make([]Define.HostConfig, 0, len(hosts))
-	for _, host := range hosts {
-		hostConfig := configs[host]
-		rawInfo := GetSSHConfigContent(host, hostConfig)
-		hostInfo := ParseSSHConfig(rawInfo.Config, rawInfo.Comments)
-		config, name, notes := GetSingleHostData(hostInfo)
+	hostConfigs := make([]Define.HostConfig, 0, len(blocks))
+	toHostConfig := func(block SSHConfigBlock) Define.HostConfig {
+		return normalizeHostConfig(blockToHostConfig(block), typedSpellings(block.SSHHostConfigGroup), block.Label(), options)
+	}
+	if options.KeepOrder {
+		for _, block := range blocks {
+			hostConfigs = append(hostConfigs, toHostConfig(block))
+		}
+		return hostConfigs, nil
+	}
 
-		hostConfigs = append(hostConfigs, Define.HostConfig{
-			Name:   name,
-			Notes:  notes,
-			Config: config,
-		})
+	var hosts []SSHConfigBlock
+	for _, block := range blocks {
+		if block.IsInclude() {
+			hostConfigs = append(hostConfigs, toHostConfig(block))
+		} else if !block.IsMatch() {
+			hosts = append(hosts, block)
+		}
+	}
+
+	// kept duplicates stay next to each other, in source order
+	slices.SortStableFunc(hosts, func(a, b SSHConfigBlock) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+	for _, block := range hosts {
+		hostConfigs = append(hostConfigs, toHostConfig(block))
+	}
+
+	// Match blocks keep their order, they are evaluated top to bottom.
+	for index, block := range blocks {
+		if block.IsMatch() {
+			warnMovedMatch(block, blocks[index+1:], options)
+			hostConfigs = append(hostConfigs, toHostConfig(block))
+		}
 	}
 	return hostConfigs, nil
 }
 
+// warnMovedMatch warns about the Host blocks in later that set keywords
+// match sets too: they move before it, so their values win where it set
+// them first.
+func warnMovedMatch(match SSHConfigBlock, later []SSHConfigBlock, options Options) {
+	keys := Fn.GetOrderConfig(match.Config, match.Lists).Keys
+	for _, block := range later {
+		if block.IsMatch() || block.IsInclude() {
+			continue
+		}
+		var shared []string
+		for _, key := range keys {
+			if hasKey(block.SSHHostConfigGroup, key) {
+				shared = append(shared, key)
+			}
+		}
+		if len(shared) > 0 {
+			options.warn(fmt.Sprintf("%s: %s at line %d is written before it in the output, so ssh takes %s from that block first, use -keep-order to keep the source order",
+				match.Label(), block.Label(), block.Line, strings.Join(shared, ", ")))
+		}
+	}
+}
+
+func blockToHostConfig(block SSHConfigBlock) Define.HostConfig {
+	switch {
+	case block.IsInclude():
+		return Define.HostConfig{Include: block.Include}
+	case block.IsMatch():
+		hostConfig := sshGroupToHostConfig("", block.SSHHostConfigGroup)
+		hostConfig.Match = block.Match
+		return hostConfig
+	default:
+		return sshGroupToHostConfig(block.Host, block.SSHHostConfigGroup)
+	}
+}
+
 type SSHHostConfigGrouped struct {
 	Comments string
 	Config   string
@@ -169,16 +478,41 @@ func GetSSHConfigContent(host string, input SSHHostConfigGroup) (config SSHHostC
 
 	var lines []string
 
-	configs := Fn.GetOrderMaps(input.Config)
+	configs := Fn.GetOrderConfig(input.Config, input.Lists)
 	for _, field := range configs.Keys {
-		lines = append(lines, fmt.Sprintf("    %s %s", field, configs.Data[field]))
+		for _, value := range configs.Values(field) {
+			lines = append(lines, fmt.Sprintf("    %s %s", field, value))
+		}
 	}
 	config.Config = strings.Join(lines, "\n")
 	config.Config = "Host " + host + "\n" + config.Config
 	return config
 }
 
+// keywordFields maps the lower-cased ssh_config(5) keywords, Define.Keywords,
+// to the index of the HostConfig field that carries them.
+var keywordFields = func() map[string]int {
+	fields := make(map[string]int, len(Define.Keywords))
+	t := reflect.TypeOf(HostConfig{})
+	for _, keyword := range Define.Keywords {
+		if field, ok := t.FieldByName(keyword); ok {
+			fields[strings.ToLower(keyword)] = field.Index[0]
+		}
+	}
+	return fields
+}()
+
+// keywordName returns the canonical spelling of the keyword held by field index.
+func keywordName(index int) string {
+	field := reflect.TypeOf(HostConfig{}).Field(index)
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
 func ParseSSHConfig(input string, notes string) (config HostConfig) {
+	fields := reflect.ValueOf(&config).Elem()
+	// ssh uses the first value obtained for a keyword
+	obtained := make(map[string]bool)
+
 	lines := strings.Split(input, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -191,41 +525,41 @@ func ParseSSHConfig(input string, notes string) (config HostConfig) {
 			key := strings.ToLower(parts[0])
 			value := strings.TrimSpace(parts[1])
 
-			switch key {
-			case "hostname":
-				config.HostName = value
-			case "user":
-				config.User = value
-			case "identityfile":
-				config.IdentityFile = value
-			case "port":
-				config.Port = value
-			case "controlpath":
-				config.ControlPath = value
-			case "controlpersist":
-				config.ControlPersist = value
-			case "tcpkeepalive":
-				config.TCPKeepAlive = value
-			case "compression":
-				config.Compression = value
-			case "forwardagent":
-				config.ForwardAgent = value
-			case "ciphers":
-				config.Ciphers = value
-			case "hostkeyalgorithms":
-				config.HostKeyAlgorithms = value
-			case "kexalgorithms":
-				config.KexAlgorithms = value
-			case "pubkeyauthentication":
-				config.PubkeyAuthentication = value
-			case "proxycommand":
-				config.ProxyCommand = value
-			case "host":
+			if key == "host" {
 				config.YamlUserHost = value
-			case "pubkeyacceptedalgorithms":
-				config.PubkeyAcceptedAlgorithms = value
-			default:
-				fmt.Println("Unknown key", key)
+				continue
+			}
+
+			if index, ok := keywordFields[key]; ok {
+				field := fields.Field(index)
+				if name := keywordName(index); Define.IsMultiValueKeyword(name) {
+					if values, ok := config.YamlUserLists[name]; ok {
+						config.YamlUserLists[name] = append(values, value)
+						continue
+					}
+					if field.String() != "" {
+						if config.YamlUserLists == nil {
+							config.YamlUserLists = make(map[string][]string)
+						}
+						config.YamlUserLists[name] = []string{field.String(), value}
+						field.SetString("")
+						continue
+					}
+				}
+				if !obtained[key] {
+					field.SetString(value)
+					obtained[key] = true
+				}
+				continue
+			}
+
+			// keep keywords we don't know about instead of dropping them
+			if config.YamlUnknownKeys == nil {
+				config.YamlUnknownKeys = make(map[string]string)
+			}
+			if !obtained[key] {
+				config.YamlUnknownKeys[parts[0]] = value
+				obtained[key] = true
 			}
 		}
 	}
@@ -252,6 +586,10 @@ func GetSingleHostData(input HostConfig) (result map[string]string, name string,
 			tag = strings.Split(tag, ",")[0]
 		}
 
+		if value.Kind() != reflect.String {
+			continue
+		}
+
 		if !value.IsZero() {
 			val := value.Interface().(string)
 			if val != "" {
@@ -265,27 +603,79 @@ func GetSingleHostData(input HostConfig) (result map[string]string, name string,
 	delete(config, "YamlUserHost")
 	delete(config, "YamlUserNotes")
 
+	for key, value := range input.YamlUnknownKeys {
+		if _, ok := config[key]; !ok {
+			config[key] = value
+		}
+	}
+
 	return config, name, notes
 }
 
+// appendBlock renders the notes, the Host, Match or Include line and the
+// directives of a single config.
+func appendBlock(lines []string, config Define.HostConfig) []string {
+	if config.IsInclude() {
+		patterns := make([]string, 0, len(config.Include))
+		for _, pattern := range config.Include {
+			patterns = append(patterns, quoteArgument(pattern))
+		}
+		return append(lines, fmt.Sprintf("Include %s", strings.Join(patterns, " ")))
+	}
+
+	if config.Notes != "" {
+		notes := strings.Split(config.Notes, "\n")
+		for _, note := range notes {
+			lines = append(lines, fmt.Sprintf("# %s", note))
+		}
+	}
+
+	if config.IsMatch() {
+		lines = append(lines, fmt.Sprintf("Match %s", FormatMatchConditions(config.Match)))
+	} else {
+		hostName := fmt.Sprintf("%s%s", config.Extra.Prefix, config.Name)
+		lines = append(lines, fmt.Sprintf("Host %s", hostName))
+	}
+
+	orderMaps := Fn.GetOrderConfig(config.Config, config.Lists)
+	for _, field := range orderMaps.Keys {
+		for _, value := range orderMaps.Values(field) {
+			lines = append(lines, fmt.Sprintf("    %s %s", field, value))
+		}
+	}
+	return lines
+}
+
 func ConvertToSSH(hostConfigs []Define.HostConfig) []byte {
+	return ConvertToSSHWithOptions(hostConfigs, Options{})
+}
+
+func ConvertToSSHWithOptions(hostConfigs []Define.HostConfig, options Options) []byte {
 	lines := make([]string, 0)
+	if options.LegacyKeywords {
+		hostConfigs = legacyHostConfigs(hostConfigs)
+	}
+
+	if options.KeepOrder {
+		for _, config := range hostConfigs {
+			lines = appendBlock(lines, config)
+			lines = append(lines, "")
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	includeConfigs := Fn.FindIncludeConfig(hostConfigs)
+	if len(includeConfigs) > 0 {
+		for _, config := range includeConfigs {
+			lines = appendBlock(lines, config)
+		}
+		lines = append(lines, "")
+	}
 
 	globalConfigs := Fn.FindGlobalConfig(hostConfigs)
 	if len(globalConfigs) > 0 {
 		for _, config := range globalConfigs {
-			if config.Notes != "" {
-				notes := strings.Split(config.Notes, "\n")
-				for _, note := range notes {
-					lines = append(lines, fmt.Sprintf("# %s", note))
-				}
-			}
-			lines = append(lines, fmt.Sprintf("Host %s", config.Name))
-
-			orderMaps := Fn.GetOrderMaps(config.Config)
-			for _, field := range orderMaps.Keys {
-				lines = append(lines, fmt.Sprintf("    %s %s", field, orderMaps.Data[field]))
-			}
+			lines = appendBlock(lines, config)
 		}
 		lines = append(lines, "")
 	}
@@ -293,19 +683,16 @@ func ConvertToSSH(hostConfigs []Define.HostConfig) []byte {
 	normalConfigs := Fn.FindNormalConfig(hostConfigs)
 	if len(normalConfigs) > 0 {
 		for _, config := range normalConfigs {
-			if config.Notes != "" {
-				notes := strings.Split(config.Notes, "\n")
-				for _, note := range notes {
-					lines = append(lines, fmt.Sprintf("# %s", note))
-				}
-			}
+			lines = appendBlock(lines, config)
+			lines = append(lines, "")
+		}
+		lines = append(lines, "")
+	}
 
-			hostName := fmt.Sprintf("%s%s", config.Extra.Prefix, config.Name)
-			lines = append(lines, fmt.Sprintf("Host %s", hostName))
-			orderMaps := Fn.GetOrderMaps(config.Config)
-			for _, field := range orderMaps.Keys {
-				lines = append(lines, fmt.Sprintf("    %s %s", field, orderMaps.Data[field]))
-			}
+	matchConfigs := Fn.FindMatchConfig(hostConfigs)
+	if len(matchConfigs) > 0 {
+		for _, config := range matchConfigs {
+			lines = appendBlock(lines, config)
 			lines = append(lines, "")
 		}
 		lines = append(lines, "")
@@ -17,12 +17,14 @@
 package parser_test
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"reflect"
 	"strings"
 	"testing"
 
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
 	Define "github.com/soulteary/ssh-config/v2/internal/define"
 	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
 )
@@ -72,7 +74,7 @@ func TestGroupSSHConfigFromString(t *testing.T) {
 }
 
 func TestGroupSSHConfigFromString_IncludeMatchIgnored(t *testing.T) {
-	// include/match 关键字应被跳过，不参与 host 解析
+	// include 行被跳过；Match 之后的指令属于 Match 块，不会混入上一个 Host
 	input := `Host foo
     HostName foo.com
 Include other.conf
@@ -86,9 +88,134 @@ Match host bar
 	if _, ok := actual["foo"]; !ok {
 		t.Errorf("GroupSSHConfigFromString() expected host foo, got %v", actual)
 	}
-	// Include/Match 行被消费后，bar 不会作为独立 Host 出现（Match 后的 HostName 属于上一 block）
 	if len(actual) != 1 {
-		t.Logf("GroupSSHConfigFromString() got hosts: %v", actual)
+		t.Errorf("GroupSSHConfigFromString() got hosts: %v, want only foo", actual)
+	}
+	if got := actual["foo"].Config["HostName"]; got != "foo.com" {
+		t.Errorf("GroupSSHConfigFromString() foo HostName = %q, want foo.com", got)
+	}
+}
+
+func TestGroupSSHConfig_KeepInclude(t *testing.T) {
+	input := `Include config.d/* "~/my configs/*.conf"
+Host foo
+    HostName foo.com
+    Include foo.d/*
+`
+	configs, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	expected := []Define.HostConfig{
+		{Include: []string{"config.d/*", "~/my configs/*.conf"}},
+		{Name: "foo", Config: map[string]string{"HostName": "foo.com", "Include": "foo.d/*"}},
+	}
+	if !reflect.DeepEqual(configs, expected) {
+		t.Fatalf("GroupSSHConfig() = %+v, want %+v", configs, expected)
+	}
+
+	output := string(Parser.ConvertToSSH(configs))
+	want := "Include config.d/* \"~/my configs/*.conf\"\n\nHost foo\n    HostName foo.com\n    Include foo.d/*\n\n"
+	if output != want {
+		t.Errorf("ConvertToSSH() = %q, want %q", output, want)
+	}
+}
+
+func TestGroupSSHBlocksFromString_Match(t *testing.T) {
+	input := `Host foo
+    HostName foo.com
+
+# office network
+Match originalhost "*.corp" !exec "test -f /tmp/vpn up" user admin
+    User admin
+Match canonical final all
+    ForwardAgent no
+Match !localuser root
+    IdentityFile ~/.ssh/a
+    IdentityFile ~/.ssh/b
+`
+	blocks, err := Parser.GroupSSHBlocksFromString(input)
+	if err != nil {
+		t.Fatalf("GroupSSHBlocksFromString() error = %v", err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("GroupSSHBlocksFromString() = %v, want 4 blocks", blocks)
+	}
+	if blocks[0].Host != "foo" || blocks[0].IsMatch() {
+		t.Errorf("GroupSSHBlocksFromString() first block = %+v, want Host foo", blocks[0])
+	}
+
+	expected := [][]Define.MatchCondition{
+		{
+			{Criterion: "originalhost", Argument: "*.corp"},
+			{Criterion: "exec", Negate: true, Argument: "test -f /tmp/vpn up"},
+			{Criterion: "user", Argument: "admin"},
+		},
+		{
+			{Criterion: "canonical"},
+			{Criterion: "final"},
+			{Criterion: "all"},
+		},
+		{
+			{Criterion: "localuser", Negate: true, Argument: "root"},
+		},
+	}
+	for i, want := range expected {
+		block := blocks[i+1]
+		if !reflect.DeepEqual(block.Match, want) {
+			t.Errorf("GroupSSHBlocksFromString() Match[%d] = %+v, want %+v", i, block.Match, want)
+		}
+	}
+	if !reflect.DeepEqual(blocks[1].Comments, []string{"office network"}) {
+		t.Errorf("GroupSSHBlocksFromString() Match comments = %v", blocks[1].Comments)
+	}
+	if blocks[1].Config["User"] != "admin" || blocks[2].Config["ForwardAgent"] != "no" {
+		t.Errorf("GroupSSHBlocksFromString() Match config = %v, %v", blocks[1].Config, blocks[2].Config)
+	}
+	if !reflect.DeepEqual(blocks[3].Lists["IdentityFile"], []string{"~/.ssh/a", "~/.ssh/b"}) {
+		t.Errorf("GroupSSHBlocksFromString() Match lists = %v", blocks[3].Lists)
+	}
+}
+
+func TestGroupSSHBlocksFromString_InvalidMatch(t *testing.T) {
+	for _, input := range []string{
+		"Match\n",
+		"Match host\n",
+		"Match nosuchcriterion foo\n",
+	} {
+		if _, err := Parser.GroupSSHBlocksFromString(input); err == nil {
+			t.Errorf("GroupSSHBlocksFromString(%q) expected error, got nil", input)
+		}
+	}
+}
+
+func TestConvertToSSH_Match(t *testing.T) {
+	input := `Match host "*.corp" !exec "test -f /tmp/vpn"
+    User admin
+
+Host foo
+    HostName foo.com
+
+Match all
+    ForwardAgent no
+`
+	configs, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	expected := "Host foo\n    HostName foo.com\n\n\n" +
+		"Match host *.corp !exec \"test -f /tmp/vpn\"\n    User admin\n\n" +
+		"Match all\n    ForwardAgent no\n\n"
+	if got := string(Parser.ConvertToSSH(configs)); got != expected {
+		t.Errorf("ConvertToSSH() = %q, want %q", got, expected)
+	}
+
+	again, err := Parser.GroupSSHConfig(expected)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(again, configs) {
+		t.Errorf("GroupSSHConfig() round-trip = %+v, want %+v", again, configs)
 	}
 }
 
@@ -256,6 +383,27 @@ Host unknown
 			expected: Parser.HostConfig{
 				HostName:     "unknown.com",
 				YamlUserHost: "unknown",
+				YamlUnknownKeys: map[string]string{
+					"UnknownKey1": "value1",
+					"UnknownKey2": "value2",
+				},
+			},
+		},
+		{
+			name: "Less Common Keywords",
+			input: `
+Host tunnel
+    ServerAliveInterval 30
+    localforward 8080 localhost:80
+    IdentitiesOnly yes
+    SetEnv FOO=bar
+`,
+			expected: Parser.HostConfig{
+				ServerAliveInterval: "30",
+				LocalForward:        "8080 localhost:80",
+				IdentitiesOnly:      "yes",
+				SetEnv:              "FOO=bar",
+				YamlUserHost:        "tunnel",
 			},
 		},
 	}
@@ -305,6 +453,20 @@ func TestGetSingleHostData(t *testing.T) {
 			expectedName:  "host2",
 			expectedNotes: "",
 		},
+		{
+			name: "Unknown keys",
+			input: Parser.HostConfig{
+				YamlUserHost:    "host3",
+				Port:            "22",
+				YamlUnknownKeys: map[string]string{"VendorOption": "on"},
+			},
+			expectedResult: map[string]string{
+				"Port":         "22",
+				"VendorOption": "on",
+			},
+			expectedName:  "host3",
+			expectedNotes: "",
+		},
 		{
 			name:           "Empty config",
 			input:          Parser.HostConfig{},
@@ -462,6 +624,84 @@ Host example2
 	}
 }
 
+func TestGroupSSHConfig_KeepsAllKeywords(t *testing.T) {
+	input := `Host tunnel
+    HostName tunnel.example.com
+    ServerAliveInterval 30
+    LocalForward 8080 localhost:80
+    IdentitiesOnly yes
+    SetEnv FOO=bar
+    XVendorOption on
+`
+	results, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("GroupSSHConfig() = %v, want 1 host", results)
+	}
+	expected := map[string]string{
+		"HostName":            "tunnel.example.com",
+		"ServerAliveInterval": "30",
+		"LocalForward":        "8080 localhost:80",
+		"IdentitiesOnly":      "yes",
+		"SetEnv":              "FOO=bar",
+		"XVendorOption":       "on",
+	}
+	if !reflect.DeepEqual(results[0].Config, expected) {
+		t.Errorf("GroupSSHConfig() Config = %v, want %v", results[0].Config, expected)
+	}
+
+	output := string(Parser.ConvertToSSH(results))
+	for key, value := range expected {
+		if !strings.Contains(output, "    "+key+" "+value+"\n") {
+			t.Errorf("ConvertToSSH() missing %s %s in:\n%s", key, value, output)
+		}
+	}
+}
+
+func TestGroupSSHConfig_MultiValueDirectives(t *testing.T) {
+	input := `Host tunnel
+    HostName tunnel.example.com
+    IdentityFile ~/.ssh/id_ed25519
+    LocalForward 8080 localhost:80
+    IdentityFile ~/.ssh/id_rsa
+    LocalForward 8443 localhost:443
+    IdentityFile ~/.ssh/id_ecdsa
+    SendEnv LANG
+`
+	results, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("GroupSSHConfig() = %v, want 1 host", results)
+	}
+	expectedLists := map[string][]string{
+		"IdentityFile": {"~/.ssh/id_ed25519", "~/.ssh/id_rsa", "~/.ssh/id_ecdsa"},
+		"LocalForward": {"8080 localhost:80", "8443 localhost:443"},
+	}
+	if !reflect.DeepEqual(results[0].Lists, expectedLists) {
+		t.Errorf("GroupSSHConfig() Lists = %v, want %v", results[0].Lists, expectedLists)
+	}
+	expectedConfig := map[string]string{"HostName": "tunnel.example.com", "SendEnv": "LANG"}
+	if !reflect.DeepEqual(results[0].Config, expectedConfig) {
+		t.Errorf("GroupSSHConfig() Config = %v, want %v", results[0].Config, expectedConfig)
+	}
+
+	expected := "Host tunnel\n" +
+		"    HostName tunnel.example.com\n" +
+		"    IdentityFile ~/.ssh/id_ed25519\n" +
+		"    IdentityFile ~/.ssh/id_rsa\n" +
+		"    IdentityFile ~/.ssh/id_ecdsa\n" +
+		"    LocalForward 8080 localhost:80\n" +
+		"    LocalForward 8443 localhost:443\n" +
+		"    SendEnv LANG\n\n"
+	if got := string(Parser.ConvertToSSH(results)); got != expected {
+		t.Errorf("ConvertToSSH() = %q, want %q", got, expected)
+	}
+}
+
 func TestGroupSSHConfigFromString_NilConfig(t *testing.T) {
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -557,3 +797,248 @@ func keys(m map[string]Parser.SSHHostConfigGroup) []string {
 	}
 	return ks
 }
+
+func TestGroupSSHConfig_KeepOrder(t *testing.T) {
+	input := `Host zeta
+    HostName zeta.com
+
+Match host zeta
+    User admin
+
+Host alpha
+    HostName alpha.com
+
+Host *
+    User nobody
+`
+	options := Parser.Options{KeepOrder: true}
+	hostConfigs, err := Parser.GroupSSHConfigWithOptions(input, options)
+	if err != nil {
+		t.Fatalf("GroupSSHConfigWithOptions() error = %v", err)
+	}
+	got := string(Parser.ConvertToSSHWithOptions(hostConfigs, options))
+	if got != input {
+		t.Errorf("ConvertToSSHWithOptions() = %q, want %q", got, input)
+	}
+
+	sorted, err := Parser.GroupSSHConfig(input)
+	if err != nil {
+		t.Fatalf("GroupSSHConfig() error = %v", err)
+	}
+	if sorted[0].Name != "*" || sorted[1].Name != "alpha" {
+		t.Errorf("GroupSSHConfig() = %+v, want sorted hosts", sorted)
+	}
+}
+
+func TestGroupSSHConfig_Duplicates(t *testing.T) {
+	input := `Host foo
+    HostName first.com
+    IdentityFile ~/.ssh/one
+
+Host bar
+    User bar
+
+# second foo
+Host foo
+    HostName second.com
+    Port 2222
+    IdentityFile ~/.ssh/two
+`
+	var warnings []string
+	options := Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }}
+	got, err := Parser.GroupSSHConfigWithOptions(input, options)
+	if err != nil {
+		t.Fatalf("GroupSSHConfigWithOptions() error = %v", err)
+	}
+	merged := Define.HostConfig{
+		Name:   "foo",
+		Notes:  "second foo",
+		Config: map[string]string{"HostName": "first.com", "Port": "2222"},
+		Lists:  map[string][]string{"IdentityFile": {"~/.ssh/one", "~/.ssh/two"}},
+	}
+	if len(got) != 2 || !reflect.DeepEqual(got[1], merged) {
+		t.Errorf("GroupSSHConfigWithOptions() = %+v, want merged %+v", got, merged)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `duplicate Host block "foo" at line 9, first defined at line 1`) {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	options.Duplicates = Parser.DuplicateMerge
+	warnings = nil
+	if _, err := Parser.GroupSSHConfigWithOptions(input, options); err != nil || len(warnings) != 0 {
+		t.Errorf("merge policy: err = %v, warnings = %v", err, warnings)
+	}
+
+	options.Duplicates = Parser.DuplicateError
+	if _, err := Parser.GroupSSHConfigWithOptions(input, options); err == nil || !strings.Contains(err.Error(), "line 9") {
+		t.Errorf("error policy: err = %v", err)
+	}
+
+	options.Duplicates = Parser.DuplicateKeep
+	options.KeepOrder = true
+	got, err = Parser.GroupSSHConfigWithOptions(input, options)
+	if err != nil {
+		t.Fatalf("keep policy: err = %v", err)
+	}
+	names := []string{}
+	for _, config := range got {
+		names = append(names, config.Name+"="+config.Config["HostName"])
+	}
+	if want := []string{"foo=first.com", "bar=", "foo=second.com"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("keep policy = %v, want %v", names, want)
+	}
+	if output := string(Parser.ConvertToSSHWithOptions(got, options)); strings.Count(output, "Host foo\n") != 2 {
+		t.Errorf("ConvertToSSHWithOptions() = %q, want both foo blocks", output)
+	}
+	yamlOutput := Parser.ConvertToYAMLWithOptions(got, options)
+	if back := Parser.GroupYAMLConfigWithOptions(string(yamlOutput), options); !reflect.DeepEqual(back, got) {
+		t.Errorf("YAML round-trip = %+v, want %+v\n%s", back, got, yamlOutput)
+	}
+}
+
+func TestGroupSSHConfig_DuplicatesWithBlockInBetween(t *testing.T) {
+	input := `Host a
+    User deploy
+
+Host *
+    Port 1
+
+Host b
+    User other
+
+Host a
+    Port 2
+    User ignored
+    Compression yes
+`
+	var warnings []string
+	options := Parser.Options{KeepOrder: true, Warn: func(message string) { warnings = append(warnings, message) }}
+	got, err := Parser.GroupSSHConfigWithOptions(input, options)
+	if err != nil {
+		t.Fatalf("GroupSSHConfigWithOptions() error = %v", err)
+	}
+	var blocks []string
+	for _, config := range got {
+		blocks = append(blocks, fmt.Sprintf("%s %v", config.Name, config.Config))
+	}
+	want := []string{"a map[Compression:yes User:deploy]", "* map[Port:1]", "b map[User:other]", "a map[Port:2]"}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("GroupSSHConfigWithOptions() = %v, want %v", blocks, want)
+	}
+	if len(warnings) != 1 || !strings.HasSuffix(warnings[0], "Port stay at line 10 as a block in between may set them first") {
+		t.Errorf("warnings = %q", warnings)
+	}
+
+	args := Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"a"}, KeepOrder: true, Duplicates: "merge"}
+	if resolved, err := Parser.Process("TEXT", input, args); err != nil || !strings.Contains(string(resolved), "\nport 1\n") {
+		t.Errorf("Process(resolve a) = %s, %v, want port 1", resolved, err)
+	}
+}
+
+func TestGroupSSHConfig_MatchMovedAfterHosts(t *testing.T) {
+	input := `Host a
+    User a
+
+Match host b
+    User match
+    Port 2222
+
+Host b
+    User b
+    Compression yes
+
+Host c
+    Compression yes
+`
+	var warnings []string
+	options := Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }}
+	if _, err := Parser.GroupSSHConfigWithOptions(input, options); err != nil {
+		t.Fatalf("GroupSSHConfigWithOptions() error = %v", err)
+	}
+	want := []string{"Match host b: Host b at line 8 is written before it in the output, so ssh takes User from that block first, use -keep-order to keep the source order"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	warnings = nil
+	options.KeepOrder = true
+	if _, err := Parser.GroupSSHConfigWithOptions(input, options); err != nil || len(warnings) != 0 {
+		t.Errorf("GroupSSHConfigWithOptions(-keep-order) = %v, warnings %q", err, warnings)
+	}
+}
+
+func TestGroupSSHConfig_FirstValueWins(t *testing.T) {
+	input := `Host foo
+    Port 22
+    User first
+    port 2222
+    IdentityFile ~/.ssh/one
+    identityfile ~/.ssh/two
+`
+	blocks, err := Parser.GroupSSHBlocksFromString(input)
+	if err != nil {
+		t.Fatalf("GroupSSHBlocksFromString() error = %v", err)
+	}
+	wantShadowed := []Parser.ShadowedValue{{Key: "port", Value: "2222", Kept: "22", Line: 4}}
+	if len(blocks) != 1 || !reflect.DeepEqual(blocks[0].Shadowed, wantShadowed) {
+		t.Fatalf("Shadowed = %+v, want %+v", blocks, wantShadowed)
+	}
+
+	var warnings []string
+	got, err := Parser.GroupSSHConfigWithOptions(input, Parser.Options{Warn: func(message string) { warnings = append(warnings, message) }})
+	if err != nil {
+		t.Fatalf("GroupSSHConfigWithOptions() error = %v", err)
+	}
+	want := []Define.HostConfig{{
+		Name:   "foo",
+		Config: map[string]string{"Port": "22", "User": "first"},
+		Lists:  map[string][]string{"IdentityFile": {"~/.ssh/one", "~/.ssh/two"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupSSHConfigWithOptions() = %+v, want %+v", got, want)
+	}
+	wantWarning := `Host foo: port "2222" at line 4 is ignored, ssh uses the first value "22"`
+	if len(warnings) != 1 || warnings[0] != wantWarning {
+		t.Errorf("warnings = %q, want %q", warnings, wantWarning)
+	}
+}
+
+func TestParseSSHConfig_FirstValueWins(t *testing.T) {
+	config := Parser.ParseSSHConfig("Host a\nPort 22\nPORT 2222\nFoo one\nfoo two", "")
+	if config.Port != "22" {
+		t.Errorf("Port = %q, want 22", config.Port)
+	}
+	if !reflect.DeepEqual(config.YamlUnknownKeys, map[string]string{"Foo": "one"}) {
+		t.Errorf("YamlUnknownKeys = %v", config.YamlUnknownKeys)
+	}
+}
+
+func TestParseSSHConfig_KnownKeywords(t *testing.T) {
+	for _, keyword := range Define.Keywords {
+		config := Parser.ParseSSHConfig("Host a\n"+strings.ToLower(keyword)+" value", "")
+		if len(config.YamlUnknownKeys) > 0 {
+			t.Errorf("ParseSSHConfig() keeps %s as an unknown keyword", keyword)
+		}
+		data, _, _ := Parser.GetSingleHostData(config)
+		if data[keyword] != "value" {
+			t.Errorf("GetSingleHostData() = %v, want %s", data, keyword)
+		}
+	}
+
+	config := Parser.ParseSSHConfig("Host a\nLogLevelVerbose yes\nRequireRSASize 2048", "")
+	if len(config.YamlUnknownKeys) != 2 {
+		t.Errorf("YamlUnknownKeys = %v, want the misspelled keywords", config.YamlUnknownKeys)
+	}
+}
+
+func TestGroupSSHBlocksFromString_ReportsAllProblems(t *testing.T) {
+	input := "Host a\n    User \"unclosed\nHost b\n    = x\n    Port 22\n"
+	_, err := Parser.GroupSSHBlocksFromString(input)
+	if err == nil {
+		t.Fatal("GroupSSHBlocksFromString() expected an error, got nil")
+	}
+	want := "line 2 column 10: unclosed quoted string\nline 4 column 5: '=' without a keyword"
+	if err.Error() != want {
+		t.Errorf("GroupSSHBlocksFromString() error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,269 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+)
+
+// BlockScanner reads the blocks of an ssh config one at a time from a token
+// stream, only the block being read is kept in memory.
+type BlockScanner struct {
+	source    func() (lexer.Token, error)
+	lookahead *lexer.Token
+	current   *SSHConfigBlock
+	comments  []string
+	ready     []SSHConfigBlock
+	done      bool
+	// err is returned once the blocks read before it are consumed.
+	err error
+}
+
+// NewBlockScanner returns a scanner reading the config from r.
+func NewBlockScanner(r io.Reader) *BlockScanner {
+	return newBlockScanner(lexer.NewStreamLexer(r).NextToken)
+}
+
+func newBlockScanner(source func() (lexer.Token, error)) *BlockScanner {
+	return &BlockScanner{source: source}
+}
+
+// Next returns the next Host, Match or Include block, and io.EOF once the
+// input is exhausted. Directives before the first Host line come as a block
+// with an empty Host. After an error the scanner stops.
+func (s *BlockScanner) Next() (SSHConfigBlock, error) {
+	for len(s.ready) == 0 {
+		if s.err != nil {
+			err := s.err
+			s.err = nil
+			return SSHConfigBlock{}, err
+		}
+		if s.done {
+			return SSHConfigBlock{}, io.EOF
+		}
+		if err := s.step(); err != nil {
+			s.err = err
+			s.done = true
+			s.current = nil
+		}
+	}
+	block := s.ready[0]
+	s.ready = s.ready[1:]
+	return block, nil
+}
+
+func (s *BlockScanner) next() (lexer.Token, error) {
+	if s.lookahead != nil {
+		token := *s.lookahead
+		s.lookahead = nil
+		return token, nil
+	}
+	return s.source()
+}
+
+func (s *BlockScanner) peek() (lexer.Token, error) {
+	if s.lookahead == nil {
+		token, err := s.source()
+		if err != nil {
+			return lexer.Token{}, err
+		}
+		s.lookahead = &token
+	}
+	return *s.lookahead, nil
+}
+
+// arguments reads the values following a keyword, skipping a leading '='.
+func (s *BlockScanner) arguments() ([]lexer.Token, error) {
+	var args []lexer.Token
+	if token, err := s.peek(); err != nil {
+		return nil, err
+	} else if token.Kind == lexer.TokenEquals {
+		s.lookahead = nil
+	}
+	for {
+		token, err := s.peek()
+		if err != nil {
+			return nil, err
+		}
+		if token.Kind != lexer.TokenValue && token.Kind != lexer.TokenQuoted {
+			return args, nil
+		}
+		s.lookahead = nil
+		args = append(args, token)
+	}
+}
+
+// open finishes the current block and starts block.
+func (s *BlockScanner) open(block SSHConfigBlock) {
+	s.flush()
+	s.current = &block
+}
+
+func (s *BlockScanner) flush() {
+	if s.current != nil {
+		s.ready = append(s.ready, *s.current)
+		s.current = nil
+	}
+}
+
+// step consumes one token, or one line for keywords and directives.
+func (s *BlockScanner) step() error {
+	token, err := s.next()
+	if err != nil {
+		return err
+	}
+
+	switch token.Kind {
+	case lexer.TokenEOF:
+		s.flush()
+		s.done = true
+	case lexer.TokenComment:
+		s.comments = append(s.comments, token.Value)
+	case lexer.TokenKeyword:
+		args, err := s.arguments()
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(token.Value) {
+		case "host":
+			var parts []string
+			for _, arg := range args {
+				parts = append(parts, arg.Value)
+			}
+			host := strings.TrimSpace(strings.Join(parts, " "))
+			if host == "" {
+				s.open(SSHConfigBlock{})
+				return nil
+			}
+			s.open(SSHConfigBlock{
+				Host: host,
+				Line: token.Line,
+				SSHHostConfigGroup: SSHHostConfigGroup{
+					Comments: s.comments,
+					Config:   make(map[string]string),
+				},
+			})
+			s.comments = nil
+		case "match":
+			s.flush()
+			conditions, err := parseMatchConditions(args, token)
+			if err != nil {
+				return err
+			}
+			s.open(SSHConfigBlock{
+				Match: conditions,
+				Line:  token.Line,
+				SSHHostConfigGroup: SSHHostConfigGroup{
+					Comments: s.comments,
+					Config:   make(map[string]string),
+				},
+			})
+			s.comments = nil
+		case "include":
+			var patterns []string
+			for _, arg := range args {
+				patterns = append(patterns, arg.Value)
+			}
+			if len(patterns) == 0 {
+				return nil
+			}
+			// inside a block the Include only applies to it, keep it as a directive
+			if s.current != nil {
+				addDirective(&s.current.SSHHostConfigGroup, "Include", strings.Join(patterns, " "))
+				return nil
+			}
+			s.ready = append(s.ready, SSHConfigBlock{Include: patterns, Line: token.Line})
+		}
+	case lexer.TokenIdent:
+		args, err := s.arguments()
+		if err != nil {
+			return err
+		}
+		var parts []string
+		for _, arg := range args {
+			parts = append(parts, arg.Value)
+		}
+		// Allow empty values per ssh_config(5): first obtained value is used; empty is valid.
+		value := strings.TrimSpace(strings.Join(parts, " "))
+		if s.current == nil {
+			s.open(SSHConfigBlock{})
+		}
+		if kept, ok := addDirective(&s.current.SSHHostConfigGroup, token.Value, value); !ok {
+			s.current.Shadowed = append(s.current.Shadowed, ShadowedValue{
+				Key:   token.Value,
+				Value: value,
+				Kept:  kept,
+				Line:  token.Line,
+			})
+		}
+	}
+	// newlines and values at line start carry nothing on their own
+	return nil
+}
+
+// GroupSSHConfigFromReader is GroupSSHConfigWithOptions for a config read
+// from r. The text is lexed line by line, but every block is collected
+// before grouping, so memory still grows with the config. Include lines are
+// kept as they are.
+func GroupSSHConfigFromReader(r io.Reader, options Options) ([]Define.HostConfig, error) {
+	blocks, err := collectBlocks(NewBlockScanner(r))
+	if err != nil {
+		return nil, err
+	}
+	return groupSSHBlocks(blocks, options)
+}
+
+// collectBlocks reads every block of scanner. Directives outside of any Host
+// line all belong to the first unnamed block.
+func collectBlocks(scanner *BlockScanner) ([]SSHConfigBlock, error) {
+	var blocks []SSHConfigBlock
+	unnamed := -1
+	for {
+		block, err := scanner.Next()
+		if errors.Is(err, io.EOF) {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !block.isUnnamed() {
+			blocks = append(blocks, block)
+			continue
+		}
+		if unnamed < 0 {
+			unnamed = len(blocks)
+			blocks = append(blocks, block)
+			continue
+		}
+		first := &blocks[unnamed]
+		first.Shadowed = append(first.Shadowed, block.Shadowed...)
+		orderMaps := Fn.GetOrderConfig(block.Config, block.Lists)
+		for _, key := range orderMaps.Keys {
+			for _, value := range orderMaps.Values(key) {
+				if kept, ok := addDirective(&first.SSHHostConfigGroup, key, value); !ok {
+					first.Shadowed = append(first.Shadowed, ShadowedValue{Key: key, Value: value, Kept: kept})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+func TestBlockScanner(t *testing.T) {
+	input := "User root\n# web\nHost web\n    HostName web.com\nInclude extra.conf\nMatch all\n    Port 22\n"
+	scanner := Parser.NewBlockScanner(strings.NewReader(input))
+	var labels []string
+	for {
+		block, err := scanner.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		labels = append(labels, fmt.Sprintf("%s@%d %v", block.Label(), block.Line, block.Comments))
+	}
+	want := []string{
+		"directives before the first Host@0 []",
+		"Host web@3 [web]",
+		"Match all@6 []",
+	}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("blocks = %q, want %q", labels, want)
+	}
+}
+
+func TestBlockScanner_Error(t *testing.T) {
+	scanner := Parser.NewBlockScanner(strings.NewReader("Host a\nMatch nothing\nHost b\n"))
+	if _, err := scanner.Next(); err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if _, err := scanner.Next(); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Next() error = %v, want the Match error", err)
+	}
+	if _, err := scanner.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() after an error = %v, want io.EOF", err)
+	}
+}
+
+func TestGroupSSHConfigFromReader(t *testing.T) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(path.Join(pwd, "../../testdata/parser-ssh-group.cfg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, options := range []Parser.Options{{}, {KeepOrder: true}} {
+		want, err := Parser.GroupSSHConfigWithOptions(string(buf), options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Parser.GroupSSHConfigFromReader(strings.NewReader(string(buf)), options)
+		if err != nil {
+			t.Fatalf("GroupSSHConfigFromReader() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GroupSSHConfigFromReader() = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func benchmarkSSHConfig(hosts int) string {
+	var b strings.Builder
+	for i := 0; i < hosts; i++ {
+		fmt.Fprintf(&b, "# host %d\nHost host-%d\n    HostName 10.0.%d.%d\n    User deploy\n    Port 22\n    IdentityFile ~/.ssh/id_%d\n\n", i, i, i/256%256, i%256, i)
+	}
+	return b.String()
+}
+
+func BenchmarkGroupSSHBlocksFromString(b *testing.B) {
+	input := benchmarkSSHConfig(20000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Parser.GroupSSHBlocksFromString(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBlockScanner(b *testing.B) {
+	input := benchmarkSSHConfig(20000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		scanner := Parser.NewBlockScanner(strings.NewReader(input))
+		for {
+			_, err := scanner.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
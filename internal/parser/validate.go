@@ -0,0 +1,225 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Define "github.com/soulteary/ssh-config/v2/internal/define"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	"github.com/soulteary/ssh-config/v2/pkg/diag"
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+	"github.com/soulteary/ssh-config/v2/pkg/validate"
+)
+
+// ValueError is a value ssh would refuse.
+type ValueError struct {
+	// Line and Column locate the offending part of the value in SSH config
+	// text, they are 0 for YAML and JSON.
+	Line   int
+	Column int
+	// Label names the block of the value, e.g. "Host web", empty outside of
+	// any block.
+	Label string
+	validate.Problem
+}
+
+func (e ValueError) Error() string {
+	var parts []string
+	if e.Line > 0 {
+		parts = append(parts, fmt.Sprintf("%d:%d", e.Line, e.Column))
+	}
+	if e.Label != "" {
+		parts = append(parts, e.Label)
+	}
+	return strings.Join(append(parts, e.Problem.Error()), ": ")
+}
+
+// Diagnostic returns the error as a diagnostic of file.
+func (e ValueError) Diagnostic(file string) diag.Diagnostic {
+	return diag.Diagnostic{
+		File:     file,
+		Line:     e.Line,
+		Column:   e.Column,
+		Rule:     diag.RuleInvalidValue,
+		Severity: diag.SeverityError,
+		Label:    e.Label,
+		Message:  e.Problem.Error(),
+	}
+}
+
+// checkValue validates value, a deprecated keyword is checked as its
+// current one but reported as written.
+func checkValue(key, value string) []validate.Problem {
+	keyword := key
+	if modern, ok := Define.ModernKeyword(key); ok {
+		keyword = modern
+	}
+	problems := validate.Check(keyword, value)
+	for i := range problems {
+		problems[i].Key = key
+	}
+	return problems
+}
+
+// ValidateSSHConfig checks every value of SSH config text against the type
+// of its keyword. Include lines are not followed. Lines that can not be read
+// are skipped and reported in the error, one lexer.Diagnostic each, the rest
+// of the config is still checked.
+func ValidateSSHConfig(input string) ([]ValueError, error) {
+	tokens, diagnostics := lexer.LexAll(input)
+
+	var errs []ValueError
+	label := ""
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Kind != lexer.TokenKeyword && token.Kind != lexer.TokenIdent {
+			continue
+		}
+		next := i + 1
+		if next < len(tokens) && tokens[next].Kind == lexer.TokenEquals {
+			next++
+		}
+		var args []lexer.Token
+		var parts []string
+		for ; next < len(tokens) && (tokens[next].Kind == lexer.TokenValue || tokens[next].Kind == lexer.TokenQuoted); next++ {
+			args = append(args, tokens[next])
+			parts = append(parts, tokens[next].Value)
+		}
+		i = next - 1
+		value := strings.Join(parts, " ")
+
+		if token.Kind == lexer.TokenKeyword {
+			switch strings.ToLower(token.Value) {
+			case "host":
+				label = "Host " + value
+			case "match":
+				label = "Match " + value
+			}
+			continue
+		}
+		for _, problem := range checkValue(token.Value, value) {
+			line, column := valuePosition(token, args, problem.Offset)
+			errs = append(errs, ValueError{Line: line, Column: column, Label: label, Problem: problem})
+		}
+	}
+	return errs, joinDiagnostics(diagnostics)
+}
+
+// joinDiagnostics returns the problems lexer.LexAll found as one error, nil
+// when there are none.
+func joinDiagnostics(diagnostics []lexer.Diagnostic) error {
+	errs := make([]error, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		errs = append(errs, diagnostic)
+	}
+	return errors.Join(errs...)
+}
+
+// valuePosition returns the line and column of offset in the value made of
+// args joined by single spaces, the end of the keyword when there are none.
+func valuePosition(keyword lexer.Token, args []lexer.Token, offset int) (int, int) {
+	if len(args) == 0 {
+		return keyword.Line, keyword.Column + len(keyword.Value)
+	}
+	start := 0
+	for i, arg := range args {
+		end := start + len(arg.Value)
+		if offset <= end || i == len(args)-1 {
+			column := arg.Column + min(offset-start, len(arg.Value))
+			if arg.Kind == lexer.TokenQuoted {
+				// skip the opening quote
+				column++
+			}
+			return arg.Line, column
+		}
+		start = end + 1
+	}
+	return keyword.Line, keyword.Column
+}
+
+// ValidateHostConfigs checks every value of hostConfigs against the type of
+// its keyword, the errors carry no position.
+func ValidateHostConfigs(hostConfigs []Define.HostConfig) []ValueError {
+	var errs []ValueError
+	for _, hostConfig := range hostConfigs {
+		if hostConfig.IsInclude() {
+			continue
+		}
+		label := ""
+		switch {
+		case hostConfig.IsMatch():
+			label = "Match " + FormatMatchConditions(hostConfig.Match)
+		case hostConfig.Name != "":
+			label = "Host " + hostConfig.Name
+		}
+		orderMaps := Fn.GetOrderConfig(hostConfig.Config, hostConfig.Lists)
+		for _, key := range orderMaps.Keys {
+			for _, value := range orderMaps.Values(key) {
+				for _, problem := range checkValue(key, value) {
+					errs = append(errs, ValueError{Label: label, Problem: problem})
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateInput checks every value of userInput.
+func validateInput(fileType string, userInput string, args Cmd.Args, options Options) ([]ValueError, error) {
+	if strings.EqualFold(fileType, "TEXT") {
+		return ValidateSSHConfig(userInput)
+	}
+	hostConfigs, err := groupInput(fileType, userInput, args, options)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateHostConfigs(hostConfigs), nil
+}
+
+// processValidate runs the validate command: one line per invalid value, and
+// an error when there is any or when the config can not be read.
+func processValidate(fileType string, userInput string, args Cmd.Args, options Options) ([]byte, error) {
+	var diagnostics *diagnostics
+	if args.Format != "" {
+		diagnostics = newDiagnostics(args, &options)
+	}
+	errs, err := validateInput(fileType, userInput, args, options)
+	if diagnostics != nil {
+		if err != nil {
+			diagnostics.addError(err)
+		}
+		for _, valueErr := range errs {
+			diagnostics.add(valueErr.Diagnostic(""))
+		}
+		return diagnostics.render(args.Format)
+	}
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	if err != nil {
+		return []byte(strings.Join(lines, "\n")), err
+	}
+	if len(errs) == 0 {
+		return []byte("No invalid values found"), nil
+	}
+	return []byte(strings.Join(lines, "\n")), FindingsError{Count: len(errs), One: "invalid value", Many: "invalid values"}
+}
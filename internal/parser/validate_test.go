@@ -0,0 +1,140 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
+)
+
+const invalidConfig = `Port abc
+Host web
+    Compression maybe
+    ServerAliveInterval=-5
+    ControlPersist "10x"
+    Ciphers +aes256-ctr,arcfour
+    PubkeyAcceptedKeyTypes ssh-rsa,nope
+    LocalForward 8080
+Match host *.corp
+    StrictHostKeyChecking maybe
+Host ok
+    Port 22
+    ControlPersist 1h30m
+`
+
+func errorStrings(errs []Parser.ValueError) []string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func TestValidateSSHConfig(t *testing.T) {
+	errs, err := Parser.ValidateSSHConfig(invalidConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`1:6: Port "abc": expected an integer`,
+		`3:17: Host web: Compression "maybe": expected yes or no`,
+		`4:25: Host web: ServerAliveInterval "-5": expected a time interval: unexpected '-' at offset 0`,
+		`5:21: Host web: ControlPersist "10x": expected yes, no or a time interval: unknown unit 'x' at offset 2, use s, m, h, d or w`,
+		`6:25: Host web: Ciphers "+aes256-ctr,arcfour": unknown algorithm "arcfour"`,
+		`7:36: Host web: PubkeyAcceptedKeyTypes "ssh-rsa,nope": unknown algorithm "nope"`,
+		`8:22: Host web: LocalForward "8080": missing target, use [bind_address:]port host:hostport`,
+		`10:27: Match host *.corp: StrictHostKeyChecking "maybe": expected yes, no, ask, accept-new or off`,
+	}
+	if got := errorStrings(errs); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateSSHConfig() =\n%q\nwant\n%q", got, want)
+	}
+
+	if _, err := Parser.ValidateSSHConfig("Host \"unclosed\n"); err == nil {
+		t.Error("ValidateSSHConfig() expected an error for an unclosed quote")
+	}
+}
+
+// unbalancedQuotes would hide Port abc if a quote ran across lines.
+const unbalancedQuotes = `Host a
+    User "bob
+    Port abc
+    ProxyCommand "nc %h %p
+`
+
+func TestValidateSSHConfig_UnclosedQuotes(t *testing.T) {
+	errs, err := Parser.ValidateSSHConfig(unbalancedQuotes)
+	if want := []string{`3:10: Host a: Port "abc": expected an integer`}; !reflect.DeepEqual(errorStrings(errs), want) {
+		t.Errorf("ValidateSSHConfig() = %q, want %q", errorStrings(errs), want)
+	}
+	want := "line 2 column 10: unclosed quoted string\nline 4 column 18: unclosed quoted string"
+	if err == nil || err.Error() != want {
+		t.Errorf("ValidateSSHConfig() error = %v, want %q", err, want)
+	}
+
+	result, err := Parser.Process("TEXT", unbalancedQuotes, Cmd.Args{Command: Cmd.CommandValidate})
+	if err == nil || !strings.Contains(string(result), "Port") {
+		t.Errorf("Process() = %q, %v, want the invalid value and an error", result, err)
+	}
+
+	result, err = Parser.Process("TEXT", unbalancedQuotes, Cmd.Args{Command: Cmd.CommandValidate, Format: "json"})
+	if err == nil || err.Error() != "found 3 problems" || strings.Count(string(result), `"rule"`) != 3 {
+		t.Errorf("Process(-format json) = %s, %v, want 3 problems", result, err)
+	}
+}
+
+func TestValidateHostConfigs(t *testing.T) {
+	hostConfigs := Parser.GroupYAMLConfig(`
+Group db:
+  Hosts:
+    db:
+      config:
+        Port: abc
+Group web:
+  Hosts:
+    web:
+      config:
+        Compression: maybe
+        IdentityFile: [~/.ssh/a, ~/.ssh/b]
+`)
+	want := []string{
+		`Host db: Port "abc": expected an integer`,
+		`Host web: Compression "maybe": expected yes or no`,
+	}
+	if got := errorStrings(Parser.ValidateHostConfigs(hostConfigs)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateHostConfigs() = %q, want %q", got, want)
+	}
+}
+
+func TestProcess_Validate(t *testing.T) {
+	args := Cmd.Args{Command: Cmd.CommandValidate}
+	result, err := Parser.Process("TEXT", invalidConfig, args)
+	if err == nil || err.Error() != "found 8 invalid values" {
+		t.Errorf("Process() error = %v", err)
+	}
+	if len(result) == 0 {
+		t.Error("Process() returned no findings")
+	}
+
+	result, err = Parser.Process("TEXT", "Host ok\n    Port 22\n", args)
+	if err != nil || string(result) != "No invalid values found" {
+		t.Errorf("Process() = %q, %v", result, err)
+	}
+}
@@ -18,6 +18,7 @@ package parser
 
 import (
 	"fmt"
+	"os"
 	"slices"
 
 	Define "github.com/soulteary/ssh-config/v2/internal/define"
@@ -42,6 +43,23 @@ func mapToMapSlice(m map[string]string) yaml.MapSlice {
 	return out
 }
 
+// configToMapSlice 与 mapToMapSlice 相同，但可重复的指令以列表形式输出。
+func configToMapSlice(config map[string]string, lists map[string][]string) yaml.MapSlice {
+	if len(lists) == 0 {
+		return mapToMapSlice(config)
+	}
+	orderMaps := Fn.GetOrderConfig(config, lists)
+	out := make(yaml.MapSlice, 0, len(orderMaps.Keys))
+	for _, k := range orderMaps.Keys {
+		if values, ok := lists[k]; ok {
+			out = append(out, yaml.MapItem{Key: k, Value: values})
+			continue
+		}
+		out = append(out, yaml.MapItem{Key: k, Value: config[k]})
+	}
+	return out
+}
+
 // hostConfigToMapSlice 将 HostConfig 转为固定字段顺序的 MapSlice（config 按 key 排序）。
 // 键名使用小写 "config" 以与 yaml.v2 对无 tag 字段的默认 unmarshal 行为一致。
 func hostConfigToMapSlice(c Define.HostConfig) yaml.MapSlice {
@@ -52,9 +70,37 @@ func hostConfigToMapSlice(c Define.HostConfig) yaml.MapSlice {
 	if c.Notes != "" {
 		items = append(items, yaml.MapItem{Key: "Notes", Value: c.Notes})
 	}
+	if len(c.Patterns) > 0 {
+		patterns := make([]yaml.MapSlice, 0, len(c.Patterns))
+		for _, pattern := range c.Patterns {
+			item := yaml.MapSlice{{Key: "Pattern", Value: pattern.Pattern}}
+			if pattern.Negate {
+				item = append(item, yaml.MapItem{Key: "Negate", Value: true})
+			}
+			if pattern.Wildcard {
+				item = append(item, yaml.MapItem{Key: "Wildcard", Value: true})
+			}
+			patterns = append(patterns, item)
+		}
+		items = append(items, yaml.MapItem{Key: "Patterns", Value: patterns})
+	}
 	// 仅当 Config 非 nil 时输出 config，以保证 round-trip 后 nil 仍为 nil、空 map 仍为空 map
-	if c.Config != nil {
-		items = append(items, yaml.MapItem{Key: "config", Value: mapToMapSlice(c.Config)})
+	if len(c.Match) > 0 {
+		conditions := make([]yaml.MapSlice, 0, len(c.Match))
+		for _, condition := range c.Match {
+			item := yaml.MapSlice{{Key: "Criterion", Value: condition.Criterion}}
+			if condition.Negate {
+				item = append(item, yaml.MapItem{Key: "Negate", Value: true})
+			}
+			if condition.Argument != "" {
+				item = append(item, yaml.MapItem{Key: "Argument", Value: condition.Argument})
+			}
+			conditions = append(conditions, item)
+		}
+		items = append(items, yaml.MapItem{Key: "Match", Value: conditions})
+	}
+	if c.Config != nil || len(c.Lists) > 0 {
+		items = append(items, yaml.MapItem{Key: "config", Value: configToMapSlice(c.Config, c.Lists)})
 	}
 	if c.Extra.Prefix != "" {
 		items = append(items, yaml.MapItem{Key: "Extra", Value: yaml.MapSlice{
@@ -64,78 +110,212 @@ func hostConfigToMapSlice(c Define.HostConfig) yaml.MapSlice {
 	return items
 }
 
+// globalToMapSlice 合并所有 Host * 块的指令。
+func globalToMapSlice(globalConfigs []Define.HostConfig) yaml.MapSlice {
+	global := Define.HostConfig{}
+	for _, config := range globalConfigs {
+		for key := range config.Config {
+			global.SetValues(key, config.Values(key))
+		}
+		for key := range config.Lists {
+			global.SetValues(key, config.Values(key))
+		}
+	}
+	return configToMapSlice(global.Config, global.Lists)
+}
+
+// uniqueGroupName 返回主机对应的分组名，重复保留的主机依次加上序号。
+func uniqueGroupName(host string, used map[string]yaml.MapSlice) string {
+	groupName := fmt.Sprintf("Group %s", host)
+	for index := 2; ; index++ {
+		if _, ok := used[groupName]; !ok {
+			return groupName
+		}
+		groupName = fmt.Sprintf("Group %s #%d", host, index)
+	}
+}
+
+// groupToMapSlice 将单个主机输出为 "Group <name>" 的内容。
+func groupToMapSlice(config Define.HostConfig) yaml.MapSlice {
+	groupHostConfig := Define.HostConfig{}
+	if config.Notes != "" {
+		groupHostConfig.Notes = config.Notes
+	}
+	// 模式规则（多个模式、否定或通配符）额外输出结构化的 Patterns
+	if !Define.IsConcreteHost(config.Name) {
+		groupHostConfig.Patterns = config.HostPatterns()
+	}
+	groupHostConfig.Config = config.Config
+	groupHostConfig.Lists = config.Lists
+	hostConfig := hostConfigToMapSlice(groupHostConfig)
+	groupItems := yaml.MapSlice{
+		{Key: "Hosts", Value: yaml.MapSlice{
+			{Key: config.Name, Value: hostConfig},
+		}},
+	}
+	if config.Extra.Prefix != "" {
+		groupItems = append(yaml.MapSlice{{Key: "Prefix", Value: config.Extra.Prefix}}, groupItems...)
+	}
+	return groupItems
+}
+
+func matchesToSlice(matchConfigs []Define.HostConfig) []yaml.MapSlice {
+	matchItems := make([]yaml.MapSlice, 0, len(matchConfigs))
+	for _, config := range matchConfigs {
+		matchItems = append(matchItems, hostConfigToMapSlice(config))
+	}
+	return matchItems
+}
+
 func ConvertToYAML(hostConfigs []Define.HostConfig) []byte {
+	return ConvertToYAMLWithOptions(hostConfigs, Options{})
+}
+
+func ConvertToYAMLWithOptions(hostConfigs []Define.HostConfig, options Options) []byte {
 	root := make(yaml.MapSlice, 0)
 
-	globalConfigs := Fn.FindGlobalConfig(hostConfigs)
-	if len(globalConfigs) > 0 {
-		global := make(map[string]string)
-		for _, config := range globalConfigs {
-			for key, value := range config.Config {
-				global[key] = value
-			}
+	includeConfigs := Fn.FindIncludeConfig(hostConfigs)
+	if len(includeConfigs) > 0 {
+		var patterns []string
+		for _, config := range includeConfigs {
+			patterns = append(patterns, config.Include...)
 		}
-		root = append(root, yaml.MapItem{Key: "global", Value: mapToMapSlice(global)})
+		root = append(root, yaml.MapItem{Key: "include", Value: patterns})
 	}
 
-	normalConfigs := Fn.FindNormalConfig(hostConfigs)
-	if len(normalConfigs) > 0 {
-		groupNames := make([]string, 0, len(normalConfigs))
-		groupsData := make(map[string]yaml.MapSlice)
-		for _, config := range normalConfigs {
-			groupName := fmt.Sprintf("Group %s", config.Name)
-			groupNames = append(groupNames, groupName)
-			groupHostConfig := Define.HostConfig{}
-			if config.Notes != "" {
-				groupHostConfig.Notes = config.Notes
-			}
-			groupHostConfig.Config = config.Config
-			hostConfig := hostConfigToMapSlice(groupHostConfig)
-			groupItems := yaml.MapSlice{
-				{Key: "Hosts", Value: yaml.MapSlice{
-					{Key: config.Name, Value: hostConfig},
-				}},
+	if options.KeepOrder {
+		root = append(root, orderedYAMLItems(hostConfigs)...)
+	} else {
+		globalConfigs := Fn.FindGlobalConfig(hostConfigs)
+		if len(globalConfigs) > 0 {
+			root = append(root, yaml.MapItem{Key: "global", Value: globalToMapSlice(globalConfigs)})
+		}
+
+		normalConfigs := Fn.FindNormalConfig(hostConfigs)
+		if len(normalConfigs) > 0 {
+			groupNames := make([]string, 0, len(normalConfigs))
+			groupsData := make(map[string]yaml.MapSlice)
+			for _, config := range normalConfigs {
+				groupName := uniqueGroupName(config.Name, groupsData)
+				groupNames = append(groupNames, groupName)
+				groupsData[groupName] = groupToMapSlice(config)
 			}
-			if config.Extra.Prefix != "" {
-				groupItems = append(yaml.MapSlice{{Key: "Prefix", Value: config.Extra.Prefix}}, groupItems...)
+			slices.Sort(groupNames)
+			for _, groupName := range groupNames {
+				root = append(root, yaml.MapItem{Key: groupName, Value: groupsData[groupName]})
 			}
-			groupsData[groupName] = groupItems
 		}
-		slices.Sort(groupNames)
-		for _, groupName := range groupNames {
-			root = append(root, yaml.MapItem{Key: groupName, Value: groupsData[groupName]})
+
+		// Match 块按原顺序输出为列表，ssh 自上而下依次判断
+		matchConfigs := Fn.FindMatchConfig(hostConfigs)
+		if len(matchConfigs) > 0 {
+			root = append(root, yaml.MapItem{Key: "match", Value: matchesToSlice(matchConfigs)})
 		}
 	}
 
 	yamlData, err := yaml.Marshal(root)
 	if err != nil {
-		fmt.Println("Error marshaling to YAML:", err)
+		fmt.Fprintln(os.Stderr, "Error marshaling to YAML:", err)
 		return nil
 	}
 	return yamlData
 }
 
+// orderedYAMLItems 按原顺序输出 global、分组与 Match 块：
+// 出现在任何主机之前的 Match 块写入顶层 match，其余写入前一个分组的 Match。
+func orderedYAMLItems(hostConfigs []Define.HostConfig) yaml.MapSlice {
+	items := make(yaml.MapSlice, 0)
+	globalConfigs := Fn.FindGlobalConfig(hostConfigs)
+	var pending []Define.HostConfig
+	var topMatches []Define.HostConfig
+	matchIndex := -1
+	lastGroup := -1
+	globalDone := false
+	groupNames := make(map[string]yaml.MapSlice)
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if lastGroup >= 0 {
+			group := items[lastGroup].Value.(yaml.MapSlice)
+			items[lastGroup].Value = append(group, yaml.MapItem{Key: "Match", Value: matchesToSlice(pending)})
+		} else {
+			// 顶层只有一个 match 列表，分散在 Host * 两侧的 Match 块合并输出
+			topMatches = append(topMatches, pending...)
+			if matchIndex < 0 {
+				items = append(items, yaml.MapItem{})
+				matchIndex = len(items) - 1
+			}
+			items[matchIndex] = yaml.MapItem{Key: "match", Value: matchesToSlice(topMatches)}
+		}
+		pending = nil
+	}
+
+	for _, config := range hostConfigs {
+		if config.IsInclude() {
+			continue
+		}
+		if config.IsMatch() {
+			pending = append(pending, config)
+			continue
+		}
+		flush()
+		if config.Name == "*" {
+			if !globalDone {
+				items = append(items, yaml.MapItem{Key: "global", Value: globalToMapSlice(globalConfigs)})
+				globalDone = true
+			}
+			continue
+		}
+		groupName := uniqueGroupName(config.Name, groupNames)
+		groupNames[groupName] = nil
+		items = append(items, yaml.MapItem{Key: groupName, Value: groupToMapSlice(config)})
+		lastGroup = len(items) - 1
+	}
+	flush()
+	return items
+}
+
 type YAMLHostConfigGroup struct {
 	Comments []string
 	Config   map[string]string
 }
 
 func GroupYAMLConfig(input string) []Define.HostConfig {
+	return GroupYAMLConfigWithOptions(input, Options{})
+}
+
+func GroupYAMLConfigWithOptions(input string, options Options) []Define.HostConfig {
 	yamlConfig := Fn.GetYamlData(input)
+	normalizeYAML(&yamlConfig, options)
 
 	var hostConfigs []Define.HostConfig
 
-	if yamlConfig.Global != nil {
-		hostConfig := Define.HostConfig{
-			Name:   "*",
-			Config: make(map[string]string),
-		}
-		for key, value := range yamlConfig.Global {
-			hostConfig.Config[key] = value
+	if len(yamlConfig.Include) > 0 {
+		hostConfigs = append(hostConfigs, Define.HostConfig{Include: yamlConfig.Include})
+	}
+
+	if options.KeepOrder {
+		keys, hostOrder := yamlDocumentOrder(input)
+		for _, key := range keys {
+			switch key {
+			case "include", "default":
+			case "global":
+				hostConfigs = append(hostConfigs, yamlGlobalConfig(yamlConfig)...)
+			case "match":
+				hostConfigs = append(hostConfigs, yamlMatchConfigs(yamlConfig.Match)...)
+			default:
+				if _, ok := yamlConfig.Groups[key]; ok {
+					hostConfigs = append(hostConfigs, yamlGroupConfigs(yamlConfig, key, hostOrder[key])...)
+				}
+			}
 		}
-		hostConfigs = append(hostConfigs, hostConfig)
+		return hostConfigs
 	}
 
+	hostConfigs = append(hostConfigs, yamlGlobalConfig(yamlConfig)...)
+
 	if yamlConfig.Groups != nil {
 		keys := make([]string, 0)
 		for key := range yamlConfig.Groups {
@@ -144,45 +324,105 @@ func GroupYAMLConfig(input string) []Define.HostConfig {
 		slices.Sort(keys)
 
 		for _, groupName := range keys {
-			groupConfig := yamlConfig.Groups[groupName]
-
-			prefix := ""
-			if groupConfig.Prefix != "" {
-				prefix = groupConfig.Prefix
-			}
-
-			hostNames := make([]string, 0, len(groupConfig.Hosts))
-			for hostName := range groupConfig.Hosts {
+			hostNames := make([]string, 0, len(yamlConfig.Groups[groupName].Hosts))
+			for hostName := range yamlConfig.Groups[groupName].Hosts {
 				hostNames = append(hostNames, hostName)
 			}
 			slices.Sort(hostNames)
+			hostConfigs = append(hostConfigs, yamlGroupConfigs(yamlConfig, groupName, hostNames)...)
+		}
+	}
 
-			for _, hostName := range hostNames {
-				originConfig := groupConfig.Hosts[hostName]
-				hostConfig := originConfig
-				hostConfig.Name = hostName
-				hostConfig.Extra.Prefix = prefix
-				if hostConfig.Config != nil {
-					if groupConfig.Common != nil {
-						for key, value := range groupConfig.Common {
-
-							if _, ok := hostConfig.Config[key]; !ok {
-								hostConfig.Config[key] = value
-							}
-						}
-					}
+	hostConfigs = append(hostConfigs, yamlMatchConfigs(yamlConfig.Match)...)
+	return hostConfigs
+}
 
-					if yamlConfig.Default != nil {
-						for key, value := range yamlConfig.Default {
-							if _, ok := hostConfig.Config[key]; !ok {
-								hostConfig.Config[key] = value
-							}
-						}
+// yamlDocumentOrder 返回顶层键以及每个分组下 Hosts 的书写顺序。
+func yamlDocumentOrder(input string) ([]string, map[string][]string) {
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(input), &document); err != nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(document))
+	hostOrder := make(map[string][]string)
+	for _, item := range document {
+		key := fmt.Sprint(item.Key)
+		keys = append(keys, key)
+		group, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		for _, field := range group {
+			hosts, ok := field.Value.(yaml.MapSlice)
+			if fmt.Sprint(field.Key) != "Hosts" || !ok {
+				continue
+			}
+			for _, host := range hosts {
+				hostOrder[key] = append(hostOrder[key], fmt.Sprint(host.Key))
+			}
+		}
+	}
+	return keys, hostOrder
+}
+
+func yamlGlobalConfig(yamlConfig Define.YAMLOutput) []Define.HostConfig {
+	if yamlConfig.Global == nil {
+		return nil
+	}
+	hostConfig := Define.HostConfig{
+		Name:   "*",
+		Config: make(map[string]string),
+	}
+	for key, value := range yamlConfig.Global {
+		hostConfig.Config[key] = value
+	}
+	for key, values := range yamlConfig.GlobalLists {
+		hostConfig.SetValues(key, values)
+	}
+	return []Define.HostConfig{hostConfig}
+}
+
+// yamlGroupConfigs 展开分组下的主机（继承 Common 与 default），随后是分组内的 Match 块。
+func yamlGroupConfigs(yamlConfig Define.YAMLOutput, groupName string, hostNames []string) []Define.HostConfig {
+	groupConfig := yamlConfig.Groups[groupName]
+	var hostConfigs []Define.HostConfig
+
+	for _, hostName := range hostNames {
+		originConfig, ok := groupConfig.Hosts[hostName]
+		if !ok {
+			continue
+		}
+		hostConfig := originConfig
+		hostConfig.Name = hostName
+		if len(hostConfig.Patterns) > 0 {
+			hostConfig.Name = Define.FormatHostPatterns(hostConfig.Patterns)
+			hostConfig.Patterns = nil
+		}
+		hostConfig.Extra.Prefix = groupConfig.Prefix
+		if hostConfig.Config != nil {
+			common := Define.HostConfig{Config: groupConfig.Common, Lists: groupConfig.CommonLists}
+			defaults := Define.HostConfig{Config: yamlConfig.Default, Lists: yamlConfig.DefaultLists}
+			for _, inherited := range []Define.HostConfig{common, defaults} {
+				for _, key := range Fn.GetOrderConfig(inherited.Config, inherited.Lists).Keys {
+					if !hasKey(SSHHostConfigGroup{Config: hostConfig.Config, Lists: hostConfig.Lists}, key) {
+						hostConfig.SetValues(key, inherited.Values(key))
 					}
-					hostConfigs = append(hostConfigs, hostConfig)
 				}
 			}
+			hostConfigs = append(hostConfigs, hostConfig)
+		}
+	}
+	return append(hostConfigs, yamlMatchConfigs(groupConfig.Match)...)
+}
+
+func yamlMatchConfigs(matchConfigs []Define.HostConfig) []Define.HostConfig {
+	var hostConfigs []Define.HostConfig
+	for _, matchConfig := range matchConfigs {
+		if !matchConfig.IsMatch() {
+			continue
 		}
+		matchConfig.Name = ""
+		hostConfigs = append(hostConfigs, matchConfig)
 	}
 	return hostConfigs
 }
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 
 	Define "github.com/soulteary/ssh-config/v2/internal/define"
@@ -257,7 +258,7 @@ func TestGroupYAMLConfig(t *testing.T) {
 		t.Errorf("Empty input should return empty result, got %v", result1)
 	}
 
-	// Test case 2: Only global config
+	// Test case 2: Only global config, keywords get their canonical spelling
 	input2 := `
 global:
   user: globaluser
@@ -267,8 +268,8 @@ global:
 		{
 			Name: "*",
 			Config: map[string]string{
-				"user": "globaluser",
-				"port": "22",
+				"User": "globaluser",
+				"Port": "22",
 			},
 		},
 	}
@@ -594,3 +595,173 @@ func TestYAMLConfigWithGroupCommon(t *testing.T) {
 		}
 	}
 }
+
+func TestYAMLConfig_MultiValueDirectives(t *testing.T) {
+	input := `global:
+  IdentityFile:
+    - ~/.ssh/id_ed25519
+    - ~/.ssh/id_rsa
+default:
+  SendEnv:
+    - LANG
+    - LC_*
+Group tunnels:
+  Common:
+    LocalForward:
+      - 8080 localhost:80
+      - 8443 localhost:443
+  Hosts:
+    tunnel1:
+      config:
+        HostName: tunnel1.example.com
+    tunnel2:
+      config:
+        HostName: tunnel2.example.com
+        LocalForward: 9000 localhost:9000
+`
+	result := Parser.GroupYAMLConfig(input)
+	if len(result) != 3 {
+		t.Fatalf("GroupYAMLConfig() = %v, want 3 hosts", result)
+	}
+	if got := result[0].Values("IdentityFile"); !reflect.DeepEqual(got, []string{"~/.ssh/id_ed25519", "~/.ssh/id_rsa"}) {
+		t.Errorf("GroupYAMLConfig() global IdentityFile = %v", got)
+	}
+	tunnel1 := result[1]
+	if got := tunnel1.Values("LocalForward"); !reflect.DeepEqual(got, []string{"8080 localhost:80", "8443 localhost:443"}) {
+		t.Errorf("GroupYAMLConfig() tunnel1 LocalForward = %v", got)
+	}
+	if got := tunnel1.Values("SendEnv"); !reflect.DeepEqual(got, []string{"LANG", "LC_*"}) {
+		t.Errorf("GroupYAMLConfig() tunnel1 SendEnv = %v", got)
+	}
+	// a host value shadows the whole inherited list
+	if got := result[2].Values("LocalForward"); !reflect.DeepEqual(got, []string{"9000 localhost:9000"}) {
+		t.Errorf("GroupYAMLConfig() tunnel2 LocalForward = %v", got)
+	}
+
+	roundTrip := Parser.GroupYAMLConfig(string(Parser.ConvertToYAML(result)))
+	if len(roundTrip) != len(result) {
+		t.Fatalf("ConvertToYAML() round-trip = %v, want %v", roundTrip, result)
+	}
+	for i := range result {
+		if !reflect.DeepEqual(roundTrip[i].Lists, result[i].Lists) {
+			t.Errorf("ConvertToYAML() round-trip Lists = %v, want %v", roundTrip[i].Lists, result[i].Lists)
+		}
+	}
+}
+
+func TestYAMLConfig_Match(t *testing.T) {
+	input := []Define.HostConfig{
+		{Name: "foo", Config: map[string]string{"HostName": "foo.com"}},
+		{
+			Notes:  "office network",
+			Match:  []Define.MatchCondition{{Criterion: "host", Argument: "*.corp"}, {Criterion: "exec", Negate: true, Argument: "test -f /tmp/vpn"}},
+			Config: map[string]string{"User": "admin"},
+		},
+		{
+			Match:  []Define.MatchCondition{{Criterion: "all"}},
+			Config: map[string]string{"ForwardAgent": "no"},
+		},
+	}
+	result := Parser.ConvertToYAML(input)
+	got := Parser.GroupYAMLConfig(string(result))
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("ConvertToYAML() round-trip = %+v, want %+v\n%s", got, input, result)
+	}
+}
+
+func TestYAMLConfig_Include(t *testing.T) {
+	input := []Define.HostConfig{
+		{Include: []string{"config.d/*", "extra.conf"}},
+		{Name: "foo", Config: map[string]string{"HostName": "foo.com"}},
+	}
+	result := Parser.ConvertToYAML(input)
+	if !strings.HasPrefix(string(result), "include:\n- config.d/*\n- extra.conf\n") {
+		t.Errorf("ConvertToYAML() = %s", result)
+	}
+	if got := Parser.GroupYAMLConfig(string(result)); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupYAMLConfig() = %+v, want %+v", got, input)
+	}
+}
+
+func TestYAMLConfig_KeepOrder(t *testing.T) {
+	options := Parser.Options{KeepOrder: true}
+	input := []Define.HostConfig{
+		{Name: "zeta", Config: map[string]string{"HostName": "zeta.com"}},
+		{
+			Match:  []Define.MatchCondition{{Criterion: "host", Argument: "zeta"}},
+			Config: map[string]string{"User": "admin"},
+		},
+		{Name: "alpha", Config: map[string]string{"HostName": "alpha.com"}},
+		{Name: "*", Config: map[string]string{"User": "nobody"}},
+	}
+	result := Parser.ConvertToYAMLWithOptions(input, options)
+	want := "Group zeta:\n  Hosts:\n    zeta:\n      config:\n        HostName: zeta.com\n  Match:\n  - Match:\n    - Criterion: host\n      Argument: zeta\n    config:\n      User: admin\n" +
+		"Group alpha:\n  Hosts:\n    alpha:\n      config:\n        HostName: alpha.com\n" +
+		"global:\n  User: nobody\n"
+	if string(result) != want {
+		t.Errorf("ConvertToYAMLWithOptions() = %q, want %q", result, want)
+	}
+	if got := Parser.GroupYAMLConfigWithOptions(string(result), options); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupYAMLConfigWithOptions() = %+v, want %+v", got, input)
+	}
+}
+
+func TestGroupYAMLConfig_KeepOrderHosts(t *testing.T) {
+	input := `
+default:
+  Port: 22
+Group work:
+  Hosts:
+    web:
+      config:
+        HostName: web.example.com
+    db:
+      config:
+        HostName: db.example.com
+global:
+  User: me
+`
+	got := Parser.GroupYAMLConfigWithOptions(input, Parser.Options{KeepOrder: true})
+	names := make([]string, 0, len(got))
+	for _, config := range got {
+		names = append(names, config.Name)
+	}
+	if want := []string{"web", "db", "*"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GroupYAMLConfigWithOptions() order = %v, want %v", names, want)
+	}
+	if got[0].Config["Port"] != "22" {
+		t.Errorf("GroupYAMLConfigWithOptions() lost default values: %+v", got[0])
+	}
+}
+
+func TestYAMLConfig_HostPatterns(t *testing.T) {
+	input := []Define.HostConfig{
+		{Name: "web1 !web3 *.dev", Config: map[string]string{"User": "deploy"}},
+	}
+	result := Parser.ConvertToYAML(input)
+	want := `Group web1 !web3 *.dev:
+  Hosts:
+    web1 !web3 *.dev:
+      Patterns:
+      - Pattern: web1
+      - Pattern: web3
+        Negate: true
+      - Pattern: '*.dev'
+        Wildcard: true
+      config:
+        User: deploy
+`
+	if string(result) != want {
+		t.Errorf("ConvertToYAML() = %q, want %q", result, want)
+	}
+	if got := Parser.GroupYAMLConfig(string(result)); !reflect.DeepEqual(got, input) {
+		t.Errorf("GroupYAMLConfig() = %+v, want %+v", got, input)
+	}
+
+	if patterns := input[0].HostPatterns(); len(patterns) != 3 || !patterns[1].Negate || !patterns[2].Wildcard {
+		t.Errorf("HostPatterns() = %+v", patterns)
+	}
+	if !Define.IsConcreteHost("web1") || Define.IsConcreteHost("web?") || Define.IsConcreteHost("a b") {
+		t.Error("IsConcreteHost() misclassified a host")
+	}
+}
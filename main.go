@@ -17,6 +17,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,10 +37,22 @@ type Dependencies struct {
 	Process               func(string, string, Cmd.Args) ([]byte, error)
 	CheckUseStdin         func() bool
 	UserHomeDir           func() (string, error)
+	// Fix returns the fixed config and a report of lint -fix, Modernize
+	// those of modernize.
+	Fix        func(string, string, Cmd.Args) ([]byte, []byte, error)
+	Modernize  func(string, string, Cmd.Args) ([]byte, []byte, error)
+	SourceFile func(string) (string, error)
+	Confirm    func(string) bool
 }
 
 func Run(args Cmd.Args, deps Dependencies) error {
-	isValid, notValidReason := Cmd.CheckConvertArgvValid(args)
+	isValid, notValidReason := Cmd.CheckCommandArgvValid(args)
+	if !isValid {
+		deps.Println(notValidReason)
+		return fmt.Errorf("%s", notValidReason)
+	}
+
+	isValid, notValidReason = Cmd.CheckConvertArgvValid(args)
 	if !isValid {
 		deps.Println(notValidReason)
 		return fmt.Errorf("%s", notValidReason)
@@ -64,19 +77,37 @@ func Run(args Cmd.Args, deps Dependencies) error {
 		userInput = string(content)
 	}
 
+	args.Stdin = pipeMode
 	fileType := Fn.DetectStringType(userInput)
+	if args.Fix {
+		return runFix(args, deps, deps.Fix, pipeMode, fileType, userInput)
+	}
+	if args.Command == Cmd.CommandModernize {
+		return runFix(args, deps, deps.Modernize, pipeMode, fileType, userInput)
+	}
 	result, err := deps.Process(fileType, userInput, args)
+	// with -format the problems are in the report and with -check in the
+	// diff, the error only sets the exit status
+	problems := err
+	if (args.Format != "" || args.Check) && len(result) > 0 {
+		err = nil
+	}
 	if err != nil {
-		deps.Println("Error parsing config:", err)
+		// commands such as validate report their findings along with the error
+		if len(result) > 0 {
+			deps.Println(string(result))
+		}
+		printError(deps, args.Command, err)
 		return err
 	}
 
+	// fmt output ends with a newline, which Println adds
 	if pipeMode {
-		deps.Println(string(result))
+		deps.Println(string(Fn.TidyLastEmptyLines(result)))
 	} else {
 		if args.Dest == "" {
-			deps.Println(string(result))
-			return nil
+			deps.Println(string(Fn.TidyLastEmptyLines(result)))
+			return problems
 		}
 
 		err := deps.SaveFile(args.Dest, result)
@@ -88,9 +119,60 @@ func Run(args Cmd.Args, deps Dependencies) error {
 		deps.Println("File path:", args.Dest)
 	}
 
+	return problems
+}
+
+// runFix prints the findings of lint -fix or the changes of modernize and
+// the diff of their fixes, then writes the fixes to the source file when
+// -write is given or the user confirms.
+func runFix(args Cmd.Args, deps Dependencies, fix func(string, string, Cmd.Args) ([]byte, []byte, error), pipeMode bool, fileType string, userInput string) error {
+	fixed, report, lintErr := fix(fileType, userInput, args)
+	if fixed == nil {
+		deps.Println("Error parsing config:", lintErr)
+		return lintErr
+	}
+	deps.Println(string(report))
+
+	if string(fixed) != userInput {
+		if pipeMode {
+			deps.Println("The fixes of a config read from stdin are not written, use -src")
+			return fmt.Errorf("fixes not written")
+		}
+		path, err := deps.SourceFile(args.Src)
+		if err != nil {
+			deps.Println("Error saving file:", err)
+			return err
+		}
+		if !args.Write && !deps.Confirm(fmt.Sprintf("Write the fixes to %s?", path)) {
+			deps.Println("The fixes were not written")
+			return fmt.Errorf("fixes not written")
+		}
+		if err := deps.SaveFile(path, fixed); err != nil {
+			deps.Println("Error saving file:", err)
+			return err
+		}
+		deps.Println("File has been saved successfully")
+		deps.Println("File path:", path)
+	}
+
+	if lintErr != nil {
+		printError(deps, args.Command, lintErr)
+		return lintErr
+	}
 	return nil
 }
 
+// printError prints the problems command found in the config under its
+// name, and any other error as a config that can not be read.
+func printError(deps Dependencies, command string, err error) {
+	var findings Parser.FindingsError
+	if errors.As(err, &findings) {
+		deps.Println(command+":", err)
+		return
+	}
+	deps.Println("Error parsing config:", err)
+}
+
 func MainWithDependencies(exit func(int), userHomeDir func() (string, error)) {
 	deps := Dependencies{
 		StdinStat:             os.Stdin.Stat,
@@ -101,6 +183,10 @@ func MainWithDependencies(exit func(int), userHomeDir func() (string, error)) {
 		GetUserInputFromStdin: Fn.GetUserInputFromStdin,
 		Process:               Parser.Process,
 		CheckUseStdin:         func() bool { return Cmd.CheckUseStdin(os.Stdin.Stat) },
+		Fix:                   Parser.LintFix,
+		Modernize:             Parser.Modernize,
+		SourceFile:            Fn.SourceFile,
+		Confirm:               Fn.Confirm,
 	}
 	args := Cmd.ParseArgs()
 
@@ -115,7 +201,7 @@ func MainWithDependencies(exit func(int), userHomeDir func() (string, error)) {
 	}
 
 	// default to YAML when no conversion flag is provided
-	if !(args.ToYAML || args.ToJSON || args.ToSSH) {
+	if args.Command == "" && !(args.ToYAML || args.ToJSON || args.ToSSH) {
 		args.ToYAML = true
 	}
 
@@ -19,12 +19,17 @@ package main
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
+	"reflect"
+	"strings"
 	"testing"
 
 	Cmd "github.com/soulteary/ssh-config/v2/cmd"
+	Fn "github.com/soulteary/ssh-config/v2/internal/fn"
+	Parser "github.com/soulteary/ssh-config/v2/internal/parser"
 )
 
 func TestRun(t *testing.T) {
@@ -63,6 +68,26 @@ func TestRun(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Unknown command",
+			args: Cmd.Args{ToYAML: true, Operands: []string{"bogus"}},
+			deps: Dependencies{
+				Println:       func(...interface{}) (int, error) { return 0, nil },
+				CheckUseStdin: func() bool { return false },
+			},
+			wantErr: true,
+		},
+		{
+			name: "Resolve without format flag",
+			args: Cmd.Args{Command: Cmd.CommandResolve, Operands: []string{"work"}},
+			deps: Dependencies{
+				Println:               func(...interface{}) (int, error) { return 0, nil },
+				GetUserInputFromStdin: func() string { return string(sshContent) },
+				Process:               func(string, string, Cmd.Args) ([]byte, error) { return []byte("host work"), nil },
+				CheckUseStdin:         func() bool { return true },
+			},
+			wantErr: false,
+		},
 		{
 			name: "Pipe mode",
 			args: Cmd.Args{ToSSH: true},
@@ -183,6 +208,187 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRun_ReportsFindingsWithError(t *testing.T) {
+	var printed []string
+	deps := Dependencies{
+		Println: func(a ...interface{}) (int, error) {
+			printed = append(printed, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+			return 0, nil
+		},
+		GetUserInputFromStdin: func() string { return "Port abc" },
+		Process: func(string, string, Cmd.Args) ([]byte, error) {
+			return []byte(`1:6: Port "abc": expected an integer`), Parser.FindingsError{Count: 1, One: "invalid value", Many: "invalid values"}
+		},
+		CheckUseStdin: func() bool { return true },
+	}
+	if err := Run(Cmd.Args{Command: Cmd.CommandValidate}, deps); err == nil {
+		t.Fatal("Run() expected an error")
+	}
+	want := []string{`1:6: Port "abc": expected an integer`, "validate: found 1 invalid value"}
+	if !reflect.DeepEqual(printed, want) {
+		t.Errorf("printed %q, want %q", printed, want)
+	}
+}
+
+func TestRun_FormatPrintsOnlyTheReport(t *testing.T) {
+	var printed []string
+	var got Cmd.Args
+	deps := Dependencies{
+		Println: func(a ...interface{}) (int, error) {
+			printed = append(printed, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+			return 0, nil
+		},
+		GetUserInputFromStdin: func() string { return "Port abc" },
+		Process: func(_ string, _ string, args Cmd.Args) ([]byte, error) {
+			got = args
+			return []byte(`[{"line":1}]`), errors.New("found 1 problem")
+		},
+		CheckUseStdin: func() bool { return true },
+	}
+	err := Run(Cmd.Args{Command: Cmd.CommandValidate, Format: "json"}, deps)
+	if err == nil || err.Error() != "found 1 problem" {
+		t.Errorf("Run() error = %v", err)
+	}
+	if !got.Stdin {
+		t.Error("Process() args.Stdin = false for piped input")
+	}
+	if want := []string{`[{"line":1}]`}; !reflect.DeepEqual(printed, want) {
+		t.Errorf("printed %q, want %q", printed, want)
+	}
+}
+
+func TestRun_Fmt(t *testing.T) {
+	var printed []string
+	deps := Dependencies{
+		Println: func(a ...interface{}) (int, error) {
+			printed = append(printed, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+			return 0, nil
+		},
+		GetUserInputFromStdin: func() string { return "host web\n  user www\n" },
+		Process:               Parser.Process,
+		CheckUseStdin:         func() bool { return true },
+	}
+	if err := Run(Cmd.Args{Command: Cmd.CommandFmt}, deps); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"Host web\n    User www"}; !reflect.DeepEqual(printed, want) {
+		t.Errorf("printed %q, want %q", printed, want)
+	}
+
+	printed = nil
+	err := Run(Cmd.Args{Command: Cmd.CommandFmt, Check: true}, deps)
+	if err == nil || err.Error() != "stdin is not formatted" {
+		t.Errorf("Run() error = %v", err)
+	}
+	if len(printed) != 1 || !strings.HasSuffix(printed[0], "+    User www\nstdin is not formatted") {
+		t.Errorf("printed %q, want only the diff", printed)
+	}
+}
+
+func TestRun_FmtCheckStdin(t *testing.T) {
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	for _, input := range []string{"Host a\n    User x\n", "Host a\r\n    User x\r\n"} {
+		r, w, _ := os.Pipe()
+		os.Stdin = r
+		go func() {
+			io.WriteString(w, input)
+			w.Close()
+		}()
+
+		var printed []string
+		deps := Dependencies{
+			Println: func(a ...interface{}) (int, error) {
+				printed = append(printed, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
+				return 0, nil
+			},
+			GetUserInputFromStdin: Fn.GetUserInputFromStdin,
+			Process:               Parser.Process,
+			CheckUseStdin:         func() bool { return true },
+		}
+		if err := Run(Cmd.Args{Command: Cmd.CommandFmt, Check: true}, deps); err != nil {
+			t.Errorf("Run(%q) error = %v, printed %q", input, err, printed)
+		}
+		if want := []string{"stdin is formatted"}; !reflect.DeepEqual(printed, want) {
+			t.Errorf("Run(%q) printed %q, want %q", input, printed, want)
+		}
+	}
+}
+
+func TestRun_Fix(t *testing.T) {
+	input := "Host web\n    IdentityFile ~/.ssh/web\n"
+	tests := []struct {
+		name      string
+		write     bool
+		answer    bool
+		wantSaved bool
+		wantErr   bool
+	}{
+		{name: "Confirmed", answer: true, wantSaved: true},
+		{name: "Declined", wantErr: true},
+		{name: "Write without asking", write: true, wantSaved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved string
+			var asked bool
+			deps := Dependencies{
+				Println:       func(a ...interface{}) (int, error) { return 0, nil },
+				GetContent:    func(string) ([]byte, error) { return []byte(input), nil },
+				CheckUseStdin: func() bool { return false },
+				Fix: func(fileType, userInput string, args Cmd.Args) ([]byte, []byte, error) {
+					return []byte(userInput + "    IdentitiesOnly yes\n"), []byte("diff"), nil
+				},
+				SourceFile: func(src string) (string, error) { return src + "/config", nil },
+				Confirm: func(string) bool {
+					asked = true
+					return tt.answer
+				},
+				SaveFile: func(path string, content []byte) error {
+					saved = path + ": " + string(content)
+					return nil
+				},
+			}
+			src := t.TempDir()
+			args := Cmd.Args{Command: Cmd.CommandLint, Src: src, Fix: true, Write: tt.write}
+			if err := Run(args, deps); (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if asked == tt.write {
+				t.Errorf("Confirm() called = %v with -write %v", asked, tt.write)
+			}
+			want := ""
+			if tt.wantSaved {
+				want = src + "/config: " + input + "    IdentitiesOnly yes\n"
+			}
+			if saved != want {
+				t.Errorf("saved %q, want %q", saved, want)
+			}
+		})
+	}
+}
+
+func TestRun_Modernize(t *testing.T) {
+	var saved string
+	deps := Dependencies{
+		Println:       func(a ...interface{}) (int, error) { return 0, nil },
+		GetContent:    func(string) ([]byte, error) { return []byte("Host *\n    Protocol 2\n"), nil },
+		CheckUseStdin: func() bool { return false },
+		Modernize: func(fileType, userInput string, args Cmd.Args) ([]byte, []byte, error) {
+			return []byte("Host *\n"), []byte("diff"), nil
+		},
+		SourceFile: func(src string) (string, error) { return src, nil },
+		SaveFile: func(path string, content []byte) error {
+			saved = string(content)
+			return nil
+		},
+	}
+	args := Cmd.Args{Command: Cmd.CommandModernize, Src: t.TempDir(), Write: true}
+	if err := Run(args, deps); err != nil || saved != "Host *\n" {
+		t.Errorf("Run() = %v, saved %q", err, saved)
+	}
+}
+
 func TestMainWithDependencies(t *testing.T) {
 	oldArgs := os.Args
 	defer func() { os.Args = oldArgs }()
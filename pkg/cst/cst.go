@@ -0,0 +1,580 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cst keeps an ssh_config file as a concrete syntax tree.
+// Every line keeps its exact text, so String reproduces the input byte for
+// byte, and edits only rewrite the lines they touch:
+// - A File is a preamble (lines before the first Host or Match) and blocks.
+// - A Block is the comments right above its header, the header and its body.
+// - A Line is the raw text of one line, newline included, and its tokens.
+package cst
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/soulteary/ssh-config/v2/pkg/lexer"
+)
+
+// DefaultIndent is used for new directives when the block has none to copy.
+const DefaultIndent = "    "
+
+// Line is one line of the file.
+type Line struct {
+	Raw string
+	// Tokens are the tokens of Raw without Newline and EOF, their Offset and
+	// End are relative to Raw.
+	Tokens []lexer.Token
+}
+
+// Block is a Host or Match block.
+type Block struct {
+	// Comments are the comment lines directly above Header.
+	Comments []*Line
+	Header   *Line
+	Lines    []*Line
+}
+
+// File is a parsed ssh_config.
+type File struct {
+	// Preamble holds the lines before the first Host or Match block.
+	Preamble []*Line
+	Blocks   []*Block
+}
+
+// NewLine lexes raw, which must hold a single line, into a Line.
+func NewLine(raw string) (*Line, error) {
+	tokens, err := lexer.Lex(raw)
+	if err != nil {
+		return nil, err
+	}
+	line := &Line{Raw: raw}
+	for _, token := range tokens {
+		if token.Kind != lexer.TokenNewline && token.Kind != lexer.TokenEOF {
+			line.Tokens = append(line.Tokens, token)
+		}
+	}
+	return line, nil
+}
+
+// Parse builds the tree of input.
+func Parse(input string) (*File, error) {
+	tokens, err := lexer.Lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	start := 0
+	var current []lexer.Token
+	for _, token := range tokens {
+		if token.Kind != lexer.TokenNewline && token.Kind != lexer.TokenEOF {
+			current = append(current, token)
+			continue
+		}
+		end := token.End
+		if end > start {
+			line := &Line{Raw: input[start:end]}
+			for _, t := range current {
+				t.Offset -= start
+				t.End -= start
+				line.Tokens = append(line.Tokens, t)
+			}
+			file.appendLine(line)
+		}
+		start = end
+		current = nil
+	}
+	return file, nil
+}
+
+// appendLine adds a parsed line, moving the comments right above a header
+// into the block they describe.
+func (f *File) appendLine(line *Line) {
+	if !line.IsBlock() {
+		if len(f.Blocks) == 0 {
+			f.Preamble = append(f.Preamble, line)
+		} else {
+			last := f.Blocks[len(f.Blocks)-1]
+			last.Lines = append(last.Lines, line)
+		}
+		return
+	}
+
+	lines := &f.Preamble
+	if len(f.Blocks) > 0 {
+		lines = &f.Blocks[len(f.Blocks)-1].Lines
+	}
+	split := len(*lines)
+	for split > 0 && (*lines)[split-1].IsComment() {
+		split--
+	}
+	block := &Block{Header: line}
+	block.Comments = append(block.Comments, (*lines)[split:]...)
+	*lines = (*lines)[:split]
+	f.Blocks = append(f.Blocks, block)
+}
+
+// String returns the file text, identical to the input when nothing changed.
+func (f *File) String() string {
+	var b strings.Builder
+	for _, line := range f.Preamble {
+		b.WriteString(line.Raw)
+	}
+	for _, block := range f.Blocks {
+		b.WriteString(block.String())
+	}
+	return b.String()
+}
+
+// Bytes returns String as bytes.
+func (f *File) Bytes() []byte {
+	return []byte(f.String())
+}
+
+// String returns the text of the block, comments included.
+func (b *Block) String() string {
+	var s strings.Builder
+	for _, line := range b.Comments {
+		s.WriteString(line.Raw)
+	}
+	s.WriteString(b.Header.Raw)
+	for _, line := range b.Lines {
+		s.WriteString(line.Raw)
+	}
+	return s.String()
+}
+
+// Keyword returns the lower-cased first word of the line, empty for blank and
+// comment lines.
+func (l *Line) Keyword() string {
+	if len(l.Tokens) == 0 {
+		return ""
+	}
+	first := l.Tokens[0]
+	if first.Kind != lexer.TokenKeyword && first.Kind != lexer.TokenIdent {
+		return ""
+	}
+	return strings.ToLower(first.Value)
+}
+
+// Args returns the unquoted arguments of the line.
+func (l *Line) Args() []string {
+	var args []string
+	for _, token := range l.Tokens {
+		if token.Kind == lexer.TokenValue || token.Kind == lexer.TokenQuoted {
+			args = append(args, token.Value)
+		}
+	}
+	return args
+}
+
+// Value returns the arguments as written, quotes included.
+func (l *Line) Value() string {
+	first, last := l.argumentRange()
+	if first < 0 {
+		return ""
+	}
+	return l.Raw[first:last]
+}
+
+// IsBlank reports whether the line has only whitespace.
+func (l *Line) IsBlank() bool {
+	return len(l.Tokens) == 0
+}
+
+// IsComment reports whether the line holds only a comment.
+func (l *Line) IsComment() bool {
+	return len(l.Tokens) == 1 && l.Tokens[0].Kind == lexer.TokenComment
+}
+
+// IsBlock reports whether the line opens a Host or Match block.
+func (l *Line) IsBlock() bool {
+	keyword := l.Keyword()
+	return keyword == "host" || keyword == "match"
+}
+
+// Indent returns the leading whitespace of the line.
+func (l *Line) Indent() string {
+	return l.Raw[:len(l.Raw)-len(strings.TrimLeft(l.Raw, " \t"))]
+}
+
+// Newline returns the line terminator, empty for a last line without one.
+func (l *Line) Newline() string {
+	switch {
+	case strings.HasSuffix(l.Raw, "\r\n"):
+		return "\r\n"
+	case strings.HasSuffix(l.Raw, "\n"):
+		return "\n"
+	default:
+		return ""
+	}
+}
+
+// argumentRange returns the byte range of the arguments in Raw, -1 when the
+// line has none.
+func (l *Line) argumentRange() (int, int) {
+	first, last := -1, -1
+	for _, token := range l.Tokens {
+		if token.Kind != lexer.TokenValue && token.Kind != lexer.TokenQuoted {
+			continue
+		}
+		if first < 0 {
+			first = token.Offset
+		}
+		last = token.End
+	}
+	return first, last
+}
+
+// WithValue returns a copy of the line with its arguments replaced by value,
+// keeping the keyword, the separator and any trailing comment as written.
+// value is written as is, quote it if needed.
+func (l *Line) WithValue(value string) (*Line, error) {
+	first, last := l.argumentRange()
+	if first < 0 {
+		// no arguments yet, append after the keyword and its separator
+		first = l.Tokens[0].End
+		for _, token := range l.Tokens[1:] {
+			if token.Kind == lexer.TokenEquals {
+				first = token.End
+			}
+		}
+		last = first
+		value = " " + value
+	}
+	return NewLine(l.Raw[:first] + value + l.Raw[last:])
+}
+
+// WithKeyword returns a copy of the line with its keyword replaced, keeping
+// everything else as written.
+func (l *Line) WithKeyword(keyword string) (*Line, error) {
+	if l.Keyword() == "" {
+		return nil, fmt.Errorf("line has no keyword")
+	}
+	first := l.Tokens[0]
+	line, err := NewLine(l.Raw[:first.Offset] + keyword + l.Raw[first.End:])
+	if err != nil {
+		return nil, err
+	}
+	if line.Keyword() != strings.ToLower(keyword) {
+		return nil, fmt.Errorf("invalid keyword %q", keyword)
+	}
+	return line, nil
+}
+
+// ensureNewline terminates a last line before another is added after it.
+func ensureNewline(line *Line, newline string) {
+	if line.Newline() == "" {
+		line.Raw += newline
+	}
+}
+
+// Keyword returns "host" or "match".
+func (b *Block) Keyword() string {
+	return b.Header.Keyword()
+}
+
+// Patterns returns the arguments of the header.
+func (b *Block) Patterns() []string {
+	return b.Header.Args()
+}
+
+// newline returns the line terminator used by the block.
+func (b *Block) newline() string {
+	if newline := b.Header.Newline(); newline != "" {
+		return newline
+	}
+	return "\n"
+}
+
+// Find returns the index of the first directive named key, -1 if missing.
+func (b *Block) Find(key string) int {
+	key = strings.ToLower(key)
+	for index, line := range b.Lines {
+		if line.Keyword() == key {
+			return index
+		}
+	}
+	return -1
+}
+
+// Get returns the first directive named key, nil if missing.
+func (b *Block) Get(key string) *Line {
+	if index := b.Find(key); index >= 0 {
+		return b.Lines[index]
+	}
+	return nil
+}
+
+// Set changes the value of the first directive named key, or adds it when
+// missing. value is written as is, quote it if needed.
+func (b *Block) Set(key, value string) error {
+	index := b.Find(key)
+	if index < 0 {
+		return b.Add(key, value)
+	}
+	line, err := b.Lines[index].WithValue(value)
+	if err != nil {
+		return err
+	}
+	b.Lines[index] = line
+	return nil
+}
+
+// Add inserts a directive after the last non-blank line of the block, with
+// the indentation of the existing directives.
+func (b *Block) Add(key, value string) error {
+	indent := DefaultIndent
+	position := 0
+	for index, line := range b.Lines {
+		if line.IsBlank() {
+			continue
+		}
+		position = index + 1
+		if !line.IsComment() {
+			indent = line.Indent()
+		}
+	}
+
+	newline := b.newline()
+	previous := b.Header
+	if position > 0 {
+		previous = b.Lines[position-1]
+	}
+	// the last line of the file may have no newline, the new one takes its place
+	raw := fmt.Sprintf("%s%s %s", indent, key, value)
+	if previous.Newline() != "" {
+		raw += newline
+	}
+	ensureNewline(previous, newline)
+
+	line, err := NewLine(raw)
+	if err != nil {
+		return err
+	}
+	if line.Keyword() != strings.ToLower(key) {
+		return fmt.Errorf("invalid directive %q", key)
+	}
+	b.Lines = append(b.Lines[:position], append([]*Line{line}, b.Lines[position:]...)...)
+	return nil
+}
+
+// Remove deletes every directive named key and reports how many were removed.
+func (b *Block) Remove(key string) int {
+	key = strings.ToLower(key)
+	lines := b.Lines[:0]
+	removed := 0
+	for _, line := range b.Lines {
+		if line.Keyword() == key {
+			removed++
+			continue
+		}
+		lines = append(lines, line)
+	}
+	b.Lines = lines
+	return removed
+}
+
+// FindHost returns the first Host block listing pattern, nil if missing.
+func (f *File) FindHost(pattern string) *Block {
+	for _, block := range f.Blocks {
+		if block.Keyword() != "host" {
+			continue
+		}
+		for _, arg := range block.Patterns() {
+			if arg == pattern {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// AddHost appends a Host block for patterns, separated from the previous
+// content by a blank line.
+func (f *File) AddHost(patterns ...string) (*Block, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("host needs at least one pattern")
+	}
+	newline := "\n"
+	var last *Line
+	if len(f.Blocks) > 0 {
+		block := f.Blocks[len(f.Blocks)-1]
+		newline = block.newline()
+		last = block.Header
+		if len(block.Lines) > 0 {
+			last = block.Lines[len(block.Lines)-1]
+		}
+	} else if len(f.Preamble) > 0 {
+		last = f.Preamble[len(f.Preamble)-1]
+	}
+
+	if last != nil {
+		ensureNewline(last, newline)
+		if !last.IsBlank() {
+			blank, _ := NewLine(newline)
+			f.appendLine(blank)
+		}
+	}
+
+	header, err := NewLine(fmt.Sprintf("Host %s%s", strings.Join(patterns, " "), newline))
+	if err != nil {
+		return nil, err
+	}
+	block := &Block{Header: header}
+	f.Blocks = append(f.Blocks, block)
+	return block, nil
+}
+
+// RemoveHost deletes the first Host block listing pattern with the comments
+// above it, and reports whether one was found.
+func (f *File) RemoveHost(pattern string) bool {
+	target := f.FindHost(pattern)
+	if target == nil {
+		return false
+	}
+	for index, block := range f.Blocks {
+		if block == target {
+			f.Blocks = append(f.Blocks[:index], f.Blocks[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceLine puts line in the place of old, in the preamble or any block,
+// and reports whether old was found.
+func (f *File) ReplaceLine(old, line *Line) bool {
+	for _, lines := range f.lineLists() {
+		for index, candidate := range *lines {
+			if candidate == old {
+				(*lines)[index] = line
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveLine deletes line from the preamble or any block and reports
+// whether it was found.
+func (f *File) RemoveLine(line *Line) bool {
+	for _, lines := range f.lineLists() {
+		for index, candidate := range *lines {
+			if candidate == line {
+				*lines = append((*lines)[:index], (*lines)[index+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lineLists returns the directive lists of the file, the preamble first.
+func (f *File) lineLists() []*[]*Line {
+	lists := []*[]*Line{&f.Preamble}
+	for _, block := range f.Blocks {
+		lists = append(lists, &block.Lines)
+	}
+	return lists
+}
+
+// RemoveBlock deletes block with the comments above it and reports whether
+// it was found. A last block leaves no blank lines at the end of the file.
+func (f *File) RemoveBlock(block *Block) bool {
+	index := -1
+	for i, candidate := range f.Blocks {
+		if candidate == block {
+			index = i
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	f.Blocks = append(f.Blocks[:index], f.Blocks[index+1:]...)
+	if index < len(f.Blocks) {
+		return true
+	}
+	lines := &f.Preamble
+	if len(f.Blocks) > 0 {
+		lines = &f.Blocks[len(f.Blocks)-1].Lines
+	}
+	for len(*lines) > 0 && (*lines)[len(*lines)-1].IsBlank() {
+		*lines = (*lines)[:len(*lines)-1]
+	}
+	return true
+}
+
+// MoveAfter moves block, with the comments above it, right after the block
+// after, separated from the blocks around it by a blank line. The comments
+// that start the file stay there, as they may describe the file.
+func (f *File) MoveAfter(block *Block, after *Block) bool {
+	index, at := slices.Index(f.Blocks, block), slices.Index(f.Blocks, after)
+	if index < 0 || at < 0 || index == at {
+		return false
+	}
+	if index == at+1 {
+		return true
+	}
+	f.Blocks = slices.Delete(f.Blocks, index, index+1)
+	if index < at {
+		at--
+	}
+	if index == 0 {
+		if !slices.ContainsFunc(f.Preamble, func(line *Line) bool { return !line.IsBlank() }) && len(block.Comments) > 0 {
+			blank, _ := NewLine(block.newline())
+			f.Preamble = append(block.Comments, blank)
+			block.Comments = nil
+		}
+		// nor with the blank lines that came before the block
+		for len(f.Preamble) > 0 && f.Preamble[0].IsBlank() {
+			f.Preamble = f.Preamble[1:]
+		}
+	}
+	if last := f.Blocks[len(f.Blocks)-1]; index == len(f.Blocks) {
+		for len(last.Lines) > 0 && last.Lines[len(last.Lines)-1].IsBlank() {
+			last.Lines = last.Lines[:len(last.Lines)-1]
+		}
+	}
+
+	previous := after.Header
+	if len(after.Lines) > 0 {
+		previous = after.Lines[len(after.Lines)-1]
+	}
+	newline := after.newline()
+	ensureNewline(previous, newline)
+	if !previous.IsBlank() {
+		blank, _ := NewLine(newline)
+		after.Lines = append(after.Lines, blank)
+	}
+	// the moved block may have been separated by the blank lines it ends with
+	for len(block.Lines) > 0 && block.Lines[len(block.Lines)-1].IsBlank() {
+		block.Lines = block.Lines[:len(block.Lines)-1]
+	}
+	end := block.Header
+	if len(block.Lines) > 0 {
+		end = block.Lines[len(block.Lines)-1]
+	}
+	ensureNewline(end, newline)
+	if at+1 < len(f.Blocks) {
+		blank, _ := NewLine(newline)
+		block.Lines = append(block.Lines, blank)
+	}
+	f.Blocks = slices.Insert(f.Blocks, at+1, block)
+	return true
+}
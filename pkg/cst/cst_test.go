@@ -0,0 +1,264 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cst
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sample = `# managed by hand
+Include config.d/*
+
+# work machines
+Host work  work.alias
+	HostName=work.example.com   # primary
+	User "deploy user"
+
+	IdentityFile ~/.ssh/id_work
+# office only
+Match host *.corp exec "test -f /tmp/vpn"
+  ProxyJump bastion
+
+Host *
+    ServerAliveInterval 60`
+
+func mustParse(t *testing.T, input string) *File {
+	t.Helper()
+	file, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return file
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n\n",
+		sample,
+		sample + "\n",
+		"Host a\r\n  User b\r\n",
+		"  # only a comment",
+		"Host a\n    SetEnv FOO=bar \"B=a z\"\n",
+	}
+	for _, input := range inputs {
+		if got := mustParse(t, input).String(); got != input {
+			t.Errorf("String() = %q, want %q", got, input)
+		}
+	}
+}
+
+func TestParse_Structure(t *testing.T) {
+	file := mustParse(t, sample)
+	if len(file.Preamble) != 3 {
+		t.Fatalf("len(Preamble) = %d, want 3", len(file.Preamble))
+	}
+	if len(file.Blocks) != 3 {
+		t.Fatalf("len(Blocks) = %d, want 3", len(file.Blocks))
+	}
+
+	work := file.Blocks[0]
+	if len(work.Comments) != 1 || work.Comments[0].Raw != "# work machines\n" {
+		t.Errorf("Comments = %+v, want the comment above Host", work.Comments)
+	}
+	if !reflect.DeepEqual(work.Patterns(), []string{"work", "work.alias"}) {
+		t.Errorf("Patterns() = %v", work.Patterns())
+	}
+	if got := work.Get("hostname").Args(); !reflect.DeepEqual(got, []string{"work.example.com"}) {
+		t.Errorf("Get(hostname).Args() = %v", got)
+	}
+	if got := work.Get("User").Value(); got != `"deploy user"` {
+		t.Errorf("Get(User).Value() = %q", got)
+	}
+
+	match := file.Blocks[1]
+	if match.Keyword() != "match" || len(match.Comments) != 1 {
+		t.Errorf("Match block = %+v", match)
+	}
+	if file.FindHost("*") != file.Blocks[2] || file.FindHost("missing") != nil {
+		t.Error("FindHost() returned the wrong block")
+	}
+}
+
+func TestBlock_Set(t *testing.T) {
+	file := mustParse(t, sample)
+	work := file.FindHost("work")
+	if err := work.Set("HostName", "new.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := work.Set("Port", "2222"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# managed by hand
+Include config.d/*
+
+# work machines
+Host work  work.alias
+	HostName=new.example.com   # primary
+	User "deploy user"
+
+	IdentityFile ~/.ssh/id_work
+	Port 2222
+# office only
+Match host *.corp exec "test -f /tmp/vpn"
+  ProxyJump bastion
+
+Host *
+    ServerAliveInterval 60`
+	if got := file.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBlock_AddAtEndOfFile(t *testing.T) {
+	file := mustParse(t, "Host a\n    User a")
+	if err := file.FindHost("a").Add("Port", "22"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := file.String(), "Host a\n    User a\n    Port 22"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	file = mustParse(t, "Host a\r\n")
+	if err := file.FindHost("a").Add("Port", "22"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := file.String(), "Host a\r\n    Port 22\r\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if err := file.FindHost("a").Add("Port", "\"unclosed"); err == nil {
+		t.Error("Add() expected an error for an unclosed quote")
+	}
+}
+
+func TestBlock_Remove(t *testing.T) {
+	file := mustParse(t, "Host a\n    User a\n    IdentityFile one\n    identityfile two\n")
+	if removed := file.FindHost("a").Remove("IdentityFile"); removed != 2 {
+		t.Errorf("Remove() = %d, want 2", removed)
+	}
+	if got, want := file.String(), "Host a\n    User a\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFile_AddAndRemoveHost(t *testing.T) {
+	file := mustParse(t, sample)
+	if !file.RemoveHost("work.alias") {
+		t.Fatal("RemoveHost() = false, want true")
+	}
+	if file.RemoveHost("work") {
+		t.Error("RemoveHost() removed a block twice")
+	}
+	block, err := file.AddHost("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := block.Add("HostName", "new.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# managed by hand
+Include config.d/*
+
+# office only
+Match host *.corp exec "test -f /tmp/vpn"
+  ProxyJump bastion
+
+Host *
+    ServerAliveInterval 60
+
+Host new
+    HostName new.example.com
+`
+	if got := file.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if _, err := file.AddHost(); err == nil {
+		t.Error("AddHost() expected an error without patterns")
+	}
+}
+
+func TestParse_UnclosedQuote(t *testing.T) {
+	for _, input := range []string{"Host \"a\n", "Host a\n    User \"b\n    Port 22 \"\n"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) expected an error for an unclosed quote", input)
+		}
+	}
+}
+
+func TestFile_EditLines(t *testing.T) {
+	file := mustParse(t, "Host *\n    user root\n    User other\n\n# web\nHost web\n    HostName web.example.com")
+	all := file.FindHost("*")
+	renamed, err := all.Lines[0].WithKeyword("User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !file.ReplaceLine(all.Lines[0], renamed) || !file.RemoveLine(all.Lines[1]) {
+		t.Fatal("ReplaceLine() or RemoveLine() did not find the line")
+	}
+	if !file.MoveAfter(all, file.FindHost("web")) {
+		t.Fatal("MoveAfter() = false, want true")
+	}
+
+	want := "# web\nHost web\n    HostName web.example.com\n\nHost *\n    User root\n"
+	if got := file.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	file = mustParse(t, "Host *\n    User root\nHost a\n    User a\n\nMatch all\n    Port 22\n\nHost b\n")
+	if !file.MoveAfter(file.FindHost("*"), file.FindHost("a")) || !file.MoveAfter(file.FindHost("b"), file.FindHost("a")) {
+		t.Fatal("MoveAfter() = false, want true")
+	}
+	want = "Host a\n    User a\n\nHost b\n\nHost *\n    User root\n\nMatch all\n    Port 22\n"
+	if got := file.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if file.MoveAfter(file.FindHost("a"), file.FindHost("a")) {
+		t.Error("MoveAfter() of a block after itself = true, want false")
+	}
+	if _, err := renamed.WithKeyword("two words"); err == nil {
+		t.Error("WithKeyword() expected an error for two words")
+	}
+
+	jump, err := mustParse(t, "ProxyCommand ssh -W %h:%p bastion # via bastion\n").Preamble[0].WithKeyword("ProxyJump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jump, err = jump.WithValue("bastion"); err != nil || jump.Raw != "ProxyJump bastion # via bastion\n" {
+		t.Errorf("WithValue() = %q, %v", jump.Raw, err)
+	}
+}
+
+func TestFile_RemoveBlock(t *testing.T) {
+	file := mustParse(t, "Host *\n    User root\n\n# again\nHost *\n    Port 22\n\nHost web\n    User www\n\n")
+	if !file.RemoveBlock(file.Blocks[1]) {
+		t.Fatal("RemoveBlock() = false, want true")
+	}
+	if want := "Host *\n    User root\n\nHost web\n    User www\n\n"; file.String() != want {
+		t.Errorf("String() = %q, want %q", file.String(), want)
+	}
+	if !file.RemoveBlock(file.Blocks[1]) || file.RemoveBlock(&Block{}) {
+		t.Fatal("RemoveBlock() found the wrong blocks")
+	}
+	if want := "Host *\n    User root\n"; file.String() != want {
+		t.Errorf("String() = %q, want %q", file.String(), want)
+	}
+}
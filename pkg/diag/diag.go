@@ -0,0 +1,194 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package diag holds the problems found in a config, parse errors, invalid
+// values and lint findings alike, and renders them as text, JSON or SARIF.
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity is how serious a diagnostic is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rules of the diagnostics that do not come from a lint rule.
+const (
+	// RuleParse is a config that can not be read.
+	RuleParse = "parse-error"
+	// RuleInvalidValue is a value ssh would refuse.
+	RuleInvalidValue = "invalid-value"
+	// RuleConfigWarning is a setting reading the config changes or drops,
+	// such as a merged duplicate block.
+	RuleConfigWarning = "config-warning"
+)
+
+// Rules describes the rules above.
+var Rules = []Rule{
+	{ID: RuleParse, Severity: SeverityError, Summary: "the config can not be read"},
+	{ID: RuleInvalidValue, Severity: SeverityError, Summary: "a value does not have the type its keyword expects"},
+	{ID: RuleConfigWarning, Severity: SeverityWarning, Summary: "reading the config changes or drops a setting"},
+}
+
+// Rule describes a rule diagnostics refer to.
+type Rule struct {
+	ID       string
+	Name     string
+	Severity Severity
+	Summary  string
+}
+
+// Diagnostic is a problem at a position of a config.
+type Diagnostic struct {
+	// File is the path of the config, empty for stdin.
+	File string `json:"file,omitempty"`
+	// Line and Column start at 1, they are 0 when unknown.
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Label names the block, e.g. "Host web", empty outside of any block.
+	Label   string `json:"label,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic as "file:line:column: severity: label:
+// message (rule)", leaving out what is unknown.
+func (d Diagnostic) String() string {
+	return d.Format("")
+}
+
+// Format is String with the name of the rule after its ID, when not empty.
+func (d Diagnostic) Format(name string) string {
+	var position []string
+	if d.File != "" {
+		position = append(position, d.File)
+	}
+	if d.Line > 0 {
+		position = append(position, strconv.Itoa(d.Line))
+		if d.Column > 0 {
+			position = append(position, strconv.Itoa(d.Column))
+		}
+	}
+	var parts []string
+	if len(position) > 0 {
+		parts = append(parts, strings.Join(position, ":"))
+	}
+	parts = append(parts, string(d.Severity))
+	if d.Label != "" {
+		parts = append(parts, d.Label)
+	}
+	rule := d.Rule
+	if name != "" {
+		rule += " " + name
+	}
+	return strings.Join(append(parts, fmt.Sprintf("%s (%s)", d.Message, rule)), ": ")
+}
+
+// Positioned is an error that knows where it is in a config: Position
+// returns its line and column, 0 when unknown, and its message without them.
+type Positioned interface {
+	error
+	Position() (line, column int, message string)
+}
+
+// FromError turns a parse error into diagnostics, one for each error it
+// joins. Errors implementing Positioned keep their position.
+func FromError(err error, file string) []Diagnostic {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var diagnostics []Diagnostic
+		for _, err := range joined.Unwrap() {
+			diagnostics = append(diagnostics, FromError(err, file)...)
+		}
+		return diagnostics
+	}
+	diagnostic := Diagnostic{File: file, Rule: RuleParse, Severity: SeverityError, Message: err.Error()}
+	var positioned Positioned
+	if errors.As(err, &positioned) {
+		diagnostic.Line, diagnostic.Column, diagnostic.Message = positioned.Position()
+	}
+	return []Diagnostic{diagnostic}
+}
+
+// Report is the diagnostics of a run with the rules they refer to.
+type Report struct {
+	Diagnostics []Diagnostic
+	Rules       []Rule
+}
+
+// Output formats of a report.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+var Formats = []string{FormatText, FormatJSON, FormatSARIF}
+
+// Render writes the report in format, text when it is empty.
+func (r Report) Render(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return []byte(r.Text()), nil
+	case FormatJSON:
+		return r.JSON()
+	case FormatSARIF:
+		return r.SARIF()
+	}
+	return nil, fmt.Errorf("unsupported format %q, use one of %v", format, Formats)
+}
+
+// Text writes one diagnostic per line, rules with their name.
+func (r Report) Text() string {
+	lines := make([]string, 0, len(r.Diagnostics))
+	for _, diagnostic := range r.Diagnostics {
+		name := ""
+		if rule := r.rule(diagnostic.Rule); rule != nil {
+			name = rule.Name
+		}
+		lines = append(lines, diagnostic.Format(name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON writes the diagnostics as an array.
+func (r Report) JSON() ([]byte, error) {
+	diagnostics := r.Diagnostics
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return json.MarshalIndent(diagnostics, "", "  ")
+}
+
+// rule returns the rule with id, nil when the report does not describe it.
+func (r Report) rule(id string) *Rule {
+	for i := range r.Rules {
+		if r.Rules[i].ID == id {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
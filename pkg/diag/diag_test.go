@@ -0,0 +1,118 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var report = Report{
+	Diagnostics: []Diagnostic{
+		{File: "/home/alice/.ssh/config", Line: 3, Column: 5, Rule: "SSH003", Severity: SeverityError, Label: "Host *", Message: "StrictHostKeyChecking no"},
+		{File: "/home/alice/.ssh/config", Rule: RuleParse, Severity: SeverityError, Message: "duplicate Host block"},
+		{Rule: "TEAM001", Severity: SeverityInfo, Label: "Host db", Message: "User is not set"},
+	},
+	Rules: []Rule{{ID: "SSH003", Name: "strict-host-key-checking-off", Severity: SeverityError, Summary: "StrictHostKeyChecking no accepts any host key"}},
+}
+
+func TestReport_Text(t *testing.T) {
+	want := "/home/alice/.ssh/config:3:5: error: Host *: StrictHostKeyChecking no (SSH003 strict-host-key-checking-off)\n" +
+		"/home/alice/.ssh/config: error: duplicate Host block (parse-error)\n" +
+		"info: Host db: User is not set (TEAM001)"
+	if got := report.Text(); got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReport_JSON(t *testing.T) {
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Diagnostic
+	if err := json.Unmarshal(data, &got); err != nil || !reflect.DeepEqual(got, report.Diagnostics) {
+		t.Errorf("JSON() = %s, %v", data, err)
+	}
+	if data, _ := (Report{}).JSON(); string(data) != "[]" {
+		t.Errorf("JSON() of an empty report = %s, want []", data)
+	}
+}
+
+func TestReport_SARIF(t *testing.T) {
+	data, err := report.SARIF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("SARIF() = %s", data)
+	}
+	run := log.Runs[0]
+	var ids []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ids = append(ids, rule.ID)
+	}
+	if want := []string{"SSH003", RuleParse, "TEAM001"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("rules = %v, want %v", ids, want)
+	}
+
+	first := run.Results[0]
+	location := first.Locations[0]
+	if first.RuleIndex != 0 || first.Level != "error" ||
+		location.PhysicalLocation.ArtifactLocation.URI != "file:///home/alice/.ssh/config" ||
+		*location.PhysicalLocation.Region != (sarifRegion{StartLine: 3, StartColumn: 5}) ||
+		location.LogicalLocations[0].Name != "Host *" {
+		t.Errorf("results[0] = %+v", first)
+	}
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("results[1] has a region without a line")
+	}
+	if last := run.Results[2]; last.RuleIndex != 2 || last.Level != "note" || last.Locations[0].PhysicalLocation != nil {
+		t.Errorf("results[2] = %+v", last)
+	}
+}
+
+type positionedError struct{ line, column int }
+
+func (e positionedError) Error() string { return fmt.Sprintf("line %d: bad", e.line) }
+
+func (e positionedError) Position() (int, int, string) { return e.line, e.column, "bad" }
+
+func TestFromError(t *testing.T) {
+	err := errors.Join(positionedError{2, 4}, fmt.Errorf("wrapped: %w", positionedError{5, 0}), errors.New("plain"))
+	want := []Diagnostic{
+		{File: "config", Line: 2, Column: 4, Rule: RuleParse, Severity: SeverityError, Message: "bad"},
+		{File: "config", Line: 5, Rule: RuleParse, Severity: SeverityError, Message: "bad"},
+		{File: "config", Rule: RuleParse, Severity: SeverityError, Message: "plain"},
+	}
+	if got := FromError(err, "config"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromError() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReport_Render(t *testing.T) {
+	if _, err := report.Render("xml"); err == nil {
+		t.Error("Render() expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diag
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+)
+
+// SARIF 2.1.0 documents, limited to the properties the report fills in.
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// ToolName and ToolURI describe the tool in SARIF documents.
+const (
+	ToolName = "ssh-config"
+	ToolURI  = "https://github.com/soulteary/ssh-config"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+}
+
+// sarifLevel returns the SARIF level of severity, which calls info note.
+func sarifLevel(severity Severity) string {
+	if severity == SeverityInfo {
+		return "note"
+	}
+	return string(severity)
+}
+
+// sarifURI returns the artifact URI of a file path: a file URI for an
+// absolute path, a relative reference otherwise.
+func sarifURI(file string) string {
+	path := filepath.ToSlash(file)
+	if filepath.IsAbs(file) {
+		return (&url.URL{Scheme: "file", Path: path}).String()
+	}
+	return (&url.URL{Path: path}).String()
+}
+
+// SARIF writes the report as a SARIF 2.1.0 log with a single run. Every
+// rule of the report is listed, and the rules only diagnostics name are
+// added after them.
+func (r Report) SARIF() ([]byte, error) {
+	driver := sarifDriver{Name: ToolName, InformationURI: ToolURI, Rules: []sarifRule{}}
+	index := make(map[string]int)
+	addRule := func(rule Rule) {
+		if _, ok := index[rule.ID]; ok {
+			return
+		}
+		index[rule.ID] = len(driver.Rules)
+		entry := sarifRule{ID: rule.ID, Name: rule.Name}
+		if rule.Summary != "" {
+			entry.ShortDescription = &sarifMessage{Text: rule.Summary}
+		}
+		if rule.Severity != "" {
+			entry.DefaultConfiguration = &sarifConfiguration{Level: sarifLevel(rule.Severity)}
+		}
+		driver.Rules = append(driver.Rules, entry)
+	}
+	for _, rule := range r.Rules {
+		addRule(rule)
+	}
+
+	results := []sarifResult{}
+	for _, diagnostic := range r.Diagnostics {
+		addRule(Rule{ID: diagnostic.Rule})
+		result := sarifResult{
+			RuleID:    diagnostic.Rule,
+			RuleIndex: index[diagnostic.Rule],
+			Level:     sarifLevel(diagnostic.Severity),
+			Message:   sarifMessage{Text: diagnostic.Message},
+		}
+		var location sarifLocation
+		if diagnostic.File != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: sarifURI(diagnostic.File)}}
+			if diagnostic.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: diagnostic.Line, StartColumn: diagnostic.Column}
+			}
+		}
+		if diagnostic.Label != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{Name: diagnostic.Label}}
+		}
+		if location.PhysicalLocation != nil || location.LogicalLocations != nil {
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
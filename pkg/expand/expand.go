@@ -0,0 +1,272 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package expand expands the percent tokens and ${ENV} references of
+// ssh_config values, following the TOKENS and ENVIRONMENT VARIABLES sections
+// of ssh_config(5): https://man7.org/linux/man-pages/man5/ssh_config.5.html
+// - Each keyword accepts its own set of tokens, "%%" is always a literal '%'.
+// - Only some keywords expand ${ENV}, elsewhere it is kept as written.
+package expand
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// Context holds the values tokens expand to.
+type Context struct {
+	// Host is the remote host name after HostName is applied (%h).
+	Host string
+	// OriginalHost is the name given on the command line (%n).
+	OriginalHost string
+	// Port is the remote port (%p).
+	Port string
+	// RemoteUser is the remote user name (%r).
+	RemoteUser string
+	// LocalUser is the local user name (%u).
+	LocalUser string
+	// UID is the local user ID (%i).
+	UID string
+	// HomeDir is the local home directory (%d).
+	HomeDir string
+	// LocalHostname is the local host name with its domain (%l), LocalHost is
+	// the part before the first dot (%L).
+	LocalHostname string
+	// HostKeyAlias is the alias used for host keys (%k), OriginalHost when empty.
+	HostKeyAlias string
+	// ProxyJump is the jump host list (%j).
+	ProxyJump string
+	// Tunnel is the tunnel device (%T), "NONE" when empty.
+	Tunnel string
+	// Env looks up ${NAME} references, os.LookupEnv when nil.
+	Env func(name string) (string, bool)
+}
+
+// LocalHost returns the short local host name (%L).
+func (c Context) LocalHost() string {
+	host, _, _ := strings.Cut(c.LocalHostname, ".")
+	return host
+}
+
+// ConnectionHash returns the %C value: the SHA-1 of "%l%h%p%r%j".
+func (c Context) ConnectionHash() string {
+	sum := sha1.Sum([]byte(c.LocalHostname + c.Host + c.Port + c.RemoteUser + c.ProxyJump))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	currentUser = user.Current
+	hostname    = os.Hostname
+)
+
+// LocalContext returns a context filled with the local user and host, the
+// remote part is left for the caller.
+func LocalContext() Context {
+	context := Context{}
+	if u, err := currentUser(); err == nil {
+		context.LocalUser = u.Username
+		context.UID = u.Uid
+		context.HomeDir = u.HomeDir
+	}
+	if name, err := hostname(); err == nil {
+		context.LocalHostname = name
+	}
+	return context
+}
+
+// token returns the value of %c, false for an unknown token.
+func (c Context) token(r byte) (string, bool) {
+	switch r {
+	case '%':
+		return "%", true
+	case 'C':
+		return c.ConnectionHash(), true
+	case 'd':
+		return c.HomeDir, true
+	case 'h':
+		return c.Host, true
+	case 'i':
+		return c.UID, true
+	case 'j':
+		return c.ProxyJump, true
+	case 'k':
+		if c.HostKeyAlias != "" {
+			return c.HostKeyAlias, true
+		}
+		return c.OriginalHost, true
+	case 'L':
+		return c.LocalHost(), true
+	case 'l':
+		return c.LocalHostname, true
+	case 'n':
+		return c.OriginalHost, true
+	case 'p':
+		return c.Port, true
+	case 'r':
+		return c.RemoteUser, true
+	case 'T':
+		if c.Tunnel != "" {
+			return c.Tunnel, true
+		}
+		return "NONE", true
+	case 'u':
+		return c.LocalUser, true
+	}
+	return "", false
+}
+
+const (
+	// commonTokens are accepted by most keywords that take tokens.
+	commonTokens = "%CdhijkLlnpru"
+	// knownHostsTokens are only known while ssh verifies a host key, they
+	// are reported but never expanded here.
+	knownHostsTokens = "fHIKt"
+	allTokens        = commonTokens + knownHostsTokens + "T"
+)
+
+// keywordTokens lists the tokens each keyword accepts, lower-cased keys.
+// "exec" is the command of a Match exec criterion.
+var keywordTokens = map[string]string{
+	"certificatefile":    commonTokens,
+	"controlpath":        commonTokens,
+	"exec":               commonTokens,
+	"hostname":           "%h",
+	"identityagent":      commonTokens,
+	"identityfile":       commonTokens,
+	"knownhostscommand":  commonTokens + knownHostsTokens,
+	"localcommand":       commonTokens + "T",
+	"localforward":       commonTokens,
+	"proxycommand":       "%hnpr",
+	"proxyjump":          "%hnpr",
+	"remotecommand":      commonTokens,
+	"remoteforward":      commonTokens,
+	"revokedhostkeys":    commonTokens,
+	"userknownhostsfile": commonTokens,
+}
+
+// envKeywords expand ${NAME} references, lower-cased keys.
+var envKeywords = map[string]bool{
+	"certificatefile":    true,
+	"controlpath":        true,
+	"identityagent":      true,
+	"identityfile":       true,
+	"knownhostscommand":  true,
+	"localforward":       true,
+	"remoteforward":      true,
+	"revokedhostkeys":    true,
+	"userknownhostsfile": true,
+}
+
+// Tokens returns the tokens key accepts, e.g. "%h" for HostName, and
+// whether it expands ${NAME} references.
+func Tokens(key string) (tokens string, env bool) {
+	key = strings.ToLower(key)
+	return keywordTokens[key], envKeywords[key]
+}
+
+// Problem is a token or reference of a value that can not be expanded.
+type Problem struct {
+	Key string
+	// Token is the token as written, e.g. "%T" or "${HOME".
+	Token string
+	// Offset is the byte offset of Token in the value.
+	Offset  int
+	Message string
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s: %s at offset %d: %s", p.Key, strconv.Quote(p.Token), p.Offset, p.Message)
+}
+
+// Check reports the tokens and references of value that key does not support.
+func Check(key, value string) []Problem {
+	_, problems := expand(key, value, Context{}, false)
+	return problems
+}
+
+// Expand returns value with the tokens and references key supports
+// replaced, anything it can not expand is kept as written and reported.
+func Expand(key, value string, context Context) (string, []Problem) {
+	return expand(key, value, context, true)
+}
+
+func expand(key, value string, context Context, replace bool) (string, []Problem) {
+	tokens, env := Tokens(key)
+	lookup := context.Env
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	var b strings.Builder
+	var problems []Problem
+	report := func(token string, offset int, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Token: token, Offset: offset, Message: fmt.Sprintf(format, args...)})
+		b.WriteString(token)
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '%' && tokens != "":
+			if i+1 >= len(value) {
+				report("%", i, "incomplete token")
+				continue
+			}
+			token := value[i : i+2]
+			i++
+			switch {
+			case !strings.Contains(allTokens, token[1:]):
+				report(token, i-1, "unknown token")
+			case !strings.Contains(tokens, token[1:]):
+				report(token, i-1, "token not supported by %s", key)
+			case strings.Contains(knownHostsTokens, token[1:]):
+				// only known while ssh verifies a host key
+				b.WriteString(token)
+			case replace:
+				expanded, _ := context.token(token[1])
+				b.WriteString(expanded)
+			default:
+				b.WriteString(token)
+			}
+		case value[i] == '$' && env && strings.HasPrefix(value[i:], "${"):
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				report(value[i:], i, "unterminated environment variable")
+				i = len(value)
+				continue
+			}
+			reference := value[i : i+end+1]
+			name := reference[2 : len(reference)-1]
+			i += end
+			if !replace {
+				b.WriteString(reference)
+				continue
+			}
+			if expanded, ok := lookup(name); ok {
+				b.WriteString(expanded)
+			} else {
+				report(reference, i-end, "environment variable %s is not set", name)
+			}
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String(), problems
+}
@@ -0,0 +1,151 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expand
+
+import (
+	"errors"
+	"os/user"
+	"reflect"
+	"testing"
+)
+
+var testContext = Context{
+	Host:          "work.example.com",
+	OriginalHost:  "work",
+	Port:          "2222",
+	RemoteUser:    "deploy",
+	LocalUser:     "alice",
+	UID:           "1000",
+	HomeDir:       "/home/alice",
+	LocalHostname: "laptop.lan",
+	Env: func(name string) (string, bool) {
+		if name == "KEYS" {
+			return "/keys", true
+		}
+		return "", false
+	},
+}
+
+func TestTokens(t *testing.T) {
+	if tokens, env := Tokens("HostName"); tokens != "%h" || env {
+		t.Errorf("Tokens(HostName) = %q, %v", tokens, env)
+	}
+	if tokens, env := Tokens("identityfile"); tokens != commonTokens || !env {
+		t.Errorf("Tokens(identityfile) = %q, %v", tokens, env)
+	}
+	if tokens, env := Tokens("User"); tokens != "" || env {
+		t.Errorf("Tokens(User) = %q, %v", tokens, env)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"IdentityFile", "%d/.ssh/id_%r@%h", "/home/alice/.ssh/id_deploy@work.example.com"},
+		{"ControlPath", "~/.ssh/cm-%L-%l-%u-%i-%n:%p", "~/.ssh/cm-laptop-laptop.lan-alice-1000-work:2222"},
+		{"IdentityFile", "${KEYS}/id_%k", "/keys/id_work"},
+		{"ProxyCommand", "nc %h %p 100%%", "nc work.example.com 2222 100%"},
+		{"LocalCommand", "echo %T", "echo NONE"},
+		{"KnownHostsCommand", "check %H %f", "check %H %f"},
+		{"User", "%h ${KEYS}", "%h ${KEYS}"},
+		{"ProxyCommand", "echo ${KEYS}", "echo ${KEYS}"},
+	}
+	for _, tt := range tests {
+		got, problems := Expand(tt.key, tt.value, testContext)
+		if got != tt.want || problems != nil {
+			t.Errorf("Expand(%s, %q) = %q, %v, want %q", tt.key, tt.value, got, problems, tt.want)
+		}
+	}
+}
+
+func TestExpand_ConnectionHash(t *testing.T) {
+	got, _ := Expand("ControlPath", "%C", testContext)
+	if got != testContext.ConnectionHash() || len(got) != 40 {
+		t.Errorf("Expand(%%C) = %q", got)
+	}
+	other := testContext
+	other.Port = "22"
+	if other.ConnectionHash() == got {
+		t.Error("ConnectionHash() ignores the port")
+	}
+}
+
+func TestExpand_Problems(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+		problem          Problem
+	}{
+		{"ProxyCommand", "ssh -W %h:%p %T", "ssh -W work.example.com:2222 %T",
+			Problem{Key: "ProxyCommand", Token: "%T", Offset: 13, Message: "token not supported by ProxyCommand"}},
+		{"IdentityFile", "id_%z", "id_%z",
+			Problem{Key: "IdentityFile", Token: "%z", Offset: 3, Message: "unknown token"}},
+		{"HostName", "%h.example.%", "work.example.%",
+			Problem{Key: "HostName", Token: "%", Offset: 11, Message: "incomplete token"}},
+		{"IdentityFile", "${KEYS", "${KEYS",
+			Problem{Key: "IdentityFile", Token: "${KEYS", Offset: 0, Message: "unterminated environment variable"}},
+		{"IdentityFile", "a/${MISSING}", "a/${MISSING}",
+			Problem{Key: "IdentityFile", Token: "${MISSING}", Offset: 2, Message: "environment variable MISSING is not set"}},
+	}
+	for _, tt := range tests {
+		context := testContext
+		if tt.key == "HostName" {
+			context.Host = "work"
+		}
+		got, problems := Expand(tt.key, tt.value, context)
+		if got != tt.want {
+			t.Errorf("Expand(%s, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+		if !reflect.DeepEqual(problems, []Problem{tt.problem}) {
+			t.Errorf("Expand(%s, %q) problems = %+v, want %+v", tt.key, tt.value, problems, tt.problem)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if problems := Check("IdentityFile", "%d/${MISSING}/%h"); problems != nil {
+		t.Errorf("Check() = %v, want no problem", problems)
+	}
+	problems := Check("ProxyJump", "%h %u")
+	if len(problems) != 1 || problems[0].Token != "%u" {
+		t.Fatalf("Check() = %v, want %%u reported", problems)
+	}
+	if got, want := problems[0].Error(), `ProxyJump: "%u" at offset 3: token not supported by ProxyJump`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestLocalContext(t *testing.T) {
+	defer func(u func() (*user.User, error), h func() (string, error)) {
+		currentUser, hostname = u, h
+	}(currentUser, hostname)
+
+	currentUser = func() (*user.User, error) {
+		return &user.User{Username: "bob", Uid: "501", HomeDir: "/Users/bob"}, nil
+	}
+	hostname = func() (string, error) { return "mac.local", nil }
+	want := Context{LocalUser: "bob", UID: "501", HomeDir: "/Users/bob", LocalHostname: "mac.local"}
+	if got := LocalContext(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LocalContext() = %+v, want %+v", got, want)
+	}
+
+	currentUser = func() (*user.User, error) { return nil, errors.New("no user") }
+	hostname = func() (string, error) { return "", errors.New("no hostname") }
+	if got := LocalContext(); !reflect.DeepEqual(got, Context{}) {
+		t.Errorf("LocalContext() = %+v, want an empty context", got)
+	}
+}
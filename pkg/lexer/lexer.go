@@ -19,12 +19,13 @@
 // - Comments: # to end of line; empty lines ignored.
 // - Keywords (Host, Match, Include) are case-insensitive; arguments are case-sensitive.
 // - Options separated by whitespace or optional whitespace and exactly one '='.
-// - Arguments containing spaces may be enclosed in double quotes; \" and \\ are escaped.
+// - Arguments containing spaces may be enclosed in double quotes, closed on the same line; \" and \\ are escaped.
 package lexer
 
 import (
 	"fmt"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -66,11 +67,15 @@ func (k TokenKind) String() string {
 }
 
 // Token represents a single lexer token with optional position.
+// Offset and End are the byte range of the token in the input, so callers can
+// recover its exact spelling (quotes, escapes) and the whitespace around it.
 type Token struct {
 	Kind   TokenKind
 	Value  string
 	Line   int
 	Column int
+	Offset int
+	End    int
 }
 
 func (t Token) String() string {
@@ -82,9 +87,21 @@ var blockKeywords = map[string]struct{}{
 	"host": {}, "match": {}, "include": {},
 }
 
+// isBlockKeyword looks word up without allocating a lower-cased copy.
+func isBlockKeyword(word string) bool {
+	for keyword := range blockKeywords {
+		if strings.EqualFold(word, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // Lexer scans SSH config text and produces tokens.
 type Lexer struct {
-	input       string
+	input string
+	// base is the offset of input in a larger stream.
+	base        int
 	pos         int
 	line        int
 	col         int
@@ -92,6 +109,31 @@ type Lexer struct {
 	startLine   int
 	startCol    int
 	atLineStart bool
+	// afterKey is set right after a keyword, while a single '=' may still
+	// separate it from its arguments.
+	afterKey bool
+	// afterEquals is set right after the '=' separator.
+	afterEquals bool
+
+	// recovering turns errors into diagnostics, the rest of the line is skipped.
+	recovering  bool
+	diagnostics []Diagnostic
+}
+
+// Diagnostic is a problem found by a recovering lexer.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("line %d column %d: %s", d.Line, d.Column, d.Message)
+}
+
+// Position returns the line, column and message of the diagnostic.
+func (d Diagnostic) Position() (int, int, string) {
+	return d.Line, d.Column, d.Message
 }
 
 // NewLexer returns a lexer for the given input.
@@ -104,6 +146,64 @@ func NewLexer(input string) *Lexer {
 	}
 }
 
+// newLexerAt returns a lexer for a part of a larger input, starting at the
+// given line and byte offset.
+func newLexerAt(input string, line, offset int) *Lexer {
+	l := NewLexer(input)
+	l.line = line
+	l.base = offset
+	return l
+}
+
+// NewRecoveringLexer returns a lexer that never fails: each problem is
+// recorded as a diagnostic and lexing resumes at the next line. It also
+// reports what the default lexer lets through: misplaced '=', keywords with
+// stray characters, control bytes and invalid UTF-8.
+func NewRecoveringLexer(input string) *Lexer {
+	l := NewLexer(input)
+	l.recovering = true
+	return l
+}
+
+// Diagnostics returns the problems found so far by a recovering lexer.
+func (l *Lexer) Diagnostics() []Diagnostic {
+	return l.diagnostics
+}
+
+// report records a diagnostic and skips the rest of the line.
+func (l *Lexer) report(line, column int, format string, args ...any) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	for l.peek() != 0 && l.peek() != '\n' {
+		l.next()
+	}
+	l.afterKey = false
+	l.afterEquals = false
+}
+
+// checkBytes reports the first control byte or invalid UTF-8 sequence of
+// text, which starts at the given position.
+func (l *Lexer) checkBytes(text string, line, column int) bool {
+	for offset, r := range text {
+		switch {
+		case r == utf8.RuneError:
+			if _, width := utf8.DecodeRuneInString(text[offset:]); width == 1 {
+				l.report(line, column, "invalid UTF-8 byte 0x%02x", text[offset])
+				return false
+			}
+		case r != '\t' && r != '\r' && r != '\n' && unicode.IsControl(r):
+			l.report(line, column, "control character %U", r)
+			return false
+		}
+		if r == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return true
+}
+
 func (l *Lexer) peek() rune {
 	if l.pos >= len(l.input) {
 		return 0
@@ -127,7 +227,7 @@ func (l *Lexer) next() rune {
 }
 
 func (l *Lexer) emit(kind TokenKind, value string) Token {
-	return Token{Kind: kind, Value: value, Line: l.startLine, Column: l.startCol}
+	return Token{Kind: kind, Value: value, Line: l.startLine, Column: l.startCol, Offset: l.base + l.start, End: l.base + l.pos}
 }
 
 func (l *Lexer) slice() string {
@@ -148,6 +248,8 @@ func (l *Lexer) NextToken() (Token, error) {
 		case r == '\n':
 			l.next()
 			l.atLineStart = true
+			l.afterKey = false
+			l.afterEquals = false
 			return l.emit(TokenNewline, "\n"), nil
 
 		case r == ' ' || r == '\t' || r == '\r':
@@ -167,17 +269,22 @@ func (l *Lexer) NextToken() (Token, error) {
 		case r == '"':
 			l.next()
 			var b strings.Builder
-			for {
+			closed := false
+			for !closed {
 				r = l.peek()
-				if r == 0 {
-					return Token{}, fmt.Errorf("unclosed quoted string at line %d column %d", l.line, l.col)
-				}
-				if r == '"' {
-					l.next()
-					break
+				// ssh_config has no multi-line quoting, a quote ends with its line
+				if r == 0 || r == '\n' {
+					if l.recovering {
+						l.report(l.startLine, l.startCol, "unclosed quoted string")
+						break
+					}
+					return Token{}, Diagnostic{Line: l.startLine, Column: l.startCol, Message: "unclosed quoted string"}
 				}
-				if r == '\\' {
-					l.next()
+				l.next()
+				switch r {
+				case '"':
+					closed = true
+				case '\\':
 					r = l.peek()
 					if r == '"' || r == '\\' {
 						l.next()
@@ -185,31 +292,72 @@ func (l *Lexer) NextToken() (Token, error) {
 					} else {
 						b.WriteRune('\\')
 					}
+				default:
+					b.WriteRune(r)
+				}
+			}
+			if l.recovering {
+				if !closed {
+					continue
+				}
+				if l.atLineStart {
+					l.report(l.startLine, l.startCol, "quoted string where a keyword is expected")
+					continue
+				}
+				if !l.checkBytes(l.slice(), l.startLine, l.startCol) {
 					continue
 				}
-				l.next()
-				b.WriteRune(r)
 			}
+			l.afterKey = false
+			l.afterEquals = false
 			return l.emit(TokenQuoted, b.String()), nil
 
-		case r == '=':
+		case r == '=' && l.recovering && (l.atLineStart || l.afterEquals):
+			if l.atLineStart {
+				l.report(l.line, l.col, "'=' without a keyword")
+			} else {
+				l.report(l.line, l.col, "more than one '=' between keyword and arguments")
+			}
+			continue
+
+		case r == '=' && (l.afterKey || l.atLineStart):
 			l.next()
 			l.atLineStart = false
+			l.afterKey = false
+			l.afterEquals = true
 			return l.emit(TokenEquals, "="), nil
 
 		default:
-			for l.peek() != 0 && l.peek() != '\n' && l.peek() != ' ' && l.peek() != '\t' && l.peek() != '\r' && l.peek() != '#' && l.peek() != '=' {
+			// Only the keyword itself ends at '='; inside arguments it is a plain
+			// character, e.g. "SetEnv FOO=bar".
+			atStart := l.atLineStart
+			for r := l.peek(); r != 0 && r != '\n' && r != ' ' && r != '\t' && r != '\r' && r != '#' && (r != '=' || !atStart); r = l.peek() {
 				l.next()
 			}
 			word := l.slice()
 			// In valid UTF-8 we always advance at least one rune in the loop above, so word != "".
 
-			atStart := l.atLineStart
+			if l.recovering {
+				if !l.checkBytes(word, l.startLine, l.startCol) {
+					continue
+				}
+				if atStart {
+					if index := strings.IndexFunc(word, func(r rune) bool {
+						return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+					}); index >= 0 {
+						r, _ := utf8.DecodeRuneInString(word[index:])
+						l.report(l.startLine, l.startCol+utf8.RuneCountInString(word[:index]), "invalid character %q in keyword %q", r, word)
+						continue
+					}
+				}
+			}
+
 			l.atLineStart = false
+			l.afterKey = atStart
+			l.afterEquals = false
 
 			if atStart {
-				lower := strings.ToLower(word)
-				if _, ok := blockKeywords[lower]; ok {
+				if isBlockKeyword(word) {
 					return l.emit(TokenKeyword, word), nil
 				}
 				return l.emit(TokenIdent, word), nil
@@ -221,7 +369,10 @@ func (l *Lexer) NextToken() (Token, error) {
 
 // Lex returns all tokens from input. Stops on first error (e.g. unclosed quote).
 func Lex(input string) ([]Token, error) {
-	l := NewLexer(input)
+	return lexFrom(NewLexer(input))
+}
+
+func lexFrom(l *Lexer) ([]Token, error) {
 	var tokens []Token
 	for {
 		tok, err := l.NextToken()
@@ -235,3 +386,19 @@ func Lex(input string) ([]Token, error) {
 	}
 	return tokens, nil
 }
+
+// LexAll returns the tokens of input with a recovering lexer and every
+// problem it found, so a whole file can be checked in one run.
+func LexAll(input string) ([]Token, []Diagnostic) {
+	l := NewRecoveringLexer(input)
+	var tokens []Token
+	for {
+		// a recovering lexer does not return errors
+		tok, _ := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Kind == TokenEOF {
+			break
+		}
+	}
+	return tokens, l.Diagnostics()
+}
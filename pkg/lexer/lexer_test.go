@@ -129,6 +129,12 @@ func TestLex_UnclosedQuote(t *testing.T) {
 	if err == nil {
 		t.Error("Lex: expected error for unclosed quote")
 	}
+
+	// a quote does not run into the next line, even when a later line closes it
+	_, err = Lex("Host a\n    User \"bob\n    Port abc\n    ProxyCommand \"nc %h %p\n")
+	if want := "line 2 column 10: unclosed quoted string"; err == nil || err.Error() != want {
+		t.Errorf("Lex() error = %v, want %q", err, want)
+	}
 }
 
 func TestLex_IncludeAndMatch(t *testing.T) {
@@ -249,6 +255,30 @@ func TestLex_KeySpaceEqualsSpaceValue(t *testing.T) {
 	assertTokens(t, tokens, want)
 }
 
+func TestLex_EqualsInsideArgument(t *testing.T) {
+	// only the first '=' separates keyword and arguments
+	input := "SetEnv FOO=bar\n"
+	tokens, err := Lex(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Token{
+		{Kind: TokenIdent, Value: "SetEnv", Line: 1, Column: 1},
+		{Kind: TokenValue, Value: "FOO=bar", Line: 1, Column: 8},
+		{Kind: TokenNewline, Value: "\n", Line: 1, Column: 15},
+		{Kind: TokenEOF, Value: "", Line: 2, Column: 1},
+	}
+	assertTokens(t, tokens, want)
+
+	tokens, err = Lex("SetEnv=FOO=bar\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens[1].Kind != TokenEquals || tokens[2].Kind != TokenValue || tokens[2].Value != "FOO=bar" {
+		t.Errorf("Lex() = %v, want SetEnv = FOO=bar", tokens)
+	}
+}
+
 func TestLex_NegatedPattern(t *testing.T) {
 	// "A pattern entry may be negated by prefixing it with an exclamation mark ('!')"
 	input := "Host !*.dialup.example.com\n"
@@ -460,3 +490,69 @@ func TestLex_LexErrorPath(t *testing.T) {
 		t.Error("error message should not be empty")
 	}
 }
+
+func TestLex_Offsets(t *testing.T) {
+	input := "Host a\n  User = \"b c\" # x\n"
+	tokens, err := Lex(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Host", "a", "\n", "User", "=", "\"b c\"", "# x", "\n", ""}
+	if len(tokens) != len(want) {
+		t.Fatalf("len(tokens) = %d, want %d", len(tokens), len(want))
+	}
+	for i, tok := range tokens {
+		if got := input[tok.Offset:tok.End]; got != want[i] {
+			t.Errorf("token[%d] spans %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestLexAll_Diagnostics(t *testing.T) {
+	input := "Host a\n" +
+		"    User \"unclosed\n" +
+		"    = orphan\n" +
+		"    Port = = 22\n" +
+		"    Ho$tName x\n" +
+		"    ProxyCommand nc\x01 %h\n" +
+		"    \"User\" quoted\n" +
+		"    LocalCommand \xff\n" +
+		"    HostName ok.example.com\n"
+	tokens, diagnostics := LexAll(input)
+	want := []Diagnostic{
+		{Line: 2, Column: 10, Message: "unclosed quoted string"},
+		{Line: 3, Column: 5, Message: "'=' without a keyword"},
+		{Line: 4, Column: 12, Message: "more than one '=' between keyword and arguments"},
+		{Line: 5, Column: 7, Message: `invalid character '$' in keyword "Ho$tName"`},
+		{Line: 6, Column: 20, Message: "control character U+0001"},
+		{Line: 7, Column: 5, Message: "quoted string where a keyword is expected"},
+		{Line: 8, Column: 18, Message: "invalid UTF-8 byte 0xff"},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("diagnostics =\n%v\nwant\n%v", diagnostics, want)
+	}
+
+	// lexing resumes on the next line
+	last := tokens[len(tokens)-4]
+	if last.Kind != TokenIdent || last.Value != "HostName" || last.Line != 9 {
+		t.Errorf("token = %v, want HostName on line 9", last)
+	}
+	if got := want[0].Error(); got != "line 2 column 10: unclosed quoted string" {
+		t.Errorf("Diagnostic.Error() = %q", got)
+	}
+}
+
+func TestLexAll_ValidInput(t *testing.T) {
+	input := "Host a\n    SetEnv FOO=bar\n    User=\"b c\" # note\n"
+	tokens, diagnostics := LexAll(input)
+	if len(diagnostics) != 0 {
+		t.Fatalf("diagnostics = %v, want none", diagnostics)
+	}
+	strict, err := Lex(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, strict) {
+		t.Errorf("LexAll() = %v, want %v", tokens, strict)
+	}
+}
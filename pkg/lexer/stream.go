@@ -0,0 +1,90 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lexer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// StreamLexer tokenizes input read from an io.Reader one line at a time, so
+// memory stays bounded by the longest line instead of the whole input. It
+// produces the same tokens, positions included, as Lex.
+type StreamLexer struct {
+	reader *bufio.Reader
+	// line and offset are the position of the next chunk in the input.
+	line   int
+	offset int
+	// pending holds the tokens of the current chunk, next is the first one
+	// not returned yet; the buffer is reused for every chunk.
+	pending []Token
+	next    int
+	eof     bool
+	// end is the EOF token, once the reader is exhausted.
+	end Token
+	err error
+}
+
+// NewStreamLexer returns a lexer reading from r.
+func NewStreamLexer(r io.Reader) *StreamLexer {
+	return &StreamLexer{reader: bufio.NewReader(r), line: 1}
+}
+
+// NextToken returns the next token. After TokenEOF or an error every call
+// returns the same result.
+func (s *StreamLexer) NextToken() (Token, error) {
+	for s.next >= len(s.pending) {
+		if s.err != nil {
+			return Token{}, s.err
+		}
+		if s.eof {
+			return s.end, nil
+		}
+		s.err = s.fill()
+	}
+	token := s.pending[s.next]
+	s.next++
+	return token, nil
+}
+
+// fill lexes the next line. A quote ends with its line, so an unclosed one
+// is an error of that line and the lines after it are not read.
+func (s *StreamLexer) fill() error {
+	s.pending = s.pending[:0]
+	s.next = 0
+	line, err := s.reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	s.eof = err != nil
+
+	l := newLexerAt(line, s.line, s.offset)
+	for {
+		token, err := l.NextToken()
+		if err != nil {
+			return err
+		}
+		if token.Kind == TokenEOF {
+			s.end = token
+			s.line = l.line
+			s.offset += len(line)
+			return nil
+		}
+		s.pending = append(s.pending, token)
+	}
+}
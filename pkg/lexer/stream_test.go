@@ -0,0 +1,155 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func streamTokens(t *testing.T, r io.Reader) ([]Token, error) {
+	t.Helper()
+	l := NewStreamLexer(r)
+	var tokens []Token
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Kind == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func TestStreamLexer_MatchesLex(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n\n",
+		"Host a",
+		"# note\nHost a b\n    HostName=a.example.com\n    SetEnv FOO=bar\n",
+		"Host a\r\n  User \"quoted user\"\r\n  Port 22\r\n",
+		"Host a\n    ProxyCommand \"ssh -W %h:%p\" # jump\n",
+	}
+	for _, input := range inputs {
+		want, err := Lex(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := streamTokens(t, strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("NextToken() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("stream tokens of %q =\n%v\nwant\n%v", input, got, want)
+		}
+	}
+}
+
+func TestStreamLexer_UnclosedQuote(t *testing.T) {
+	for _, input := range []string{
+		"Host a\n    User b\n    HostName \"unclosed\n",
+		"Host a\n    User \"bob\n    Port abc\n    ProxyCommand \"nc %h %p\n",
+	} {
+		_, want := Lex(input)
+		_, err := streamTokens(t, strings.NewReader(input))
+		if err == nil || want == nil || err.Error() != want.Error() {
+			t.Errorf("NextToken() error = %v, want %v", err, want)
+		}
+	}
+}
+
+// lineReader counts the bytes read from it.
+type lineReader struct {
+	io.Reader
+	read int
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func TestStreamLexer_UnclosedQuoteStopsReading(t *testing.T) {
+	input := "Host a\n    User \"bob\n" + strings.Repeat("    Port 22\n", 100000)
+	r := &lineReader{Reader: strings.NewReader(input)}
+	if _, err := streamTokens(t, r); err == nil {
+		t.Fatal("NextToken() expected an error for the unclosed quote")
+	}
+	if r.read >= len(input) {
+		t.Errorf("NextToken() read %d bytes, the whole input", r.read)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestStreamLexer_ReadError(t *testing.T) {
+	l := NewStreamLexer(failingReader{})
+	for i := 0; i < 2; i++ {
+		if _, err := l.NextToken(); err == nil || err.Error() != "disk on fire" {
+			t.Errorf("NextToken() error = %v, want the read error", err)
+		}
+	}
+}
+
+// benchmarkConfig returns a generated config with the given number of hosts.
+func benchmarkConfig(hosts int) string {
+	var b strings.Builder
+	for i := 0; i < hosts; i++ {
+		fmt.Fprintf(&b, "# host %d\nHost host-%d\n    HostName 10.0.%d.%d\n    User deploy\n    Port 22\n    IdentityFile ~/.ssh/id_%d\n\n", i, i, i/256%256, i%256, i)
+	}
+	return b.String()
+}
+
+func BenchmarkLex(b *testing.B) {
+	input := benchmarkConfig(20000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Lex(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamLexer(b *testing.B) {
+	input := benchmarkConfig(20000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		l := NewStreamLexer(strings.NewReader(input))
+		for {
+			tok, err := l.NextToken()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if tok.Kind == TokenEOF {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"slices"
+	"strings"
+)
+
+var (
+	ciphers = []string{
+		"3des-cbc",
+		"aes128-cbc",
+		"aes192-cbc",
+		"aes256-cbc",
+		"aes128-ctr",
+		"aes192-ctr",
+		"aes256-ctr",
+		"aes128-gcm@openssh.com",
+		"aes256-gcm@openssh.com",
+		"chacha20-poly1305@openssh.com",
+	}
+	macs = withETM(
+		"hmac-md5",
+		"hmac-md5-96",
+		"hmac-sha1",
+		"hmac-sha1-96",
+		"hmac-sha2-256",
+		"hmac-sha2-512",
+		"umac-64@openssh.com",
+		"umac-128@openssh.com",
+	)
+	kexAlgorithms = []string{
+		"curve25519-sha256",
+		"curve25519-sha256@libssh.org",
+		"diffie-hellman-group1-sha1",
+		"diffie-hellman-group14-sha1",
+		"diffie-hellman-group14-sha256",
+		"diffie-hellman-group16-sha512",
+		"diffie-hellman-group18-sha512",
+		"diffie-hellman-group-exchange-sha1",
+		"diffie-hellman-group-exchange-sha256",
+		"ecdh-sha2-nistp256",
+		"ecdh-sha2-nistp384",
+		"ecdh-sha2-nistp521",
+		"mlkem768x25519-sha256",
+		"sntrup761x25519-sha512",
+		"sntrup761x25519-sha512@openssh.com",
+	}
+	signatureAlgorithms = []string{
+		"ecdsa-sha2-nistp256",
+		"ecdsa-sha2-nistp384",
+		"ecdsa-sha2-nistp521",
+		"rsa-sha2-256",
+		"rsa-sha2-512",
+		"sk-ecdsa-sha2-nistp256@openssh.com",
+		"sk-ssh-ed25519@openssh.com",
+		"ssh-dss",
+		"ssh-ed25519",
+		"ssh-rsa",
+		"webauthn-sk-ecdsa-sha2-nistp256@openssh.com",
+	}
+	keyAlgorithms = append(slices.Clone(signatureAlgorithms),
+		"ecdsa-sha2-nistp256-cert-v01@openssh.com",
+		"ecdsa-sha2-nistp384-cert-v01@openssh.com",
+		"ecdsa-sha2-nistp521-cert-v01@openssh.com",
+		"rsa-sha2-256-cert-v01@openssh.com",
+		"rsa-sha2-512-cert-v01@openssh.com",
+		"sk-ecdsa-sha2-nistp256-cert-v01@openssh.com",
+		"sk-ssh-ed25519-cert-v01@openssh.com",
+		"ssh-dss-cert-v01@openssh.com",
+		"ssh-ed25519-cert-v01@openssh.com",
+		"ssh-rsa-cert-v01@openssh.com",
+	)
+)
+
+// withETM adds the encrypt-then-MAC variant of each MAC.
+func withETM(names ...string) []string {
+	all := slices.Clone(names)
+	for _, name := range names {
+		base, _ := strings.CutSuffix(name, "@openssh.com")
+		all = append(all, base+"-etm@openssh.com")
+	}
+	return all
+}
+
+// Algorithms lists the algorithms OpenSSH knows for each algorithm list
+// keyword, whether or not it enables them by default.
+var Algorithms = map[string][]string{
+	"CASignatureAlgorithms":       signatureAlgorithms,
+	"Ciphers":                     ciphers,
+	"HostbasedAcceptedAlgorithms": keyAlgorithms,
+	"HostKeyAlgorithms":           keyAlgorithms,
+	"KexAlgorithms":               kexAlgorithms,
+	"MACs":                        macs,
+	"PubkeyAcceptedAlgorithms":    keyAlgorithms,
+}
+
+// SplitAlgorithms splits an algorithm list into its modifier, one of '+'
+// (append to the defaults), '-' (remove from them), '^' (put first) or 0 to
+// replace them, and its comma-separated names.
+func SplitAlgorithms(value string) (modifier byte, names []string) {
+	if value != "" && strings.ContainsRune("+-^", rune(value[0])) {
+		modifier, value = value[0], value[1:]
+	}
+	return modifier, strings.Split(value, ",")
+}
+
+// algorithms accepts a list of the algorithms keyword knows, wildcards
+// included. Names removed with '-' are not checked, so one config can serve
+// OpenSSH versions that do not all know them.
+func algorithms(keyword string) checker {
+	known := Algorithms[keyword]
+	return func(value string) []Problem {
+		parts, problems := arguments(value, 1, 1)
+		if problems != nil {
+			return problems
+		}
+		modifier, names := SplitAlgorithms(parts[0].text)
+		offset := parts[0].offset
+		if modifier != 0 {
+			offset++
+		}
+		for _, name := range names {
+			switch {
+			case name == "":
+				problems = append(problems, problem(offset, "empty algorithm name")...)
+			case modifier == '-':
+			case strings.ContainsAny(name, "*?"):
+				if !slices.ContainsFunc(known, func(algorithm string) bool { return matchWildcard(name, algorithm) }) {
+					problems = append(problems, problem(offset, "%q matches no known algorithm", name)...)
+				}
+			case !slices.Contains(known, name):
+				problems = append(problems, problem(offset, "unknown algorithm %q", name)...)
+			}
+			offset += len(name) + 1
+		}
+		return problems
+	}
+}
+
+// matchWildcard matches name against pattern, where '*' matches any run of
+// characters and '?' exactly one.
+func matchWildcard(pattern, name string) bool {
+	if pattern == "" {
+		return name == ""
+	}
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(name); i++ {
+			if matchWildcard(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return name != "" && matchWildcard(pattern[1:], name[1:])
+	}
+	return name != "" && pattern[0] == name[0] && matchWildcard(pattern[1:], name[1:])
+}
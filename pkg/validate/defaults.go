@@ -0,0 +1,255 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is an OpenSSH release, e.g. 9.6 for OpenSSH_9.6p1.
+type Version struct {
+	Major, Minor int
+}
+
+// LatestVersion is the newest release whose defaults are known.
+var LatestVersion = Version{10, 0}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (v Version) Compare(other Version) int {
+	if v.Major != other.Major {
+		return v.Major - other.Major
+	}
+	return v.Minor - other.Minor
+}
+
+// ParseVersion reads a version such as 9.6, 9.6p1 or OpenSSH_9.6p1, as
+// ssh -V prints it.
+func ParseVersion(text string) (Version, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(text), "OpenSSH_")
+	rest, _, _ = strings.Cut(rest, "p")
+	majorText, minorText, ok := strings.Cut(rest, ".")
+	major, majorErr := strconv.Atoi(majorText)
+	minor, minorErr := strconv.Atoi(minorText)
+	if !ok || majorErr != nil || minorErr != nil || major < 0 || minor < 0 {
+		return Version{}, fmt.Errorf("invalid OpenSSH version %q, use a version such as 9.6", text)
+	}
+	return Version{major, minor}, nil
+}
+
+// defaultChanges lists the client defaults of ssh_config(5) for the releases
+// that changed them, oldest first. A release only lists the keywords that
+// changed; releases before the first one get its lists.
+var defaultChanges = []struct {
+	since Version
+	lists map[string][]string
+}{
+	{Version{7, 4}, map[string][]string{
+		"Ciphers": {
+			"chacha20-poly1305@openssh.com",
+			"aes128-ctr", "aes192-ctr", "aes256-ctr",
+			"aes128-gcm@openssh.com", "aes256-gcm@openssh.com",
+		},
+		"MACs": {
+			"umac-64-etm@openssh.com", "umac-128-etm@openssh.com",
+			"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com",
+			"hmac-sha1-etm@openssh.com",
+			"umac-64@openssh.com", "umac-128@openssh.com",
+			"hmac-sha2-256", "hmac-sha2-512", "hmac-sha1",
+		},
+		"KexAlgorithms": {
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group-exchange-sha1",
+			"diffie-hellman-group14-sha256", "diffie-hellman-group14-sha1",
+		},
+		"HostKeyAlgorithms": {
+			"ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp384-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp521-cert-v01@openssh.com",
+			"ssh-ed25519-cert-v01@openssh.com",
+			"ssh-rsa-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+			"ssh-ed25519",
+			"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa",
+		},
+	}},
+	{Version{8, 2}, map[string][]string{
+		"KexAlgorithms": {
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group14-sha256",
+		},
+		"HostKeyAlgorithms": {
+			"ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp384-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp521-cert-v01@openssh.com",
+			"sk-ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"ssh-ed25519-cert-v01@openssh.com",
+			"sk-ssh-ed25519-cert-v01@openssh.com",
+			"rsa-sha2-512-cert-v01@openssh.com",
+			"rsa-sha2-256-cert-v01@openssh.com",
+			"ssh-rsa-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+			"sk-ecdsa-sha2-nistp256@openssh.com",
+			"ssh-ed25519", "sk-ssh-ed25519@openssh.com",
+			"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa",
+		},
+	}},
+	{Version{8, 5}, map[string][]string{
+		"KexAlgorithms": {
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"sntrup761x25519-sha512@openssh.com",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group14-sha256",
+		},
+		"HostKeyAlgorithms": {
+			"ssh-ed25519-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp384-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp521-cert-v01@openssh.com",
+			"sk-ssh-ed25519-cert-v01@openssh.com",
+			"sk-ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"rsa-sha2-512-cert-v01@openssh.com",
+			"rsa-sha2-256-cert-v01@openssh.com",
+			"ssh-rsa-cert-v01@openssh.com",
+			"ssh-ed25519",
+			"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+			"sk-ssh-ed25519@openssh.com", "sk-ecdsa-sha2-nistp256@openssh.com",
+			"rsa-sha2-512", "rsa-sha2-256", "ssh-rsa",
+		},
+	}},
+	{Version{8, 8}, map[string][]string{
+		"HostKeyAlgorithms": {
+			"ssh-ed25519-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp384-cert-v01@openssh.com",
+			"ecdsa-sha2-nistp521-cert-v01@openssh.com",
+			"sk-ssh-ed25519-cert-v01@openssh.com",
+			"sk-ecdsa-sha2-nistp256-cert-v01@openssh.com",
+			"rsa-sha2-512-cert-v01@openssh.com",
+			"rsa-sha2-256-cert-v01@openssh.com",
+			"ssh-ed25519",
+			"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+			"sk-ssh-ed25519@openssh.com", "sk-ecdsa-sha2-nistp256@openssh.com",
+			"rsa-sha2-512", "rsa-sha2-256",
+		},
+	}},
+	{Version{9, 0}, map[string][]string{
+		"KexAlgorithms": {
+			"sntrup761x25519-sha512@openssh.com",
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group14-sha256",
+		},
+	}},
+	{Version{9, 9}, map[string][]string{
+		"KexAlgorithms": {
+			"sntrup761x25519-sha512", "sntrup761x25519-sha512@openssh.com",
+			"mlkem768x25519-sha256",
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group14-sha256",
+		},
+	}},
+	{Version{10, 0}, map[string][]string{
+		"KexAlgorithms": {
+			"mlkem768x25519-sha256",
+			"sntrup761x25519-sha512", "sntrup761x25519-sha512@openssh.com",
+			"curve25519-sha256", "curve25519-sha256@libssh.org",
+			"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+			"diffie-hellman-group-exchange-sha256",
+			"diffie-hellman-group16-sha512", "diffie-hellman-group18-sha512",
+			"diffie-hellman-group14-sha256",
+		},
+	}},
+}
+
+// DefaultAlgorithms returns the algorithms ssh of version enables for
+// keyword when the config does not set it, nil for a keyword without a
+// known default.
+func DefaultAlgorithms(keyword string, version Version) []string {
+	var list []string
+	for _, change := range defaultChanges {
+		if list != nil && change.since.Compare(version) > 0 {
+			break
+		}
+		if changed, ok := change.lists[keyword]; ok {
+			list = changed
+		}
+	}
+	return slices.Clone(list)
+}
+
+// EffectiveAlgorithms returns the algorithms ssh of version uses for an
+// algorithm list value: '+' appends the names to the defaults, '-' removes
+// them, '^' puts them first, and a list without modifier replaces them.
+// Wildcards are matched against the algorithms OpenSSH knows.
+func EffectiveAlgorithms(keyword, value string, version Version) []string {
+	defaults := DefaultAlgorithms(keyword, version)
+	modifier, names := SplitAlgorithms(value)
+	if modifier == '-' {
+		return slices.DeleteFunc(defaults, func(algorithm string) bool {
+			return slices.ContainsFunc(names, func(name string) bool { return matchWildcard(name, algorithm) })
+		})
+	}
+
+	var listed []string
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?") {
+			listed = append(listed, name)
+			continue
+		}
+		for _, algorithm := range Algorithms[keyword] {
+			if matchWildcard(name, algorithm) {
+				listed = append(listed, algorithm)
+			}
+		}
+	}
+	switch modifier {
+	case '+':
+		return appendMissing(defaults, listed)
+	case '^':
+		return appendMissing(appendMissing(nil, listed), defaults)
+	}
+	return appendMissing(nil, listed)
+}
+
+// appendMissing appends the names of extra that list does not hold yet.
+func appendMissing(list, extra []string) []string {
+	for _, name := range extra {
+		if name != "" && !slices.Contains(list, name) {
+			list = append(list, name)
+		}
+	}
+	return list
+}
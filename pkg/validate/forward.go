@@ -0,0 +1,153 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// localForward accepts "[bind_address:]port host:hostport", either side may
+// be a Unix socket path instead.
+func localForward(value string) []Problem {
+	parts, problems := arguments(value, 2, 2)
+	if problems != nil {
+		if len(fields(value)) == 1 {
+			return problem(len(value), "missing target, use [bind_address:]port host:hostport")
+		}
+		return problems
+	}
+	if problems := checkListen(parts[0], false); problems != nil {
+		return problems
+	}
+	return checkTarget(parts[1])
+}
+
+// remoteForward is localForward where the listening port may be 0, to let
+// the server pick one. Without a target it is a dynamic forward on the server.
+func remoteForward(value string) []Problem {
+	parts, problems := arguments(value, 1, 2)
+	if problems != nil {
+		return problems
+	}
+	if problems := checkListen(parts[0], true); problems != nil {
+		return problems
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+	return checkTarget(parts[1])
+}
+
+// dynamicForward accepts "[bind_address:]port".
+func dynamicForward(value string) []Problem {
+	parts, problems := arguments(value, 1, 1)
+	if problems != nil {
+		return problems
+	}
+	if isSocketPath(parts[0].text) {
+		return problem(parts[0].offset, "expected [bind_address:]port")
+	}
+	return checkListen(parts[0], false)
+}
+
+// checkListen checks "[bind_address:]port" or a socket path.
+func checkListen(part field, anyPort bool) []Problem {
+	if isSocketPath(part.text) {
+		return nil
+	}
+	spec := splitForward(part)
+	switch len(spec) {
+	case 1:
+		return checkForwardPort(spec[0], anyPort)
+	case 2:
+		return checkForwardPort(spec[1], anyPort)
+	}
+	return problem(part.offset, "expected [bind_address:]port, enclose IPv6 addresses in []")
+}
+
+// checkTarget checks "host:hostport" or a socket path.
+func checkTarget(part field) []Problem {
+	if isSocketPath(part.text) {
+		return nil
+	}
+	spec := splitForward(part)
+	if len(spec) != 2 || spec[0].text == "" {
+		return problem(part.offset, "expected host:hostport, enclose IPv6 addresses in []")
+	}
+	return checkForwardPort(spec[1], false)
+}
+
+// checkForwardPort accepts a port number or a service name such as http,
+// 0 only when anyPort is set.
+func checkForwardPort(part field, anyPort bool) []Problem {
+	if part.text == "" {
+		return problem(part.offset, "missing port")
+	}
+	number, err := strconv.Atoi(part.text)
+	if err != nil {
+		if isServiceName(part.text) {
+			return nil
+		}
+		return problem(part.offset, "expected a port number")
+	}
+	min := 1
+	if anyPort {
+		min = 0
+	}
+	if number < min || number > 65535 {
+		return problem(part.offset, "port %d is out of range %d-65535", number, min)
+	}
+	return nil
+}
+
+func isServiceName(text string) bool {
+	if text == "" || text[0] < 'a' || text[0] > 'z' {
+		return false
+	}
+	return strings.Trim(strings.ToLower(text), "abcdefghijklmnopqrstuvwxyz0123456789-") == ""
+}
+
+// isSocketPath reports whether a forward side is a Unix domain socket.
+func isSocketPath(text string) bool {
+	return strings.Contains(text, "/")
+}
+
+// splitForward splits a forward side on ':', an address in brackets, e.g.
+// "[::1]:8080", is one part and is returned without them.
+func splitForward(part field) []field {
+	var spec []field
+	text, offset := part.text, part.offset
+	for {
+		if strings.HasPrefix(text, "[") {
+			if end := strings.Index(text, "]"); end > 0 && (end+1 == len(text) || text[end+1] == ':') {
+				spec = append(spec, field{text[1:end], offset + 1})
+				if end+1 == len(text) {
+					return spec
+				}
+				text, offset = text[end+2:], offset+end+2
+				continue
+			}
+		}
+		head, rest, found := strings.Cut(text, ":")
+		spec = append(spec, field{head, offset})
+		if !found {
+			return spec
+		}
+		text, offset = rest, offset+len(head)+1
+	}
+}
@@ -0,0 +1,131 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AlgorithmRule limits the algorithms of one keyword. Names may use the
+// '*' and '?' wildcards.
+type AlgorithmRule struct {
+	// Allow lists the algorithms the keyword may enable, any when empty.
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny lists the algorithms it must not enable, whatever Allow says.
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// Policy holds the rule of each algorithm list keyword.
+type Policy map[string]AlgorithmRule
+
+// ModernPolicy is the built-in baseline: it denies the algorithms OpenSSH
+// has dropped from its defaults for being weak, CBC ciphers, MD5 and
+// truncated MACs, SHA-1 key exchange, and SHA-1 RSA and DSA host keys.
+var ModernPolicy = Policy{
+	"Ciphers": {Deny: []string{"*-cbc", "arcfour*", "rijndael-cbc@lysator.liu.se", "none"}},
+	"MACs":    {Deny: []string{"hmac-md5*", "*-96", "*-96-etm@openssh.com", "hmac-ripemd160*"}},
+	"KexAlgorithms": {Deny: []string{
+		"diffie-hellman-group1-sha1",
+		"diffie-hellman-group14-sha1",
+		"diffie-hellman-group-exchange-sha1",
+	}},
+	"HostKeyAlgorithms": {Deny: []string{"ssh-rsa", "ssh-rsa-cert-v01@openssh.com", "ssh-dss*"}},
+}
+
+// PolicyKeywords are the keywords a policy applies to.
+var PolicyKeywords = []string{"Ciphers", "MACs", "KexAlgorithms", "HostKeyAlgorithms"}
+
+// Baselines are the policies a policy file can build on.
+var Baselines = map[string]Policy{
+	"modern": ModernPolicy,
+	"none":   {},
+}
+
+// ParsePolicy reads a YAML policy file: a baseline, "modern" when not set,
+// and an allow and deny list for any of PolicyKeywords. The deny lists add
+// to the baseline's, an allow list replaces it.
+//
+//	baseline: modern
+//	Ciphers:
+//	  allow: [chacha20-poly1305@openssh.com, aes256-gcm@openssh.com]
+//	KexAlgorithms:
+//	  deny: [ecdh-sha2-nistp*]
+func ParsePolicy(data []byte) (Policy, error) {
+	var file struct {
+		Baseline string                   `yaml:"baseline"`
+		Rules    map[string]AlgorithmRule `yaml:",inline"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Baseline == "" {
+		file.Baseline = "modern"
+	}
+	baseline, ok := Baselines[file.Baseline]
+	if !ok {
+		return nil, fmt.Errorf("unknown baseline %q, use one of %v", file.Baseline, slices.Sorted(maps.Keys(Baselines)))
+	}
+
+	policy := maps.Clone(baseline)
+	for keyword, rule := range file.Rules {
+		if !slices.Contains(PolicyKeywords, keyword) {
+			return nil, fmt.Errorf("unknown keyword %q, use one of %v", keyword, PolicyKeywords)
+		}
+		merged := policy[keyword]
+		if rule.Allow != nil {
+			merged.Allow = rule.Allow
+		}
+		merged.Deny = append(slices.Clone(merged.Deny), rule.Deny...)
+		policy[keyword] = merged
+	}
+	return policy, nil
+}
+
+// PolicyViolation is an algorithm a policy does not accept.
+type PolicyViolation struct {
+	Keyword   string
+	Algorithm string
+	// Pattern is the deny entry it matched, empty when it is missing from
+	// the allow list.
+	Pattern string
+}
+
+func (v PolicyViolation) Error() string {
+	if v.Pattern != "" {
+		return fmt.Sprintf("%s %s is denied by %q", v.Keyword, v.Algorithm, v.Pattern)
+	}
+	return fmt.Sprintf("%s %s is not allowed", v.Keyword, v.Algorithm)
+}
+
+// Check returns the algorithms of list the rule of keyword does not accept.
+func (p Policy) Check(keyword string, list []string) []PolicyViolation {
+	rule := p[keyword]
+	var violations []PolicyViolation
+	for _, algorithm := range list {
+		matches := func(pattern string) bool { return matchWildcard(pattern, algorithm) }
+		if i := slices.IndexFunc(rule.Deny, matches); i >= 0 {
+			violations = append(violations, PolicyViolation{keyword, algorithm, rule.Deny[i]})
+		} else if len(rule.Allow) > 0 && !slices.ContainsFunc(rule.Allow, matches) {
+			violations = append(violations, PolicyViolation{Keyword: keyword, Algorithm: algorithm})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,134 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		text string
+		want Version
+	}{
+		{"9.6", Version{9, 6}},
+		{"8.8p1", Version{8, 8}},
+		{"OpenSSH_10.0p2", Version{10, 0}},
+	}
+	for _, tt := range tests {
+		if got, err := ParseVersion(tt.text); got != tt.want || err != nil {
+			t.Errorf("ParseVersion(%q) = %v, %v, want %v", tt.text, got, err, tt.want)
+		}
+	}
+	for _, text := range []string{"", "9", "9.x", "latest"} {
+		if _, err := ParseVersion(text); err == nil {
+			t.Errorf("ParseVersion(%q) expected an error", text)
+		}
+	}
+}
+
+func TestDefaultAlgorithms(t *testing.T) {
+	if got := DefaultAlgorithms("HostKeyAlgorithms", Version{8, 7}); !slices.Contains(got, "ssh-rsa") {
+		t.Errorf("DefaultAlgorithms(HostKeyAlgorithms, 8.7) = %v, want ssh-rsa", got)
+	}
+	if got := DefaultAlgorithms("HostKeyAlgorithms", Version{8, 8}); slices.Contains(got, "ssh-rsa") {
+		t.Errorf("DefaultAlgorithms(HostKeyAlgorithms, 8.8) = %v, want no ssh-rsa", got)
+	}
+	if got := DefaultAlgorithms("KexAlgorithms", Version{6, 0}); !reflect.DeepEqual(got, defaultChanges[0].lists["KexAlgorithms"]) {
+		t.Errorf("DefaultAlgorithms(KexAlgorithms, 6.0) = %v, want the oldest defaults", got)
+	}
+	if got := DefaultAlgorithms("KexAlgorithms", LatestVersion)[0]; got != "mlkem768x25519-sha256" {
+		t.Errorf("DefaultAlgorithms(KexAlgorithms, latest)[0] = %s", got)
+	}
+	if got := DefaultAlgorithms("PubkeyAcceptedAlgorithms", LatestVersion); got != nil {
+		t.Errorf("DefaultAlgorithms(PubkeyAcceptedAlgorithms) = %v, want nil", got)
+	}
+}
+
+func TestEffectiveAlgorithms(t *testing.T) {
+	defaults := DefaultAlgorithms("Ciphers", LatestVersion)
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"aes256-ctr,aes128-cbc,aes256-ctr", []string{"aes256-ctr", "aes128-cbc"}},
+		{"+aes128-cbc,aes128-ctr", append(slices.Clone(defaults), "aes128-cbc")},
+		{"-aes*-ctr", []string{"chacha20-poly1305@openssh.com", "aes128-gcm@openssh.com", "aes256-gcm@openssh.com"}},
+		{"^aes256-gcm@openssh.com", append([]string{"aes256-gcm@openssh.com"}, slices.DeleteFunc(slices.Clone(defaults), func(name string) bool {
+			return name == "aes256-gcm@openssh.com"
+		})...)},
+		{"aes*-cbc", []string{"aes128-cbc", "aes192-cbc", "aes256-cbc"}},
+	}
+	for _, tt := range tests {
+		if got := EffectiveAlgorithms("Ciphers", tt.value, LatestVersion); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EffectiveAlgorithms(Ciphers, %q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	for _, keyword := range PolicyKeywords {
+		if violations := ModernPolicy.Check(keyword, DefaultAlgorithms(keyword, LatestVersion)); violations != nil {
+			t.Errorf("ModernPolicy rejects the %s defaults: %v", keyword, violations)
+		}
+	}
+
+	list := EffectiveAlgorithms("HostKeyAlgorithms", "+ssh-rsa,ssh-dss", LatestVersion)
+	violations := ModernPolicy.Check("HostKeyAlgorithms", list)
+	want := []string{`HostKeyAlgorithms ssh-rsa is denied by "ssh-rsa"`, `HostKeyAlgorithms ssh-dss is denied by "ssh-dss*"`}
+	var got []string
+	for _, violation := range violations {
+		got = append(got, violation.Error())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+Ciphers:
+  allow: [chacha20-poly1305@openssh.com, aes256-gcm@openssh.com]
+KexAlgorithms:
+  deny: [ecdh-sha2-nistp*]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := policy.Check("Ciphers", []string{"chacha20-poly1305@openssh.com", "aes128-ctr", "3des-cbc"})
+	if len(violations) != 2 || violations[0].Error() != "Ciphers aes128-ctr is not allowed" || violations[1].Pattern != "*-cbc" {
+		t.Errorf("Check(Ciphers) = %v", violations)
+	}
+	if violations := policy.Check("KexAlgorithms", []string{"ecdh-sha2-nistp256", "diffie-hellman-group1-sha1"}); len(violations) != 2 {
+		t.Errorf("Check(KexAlgorithms) = %v, want the baseline and the file denials", violations)
+	}
+	if len(ModernPolicy["KexAlgorithms"].Deny) != 3 {
+		t.Error("ParsePolicy() changed ModernPolicy")
+	}
+
+	policy, err = ParsePolicy([]byte("baseline: none\n"))
+	if err != nil || len(policy) != 0 {
+		t.Errorf("ParsePolicy(baseline: none) = %v, %v", policy, err)
+	}
+	for _, data := range []string{"baseline: strict\n", "PubkeyAcceptedKeyTypes:\n  deny: [ssh-rsa]\n", "Ciphers:\n  allowed: [x]\n"} {
+		if _, err := ParsePolicy([]byte(data)); err == nil {
+			t.Errorf("ParsePolicy(%q) expected an error", data)
+		}
+	}
+}
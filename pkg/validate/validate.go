@@ -0,0 +1,427 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package validate checks ssh_config values against the type each keyword
+// expects, following ssh_config(5): https://man.openbsd.org/ssh_config
+// - Keywords that take free text, such as HostName or ProxyCommand, accept
+// any value.
+// - Enumerations are compared ignoring case, and true and false stand for
+// yes and no, like ssh does.
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Problem is a value, or a part of it, that ssh would refuse.
+type Problem struct {
+	Key   string
+	Value string
+	// Offset is the byte offset of the offending part in Value.
+	Offset  int
+	Message string
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s %s: %s", p.Key, strconv.Quote(p.Value), p.Message)
+}
+
+// checker returns the problems of a value, Key and Value are filled by Check.
+type checker func(value string) []Problem
+
+// Check reports why value is not valid for key, nil when it is or when key
+// takes free text. Keys are matched ignoring case.
+func Check(key, value string) []Problem {
+	check, ok := keywordTypes[strings.ToLower(key)]
+	if !ok {
+		return nil
+	}
+	problems := check(value)
+	for i := range problems {
+		problems[i].Key = key
+		problems[i].Value = value
+	}
+	return problems
+}
+
+func problem(offset int, format string, args ...any) []Problem {
+	return []Problem{{Offset: offset, Message: fmt.Sprintf(format, args...)}}
+}
+
+// field is a whitespace separated part of a value and its offset.
+type field struct {
+	text   string
+	offset int
+}
+
+func fields(value string) []field {
+	var parts []field
+	start := -1
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) && value[i] != ' ' && value[i] != '\t' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			parts = append(parts, field{value[start:i], start})
+			start = -1
+		}
+	}
+	return parts
+}
+
+// arguments checks that value has between min and max fields.
+func arguments(value string, min, max int) ([]field, []Problem) {
+	parts := fields(value)
+	if len(parts) < min {
+		if len(parts) == 0 {
+			return nil, problem(0, "missing value")
+		}
+		return nil, problem(len(value), "missing argument")
+	}
+	if len(parts) > max {
+		return nil, problem(parts[max].offset, "unexpected argument %q", parts[max].text)
+	}
+	return parts, nil
+}
+
+// oneOf accepts one of choices, e.g. yes, no or ask.
+func oneOf(choices ...string) checker {
+	return func(value string) []Problem {
+		parts, problems := arguments(value, 1, 1)
+		if problems != nil {
+			return problems
+		}
+		if !isOneOf(parts[0].text, choices) {
+			return problem(parts[0].offset, "expected %s", describeChoices(choices))
+		}
+		return nil
+	}
+}
+
+// isOneOf reports whether text is one of choices, ignoring case. Like ssh,
+// it reads true and false as yes and no wherever those are choices.
+func isOneOf(text string, choices []string) bool {
+	switch strings.ToLower(text) {
+	case "true":
+		text = "yes"
+	case "false":
+		text = "no"
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(text, choice) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeChoices(choices []string) string {
+	if len(choices) == 1 {
+		return choices[0]
+	}
+	return strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+}
+
+var flag = oneOf("yes", "no")
+
+// integer accepts a decimal number between min and max.
+func integer(min, max int) checker {
+	return func(value string) []Problem {
+		parts, problems := arguments(value, 1, 1)
+		if problems != nil {
+			return problems
+		}
+		return checkInteger(parts[0], min, max)
+	}
+}
+
+func checkInteger(part field, min, max int) []Problem {
+	number, err := strconv.Atoi(part.text)
+	if err != nil || strings.HasPrefix(part.text, "+") {
+		return problem(part.offset, "expected an integer")
+	}
+	if number < min || number > max {
+		return problem(part.offset, "%d is out of range %d-%d", number, min, max)
+	}
+	return nil
+}
+
+// ParseInterval returns the seconds of a time interval such as "90", "1h30m"
+// or "2w": numbers followed by an optional s, m, h, d or w unit, seconds
+// when omitted.
+func ParseInterval(text string) (int, error) {
+	if text == "" {
+		return 0, fmt.Errorf("empty interval")
+	}
+	total, number, digits := 0, 0, 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c >= '0' && c <= '9' {
+			number = number*10 + int(c-'0')
+			digits++
+			continue
+		}
+		if digits == 0 {
+			return 0, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+		var unit int
+		switch c {
+		case 's', 'S':
+			unit = 1
+		case 'm', 'M':
+			unit = 60
+		case 'h', 'H':
+			unit = 60 * 60
+		case 'd', 'D':
+			unit = 24 * 60 * 60
+		case 'w', 'W':
+			unit = 7 * 24 * 60 * 60
+		default:
+			return 0, fmt.Errorf("unknown unit %q at offset %d, use s, m, h, d or w", c, i)
+		}
+		total += number * unit
+		number, digits = 0, 0
+	}
+	return total + number, nil
+}
+
+// interval accepts a time interval, or one of keywords.
+func interval(keywords ...string) checker {
+	return func(value string) []Problem {
+		parts, problems := arguments(value, 1, 1)
+		if problems != nil {
+			return problems
+		}
+		return checkInterval(parts[0], keywords)
+	}
+}
+
+func checkInterval(part field, keywords []string) []Problem {
+	if isOneOf(part.text, keywords) {
+		return nil
+	}
+	if _, err := ParseInterval(part.text); err != nil {
+		if len(keywords) > 0 {
+			return problem(part.offset, "expected %s or a time interval: %v", strings.Join(keywords, ", "), err)
+		}
+		return problem(part.offset, "expected a time interval: %v", err)
+	}
+	return nil
+}
+
+// addKeysToAgent accepts yes, no, ask or confirm, optionally followed by a
+// lifetime, or a lifetime alone.
+func addKeysToAgent(value string) []Problem {
+	parts, problems := arguments(value, 1, 2)
+	if problems != nil {
+		return problems
+	}
+	first := parts[0]
+	if isOneOf(first.text, []string{"yes", "no", "ask"}) {
+		if len(parts) > 1 {
+			return problem(parts[1].offset, "unexpected argument %q", parts[1].text)
+		}
+		return nil
+	}
+	if strings.EqualFold(first.text, "confirm") {
+		if len(parts) > 1 {
+			return checkInterval(parts[1], nil)
+		}
+		return nil
+	}
+	if len(parts) > 1 {
+		return problem(parts[1].offset, "unexpected argument %q", parts[1].text)
+	}
+	return checkInterval(first, []string{"yes", "no", "ask", "confirm"})
+}
+
+// rekeyLimit accepts a byte count with an optional K, M or G suffix, or
+// default, optionally followed by a time interval, default or none.
+func rekeyLimit(value string) []Problem {
+	parts, problems := arguments(value, 1, 2)
+	if problems != nil {
+		return problems
+	}
+	size := parts[0]
+	if !strings.EqualFold(size.text, "default") {
+		digits := strings.TrimRight(size.text, "KkMmGg")
+		if len(size.text)-len(digits) > 1 || digits == "" || strings.Trim(digits, "0123456789") != "" {
+			return problem(size.offset, "expected a size such as 1G or default")
+		}
+	}
+	if len(parts) > 1 {
+		return checkInterval(parts[1], []string{"default", "none"})
+	}
+	return nil
+}
+
+// ipQoS accepts one or two DSCP names, ToS keywords or numbers.
+func ipQoS(value string) []Problem {
+	parts, problems := arguments(value, 1, 2)
+	if problems != nil {
+		return problems
+	}
+	for _, part := range parts {
+		if isOneOf(part.text, qosNames) {
+			continue
+		}
+		if number, err := strconv.ParseInt(part.text, 0, 0); err == nil && number >= 0 && number <= 255 {
+			continue
+		}
+		return problem(part.offset, "unknown QoS %q", part.text)
+	}
+	return nil
+}
+
+var qosNames = []string{
+	"af11", "af12", "af13", "af21", "af22", "af23", "af31", "af32", "af33", "af41", "af42", "af43",
+	"cs0", "cs1", "cs2", "cs3", "cs4", "cs5", "cs6", "cs7", "ef", "le",
+	"lowdelay", "throughput", "reliability", "none",
+}
+
+// escapeChar accepts a single character, a ^ followed by a character, or none.
+func escapeChar(value string) []Problem {
+	parts, problems := arguments(value, 1, 1)
+	if problems != nil {
+		return problems
+	}
+	text := parts[0].text
+	if len(text) == 1 || len(text) == 2 && text[0] == '^' || strings.EqualFold(text, "none") {
+		return nil
+	}
+	return problem(parts[0].offset, "expected a single character, ^ and a character, or none")
+}
+
+// octal accepts a file mode mask such as 0177.
+func octal(value string) []Problem {
+	parts, problems := arguments(value, 1, 1)
+	if problems != nil {
+		return problems
+	}
+	if mask, err := strconv.ParseUint(parts[0].text, 8, 32); err != nil || mask > 0o777 {
+		return problem(parts[0].offset, "expected an octal mask between 0 and 0777")
+	}
+	return nil
+}
+
+// tunnelDevice accepts local[:remote], each a device number or any.
+func tunnelDevice(value string) []Problem {
+	parts, problems := arguments(value, 1, 1)
+	if problems != nil {
+		return problems
+	}
+	offset := parts[0].offset
+	for _, device := range strings.SplitN(parts[0].text, ":", 2) {
+		if !strings.EqualFold(device, "any") {
+			if problems := checkInteger(field{device, offset}, 0, 0x7fffffff); problems != nil {
+				return problem(offset, "expected a device number or any")
+			}
+		}
+		offset += len(device) + 1
+	}
+	return nil
+}
+
+// obscureKeystrokeTiming accepts yes, no or interval:milliseconds.
+func obscureKeystrokeTiming(value string) []Problem {
+	parts, problems := arguments(value, 1, 1)
+	if problems != nil {
+		return problems
+	}
+	part := parts[0]
+	if isOneOf(part.text, []string{"yes", "no"}) {
+		return nil
+	}
+	if milliseconds, ok := strings.CutPrefix(part.text, "interval:"); ok {
+		return checkInteger(field{milliseconds, part.offset + len("interval:")}, 1, 1000)
+	}
+	return problem(part.offset, "expected yes, no or interval:milliseconds")
+}
+
+// keywordTypes holds the checker of each typed keyword, lower-cased keys.
+var keywordTypes = map[string]checker{
+	"addkeystoagent":                   addKeysToAgent,
+	"addressfamily":                    oneOf("any", "inet", "inet6"),
+	"batchmode":                        flag,
+	"canonicalizefallbacklocal":        flag,
+	"canonicalizehostname":             oneOf("yes", "no", "always", "none"),
+	"canonicalizemaxdots":              integer(0, 1<<31-1),
+	"casignaturealgorithms":            algorithms("CASignatureAlgorithms"),
+	"checkhostip":                      flag,
+	"ciphers":                          algorithms("Ciphers"),
+	"clearallforwardings":              flag,
+	"compression":                      flag,
+	"connectionattempts":               integer(1, 1<<31-1),
+	"connecttimeout":                   interval("none"),
+	"controlmaster":                    oneOf("yes", "no", "ask", "auto", "autoask"),
+	"controlpersist":                   interval("yes", "no"),
+	"dynamicforward":                   dynamicForward,
+	"enableescapecommandline":          flag,
+	"enablesshkeysign":                 flag,
+	"escapechar":                       escapeChar,
+	"exitonforwardfailure":             flag,
+	"fingerprinthash":                  oneOf("md5", "sha256"),
+	"forkafterauthentication":          flag,
+	"forwardx11":                       flag,
+	"forwardx11timeout":                interval(),
+	"forwardx11trusted":                flag,
+	"gatewayports":                     flag,
+	"gssapiauthentication":             flag,
+	"gssapidelegatecredentials":        flag,
+	"hashknownhosts":                   flag,
+	"hostbasedacceptedalgorithms":      algorithms("HostbasedAcceptedAlgorithms"),
+	"hostbasedauthentication":          flag,
+	"hostkeyalgorithms":                algorithms("HostKeyAlgorithms"),
+	"identitiesonly":                   flag,
+	"ipqos":                            ipQoS,
+	"kbdinteractiveauthentication":     flag,
+	"kexalgorithms":                    algorithms("KexAlgorithms"),
+	"localforward":                     localForward,
+	"loglevel":                         oneOf("QUIET", "FATAL", "ERROR", "INFO", "VERBOSE", "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3"),
+	"macs":                             algorithms("MACs"),
+	"nohostauthenticationforlocalhost": flag,
+	"numberofpasswordprompts":          integer(0, 1<<31-1),
+	"obscurekeystroketiming":           obscureKeystrokeTiming,
+	"passwordauthentication":           flag,
+	"permitlocalcommand":               flag,
+	"port":                             integer(1, 65535),
+	"proxyusefdpass":                   flag,
+	"pubkeyacceptedalgorithms":         algorithms("PubkeyAcceptedAlgorithms"),
+	"pubkeyauthentication":             oneOf("yes", "no", "unbound", "host-bound"),
+	"rekeylimit":                       rekeyLimit,
+	"remoteforward":                    remoteForward,
+	"requesttty":                       oneOf("yes", "no", "force", "auto"),
+	"requiredrsasize":                  integer(1024, 16384),
+	"serveralivecountmax":              integer(0, 1<<31-1),
+	"serveraliveinterval":              interval(),
+	"sessiontype":                      oneOf("none", "subsystem", "default"),
+	"stdinnull":                        flag,
+	"streamlocalbindmask":              octal,
+	"streamlocalbindunlink":            flag,
+	"stricthostkeychecking":            oneOf("yes", "no", "ask", "accept-new", "off"),
+	"syslogfacility":                   oneOf("DAEMON", "USER", "AUTH", "LOCAL0", "LOCAL1", "LOCAL2", "LOCAL3", "LOCAL4", "LOCAL5", "LOCAL6", "LOCAL7"),
+	"tcpkeepalive":                     flag,
+	"tunnel":                           oneOf("yes", "no", "point-to-point", "ethernet"),
+	"tunneldevice":                     tunnelDevice,
+	"updatehostkeys":                   oneOf("yes", "no", "ask"),
+	"verifyhostkeydns":                 oneOf("yes", "no", "ask"),
+	"visualhostkey":                    flag,
+}
@@ -0,0 +1,137 @@
+/**
+ * Copyright 2026 Su Yang (soulteary)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"testing"
+)
+
+func TestCheck_Valid(t *testing.T) {
+	tests := []struct{ key, value string }{
+		{"Port", "2222"},
+		{"compression", "YES"},
+		{"StrictHostKeyChecking", "accept-new"},
+		{"ServerAliveInterval", "1h30m"},
+		{"ControlPersist", "no"},
+		{"ControlPersist", "10m"},
+		{"ConnectTimeout", "none"},
+		{"AddKeysToAgent", "confirm 1h"},
+		{"AddKeysToAgent", "4w"},
+		{"AddressFamily", "inet6"},
+		{"LocalForward", "8080 localhost:80"},
+		{"LocalForward", "127.0.0.1:8080 [::1]:http"},
+		{"LocalForward", "/tmp/local.sock /var/run/remote.sock"},
+		{"RemoteForward", "[::1]:0 localhost:22"},
+		{"RemoteForward", "1080"},
+		{"DynamicForward", "localhost:1080"},
+		{"Ciphers", "chacha20-poly1305@openssh.com,aes256-gcm@openssh.com"},
+		{"KexAlgorithms", "^sntrup761x25519-sha512@openssh.com"},
+		{"MACs", "-hmac-md5*,not-in-this-version"},
+		{"HostKeyAlgorithms", "+ssh-ed25519-cert-*"},
+		{"RekeyLimit", "1G 1h"},
+		{"IPQoS", "af21 cs1"},
+		{"EscapeChar", "^A"},
+		{"StreamLocalBindMask", "0177"},
+		{"TunnelDevice", "any:0"},
+		{"ObscureKeystrokeTiming", "interval:80"},
+		{"Compression", "true"},
+		{"BatchMode", "FALSE"},
+		{"StrictHostKeyChecking", "false"},
+		{"UpdateHostKeys", "true"},
+		{"ControlMaster", "true"},
+		{"ControlPersist", "false"},
+		{"AddKeysToAgent", "true"},
+		{"HostName", "anything goes"},
+	}
+	for _, tt := range tests {
+		if problems := Check(tt.key, tt.value); problems != nil {
+			t.Errorf("Check(%s, %q) = %v, want nil", tt.key, tt.value, problems)
+		}
+	}
+}
+
+func TestCheck_Invalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+		offset     int
+		message    string
+	}{
+		{"Port", "abc", 0, "expected an integer"},
+		{"Port", "70000", 0, "70000 is out of range 1-65535"},
+		{"Port", "", 0, "missing value"},
+		{"Compression", "maybe", 0, "expected yes or no"},
+		{"Compression", "yes no", 4, `unexpected argument "no"`},
+		{"ServerAliveInterval", "-5", 0, "expected a time interval: unexpected '-' at offset 0"},
+		{"ControlPersist", "10x", 0, "expected yes, no or a time interval: unknown unit 'x' at offset 2, use s, m, h, d or w"},
+		{"StrictHostKeyChecking", "maybe", 0, "expected yes, no, ask, accept-new or off"},
+		{"AddressFamily", "ipv4", 0, "expected any, inet or inet6"},
+		{"AddressFamily", "true", 0, "expected any, inet or inet6"},
+		{"LocalForward", "8080:localhost:80", 17, "missing target, use [bind_address:]port host:hostport"},
+		{"LocalForward", "0 localhost:80", 0, "port 0 is out of range 1-65535"},
+		{"LocalForward", "8080 localhost:80x", 15, "expected a port number"},
+		{"LocalForward", "::1:8080 localhost:80", 0, "expected [bind_address:]port, enclose IPv6 addresses in []"},
+		{"RemoteForward", "8080 localhost", 5, "expected host:hostport, enclose IPv6 addresses in []"},
+		{"DynamicForward", "/tmp/socks", 0, "expected [bind_address:]port"},
+		{"Ciphers", "+aes256-ctr,arcfour", 12, `unknown algorithm "arcfour"`},
+		{"KexAlgorithms", "curve*-sha1", 0, `"curve*-sha1" matches no known algorithm`},
+		{"MACs", "hmac-sha2-256,", 14, "empty algorithm name"},
+		{"RekeyLimit", "lots", 0, "expected a size such as 1G or default"},
+		{"EscapeChar", "ab", 0, "expected a single character, ^ and a character, or none"},
+	}
+	for _, tt := range tests {
+		problems := Check(tt.key, tt.value)
+		if len(problems) != 1 {
+			t.Errorf("Check(%s, %q) = %v, want one problem", tt.key, tt.value, problems)
+			continue
+		}
+		got := problems[0]
+		if got.Key != tt.key || got.Value != tt.value || got.Offset != tt.offset || got.Message != tt.message {
+			t.Errorf("Check(%s, %q) = %+v, want offset %d, %q", tt.key, tt.value, got, tt.offset, tt.message)
+		}
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"0", 0},
+		{"90", 90},
+		{"1h30m", 5400},
+		{"1H30M", 5400},
+		{"2w", 1209600},
+		{"1d1s", 86401},
+	}
+	for _, tt := range tests {
+		if got, err := ParseInterval(tt.text); got != tt.want || err != nil {
+			t.Errorf("ParseInterval(%q) = %d, %v, want %d", tt.text, got, err, tt.want)
+		}
+	}
+	for _, text := range []string{"", "h", "10x", "-5", "1 h"} {
+		if _, err := ParseInterval(text); err == nil {
+			t.Errorf("ParseInterval(%q) expected an error", text)
+		}
+	}
+}
+
+func TestProblem_Error(t *testing.T) {
+	problem := Check("Port", "abc")[0]
+	if got, want := problem.Error(), `Port "abc": expected an integer`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}